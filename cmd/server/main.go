@@ -1,38 +1,12 @@
+// Команда server оставлена для совместимости: это "syncplay serve".
 package main
 
 import (
-	"context"
-	"log"
+	"os"
 
-	"github.com/jackc/pgx/v5/pgxpool"
-
-	"github.com/vpbuyanov/syncplay/internal/config"
-	"github.com/vpbuyanov/syncplay/internal/model"
-	"github.com/vpbuyanov/syncplay/internal/server"
-	"github.com/vpbuyanov/syncplay/internal/store/postgresql"
+	"github.com/vpbuyanov/syncplay/internal/cli"
 )
 
 func main() {
-	cfg := config.MustConfig(nil)
-	ctx := context.Background()
-
-	db, err := pgxpool.New(ctx, cfg.Postgres.String())
-	if err != nil {
-		log.Fatal("error connect db")
-	}
-	defer db.Close()
-
-	rep := postgresql.NewRepos(db)
-
-	modelR := model.NewModelRoom(rep)
-
-	s, err := server.NewServer(cfg.Server, modelR)
-	if err != nil {
-		log.Panic(err)
-	}
-
-	err = s.Listen()
-	if err != nil {
-		log.Panic(err)
-	}
+	os.Exit(cli.Main(os.Args[1:], "serve"))
 }
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"os"
+
+	"github.com/vpbuyanov/syncplay/internal/cli"
+)
+
+func main() {
+	os.Exit(cli.Main(os.Args[1:]))
+}
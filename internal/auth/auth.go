@@ -0,0 +1,109 @@
+// Package auth проверяет JWT внешнего провайдера учётных записей:
+// HS256 с общим секретом или ключи RS/PS/ES из локального JWKS-файла.
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+
+	"github.com/vpbuyanov/syncplay/internal/config"
+)
+
+// ErrInvalidToken — токен не прошёл проверку; причина в обёртке.
+var ErrInvalidToken = errors.New("invalid token")
+
+var asymmetricMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// Claims — утверждения проверенного токена. Subject — идентификатор
+// пользователя у провайдера, Name — отображаемое имя, если оно есть.
+// Local — утверждения сессии встроенной учётной записи, а не JWT.
+type Claims struct {
+	jwt.RegisteredClaims
+	Name  string `json:"name,omitempty"`
+	Local bool   `json:"-"`
+}
+
+// Owner — идентификатор создателя комнаты для created_by. Учётные записи
+// и JWT разнесены по пространствам имён, чтобы sub от провайдера
+// не совпал с id встроенной учётной записи.
+func (c *Claims) Owner() string {
+	if c.Local {
+		return "local:" + c.Subject
+	}
+
+	return "jwt:" + c.Issuer + "|" + c.Subject
+}
+
+// Verifier проверяет подпись, срок действия, iss и aud токена.
+type Verifier struct {
+	parser  *jwt.Parser
+	keyFunc jwt.Keyfunc
+	// keys — ключи из JWKS; nil для HS256
+	keys    *KeySet
+	refresh time.Duration
+}
+
+// NewVerifier создаёт проверку по конфигу; cfg.Enabled() должен быть истинным.
+func NewVerifier(cfg config.Auth) (*Verifier, error) {
+	v := &Verifier{refresh: cfg.JWKSRefresh}
+
+	opts := []jwt.ParserOption{
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(cfg.Leeway),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+
+	switch {
+	case cfg.Secret != "":
+		secret := []byte(cfg.Secret)
+		v.keyFunc = func(*jwt.Token) (any, error) { return secret, nil }
+		opts = append(opts, jwt.WithValidMethods([]string{"HS256"}))
+	case cfg.JWKSFile != "":
+		keys, err := LoadKeySet(cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		v.keys = keys
+		v.keyFunc = keys.key
+		opts = append(opts, jwt.WithValidMethods(asymmetricMethods))
+	default:
+		return nil, errors.New("auth: neither secret nor jwks_file is set")
+	}
+
+	v.parser = jwt.NewParser(opts...)
+
+	return v, nil
+}
+
+// Verify проверяет токен и возвращает его утверждения. Токен без sub
+// отклоняется: по нему участник опознаётся в комнате.
+func (v *Verifier) Verify(token string) (*Claims, error) {
+	var claims Claims
+	if _, err := v.parser.ParseWithClaims(token, &claims, v.keyFunc); err != nil {
+		return nil, errors.Wrap(ErrInvalidToken, err.Error())
+	}
+
+	if claims.Subject == "" {
+		return nil, errors.Wrap(ErrInvalidToken, "token has no subject")
+	}
+
+	return &claims, nil
+}
+
+// Watch перечитывает JWKS-файл при изменении до отмены ctx;
+// для HS256 сразу возвращается.
+func (v *Verifier) Watch(ctx context.Context) {
+	if v.keys == nil || v.refresh <= 0 {
+		return
+	}
+
+	v.keys.Watch(ctx, v.refresh)
+}
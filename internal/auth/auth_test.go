@@ -0,0 +1,228 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vpbuyanov/syncplay/internal/config"
+)
+
+const secret = "0123456789abcdef0123456789abcdef"
+
+func sign(t *testing.T, method jwt.SigningMethod, key any, kid string, claims jwt.Claims) string {
+	t.Helper()
+
+	tok := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		tok.Header["kid"] = kid
+	}
+
+	s, err := tok.SignedString(key)
+	require.NoError(t, err)
+
+	return s
+}
+
+func claimsFor(sub string) Claims {
+	return Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   sub,
+			Issuer:    "https://id.example",
+			Audience:  jwt.ClaimStrings{"syncplay"},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+		Name: "Alice",
+	}
+}
+
+func b64(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+
+// writeJWKS пишет открытые ключи в JWKS-файл; mtime сдвигается,
+// чтобы замена была заметна даже в пределах секунды.
+func writeJWKS(t *testing.T, path string, mtime time.Time, keys map[string]crypto.PublicKey) {
+	t.Helper()
+
+	var set struct {
+		Keys []map[string]string `json:"keys"`
+	}
+	for kid, key := range keys {
+		switch k := key.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, map[string]string{
+				"kty": "RSA", "kid": kid, "use": "sig",
+				"n": b64(k.N.Bytes()), "e": b64(big.NewInt(int64(k.E)).Bytes()),
+			})
+		case *ecdsa.PublicKey:
+			point, err := k.Bytes()
+			require.NoError(t, err)
+			size := (len(point) - 1) / 2
+			set.Keys = append(set.Keys, map[string]string{
+				"kty": "EC", "kid": kid, "crv": k.Curve.Params().Name, "alg": "ES256",
+				"x": b64(point[1 : 1+size]), "y": b64(point[1+size:]),
+			})
+		}
+	}
+
+	b, err := json.Marshal(set)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, b, 0o600))
+	require.NoError(t, os.Chtimes(path, mtime, mtime))
+}
+
+func TestVerifier_HS256(t *testing.T) {
+	v, err := NewVerifier(config.Auth{Secret: secret, Issuer: "https://id.example", Audience: "syncplay"})
+	require.NoError(t, err)
+
+	claims, err := v.Verify(sign(t, jwt.SigningMethodHS256, []byte(secret), "", claimsFor("user-1")))
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.Subject)
+	assert.Equal(t, "Alice", claims.Name)
+
+	// Чужой секрет, чужой издатель, истёкший токен и токен без sub отклоняются
+	bad := map[string]string{
+		"чужой секрет": sign(t, jwt.SigningMethodHS256, []byte("another-secret-another-secret-!!"), "", claimsFor("user-1")),
+		"без sub":      sign(t, jwt.SigningMethodHS256, []byte(secret), "", claimsFor("")),
+		"мусор":        "not.a.token",
+	}
+	wrongIss := claimsFor("user-1")
+	wrongIss.Issuer = "https://evil.example"
+	bad["чужой издатель"] = sign(t, jwt.SigningMethodHS256, []byte(secret), "", wrongIss)
+	expired := claimsFor("user-1")
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+	bad["истёкший"] = sign(t, jwt.SigningMethodHS256, []byte(secret), "", expired)
+	noExp := claimsFor("user-1")
+	noExp.ExpiresAt = nil
+	bad["без exp"] = sign(t, jwt.SigningMethodHS256, []byte(secret), "", noExp)
+
+	for name, tok := range bad {
+		t.Run(name, func(t *testing.T) {
+			_, err := v.Verify(tok)
+			require.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+}
+
+func TestVerifier_JWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	start := time.Now().Add(-time.Hour)
+	writeJWKS(t, path, start, map[string]crypto.PublicKey{"rsa": &rsaKey.PublicKey, "ec": &ecKey.PublicKey})
+
+	v, err := NewVerifier(config.Auth{JWKSFile: path})
+	require.NoError(t, err)
+
+	claims, err := v.Verify(sign(t, jwt.SigningMethodRS256, rsaKey, "rsa", claimsFor("rsa-user")))
+	require.NoError(t, err)
+	assert.Equal(t, "rsa-user", claims.Subject)
+
+	claims, err = v.Verify(sign(t, jwt.SigningMethodES256, ecKey, "ec", claimsFor("ec-user")))
+	require.NoError(t, err)
+	assert.Equal(t, "ec-user", claims.Subject)
+
+	// Неизвестный kid и HS256 с открытым ключом вместо секрета отклоняются
+	_, err = v.Verify(sign(t, jwt.SigningMethodRS256, rsaKey, "other", claimsFor("x")))
+	require.ErrorIs(t, err, ErrInvalidToken)
+	_, err = v.Verify(sign(t, jwt.SigningMethodHS256, []byte(secret), "rsa", claimsFor("x")))
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	// После ротации старый ключ перестаёт действовать, новый принимается
+	newKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	writeJWKS(t, path, start.Add(time.Minute), map[string]crypto.PublicKey{"ec-2": &newKey.PublicKey})
+
+	changed, err := v.keys.Reload()
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	_, err = v.Verify(sign(t, jwt.SigningMethodES256, ecKey, "ec", claimsFor("x")))
+	require.ErrorIs(t, err, ErrInvalidToken)
+	// Единственный ключ подходит и токену без kid
+	_, err = v.Verify(sign(t, jwt.SigningMethodES256, newKey, "", claimsFor("x")))
+	require.NoError(t, err)
+
+	// Битый файл не сбрасывает действующие ключи
+	require.NoError(t, os.WriteFile(path, []byte(`{"keys": []}`), 0o600))
+	_, err = v.keys.Reload()
+	require.ErrorContains(t, err, "no signing keys")
+	_, err = v.Verify(sign(t, jwt.SigningMethodES256, newKey, "ec-2", claimsFor("x")))
+	require.NoError(t, err)
+}
+
+func TestKeySet_Watch(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	start := time.Now().Add(-time.Hour)
+	writeJWKS(t, path, start, map[string]crypto.PublicKey{"a": &key.PublicKey})
+
+	keys, err := LoadKeySet(path)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go keys.Watch(ctx, 10*time.Millisecond)
+
+	writeJWKS(t, path, start.Add(time.Minute), map[string]crypto.PublicKey{"b": &key.PublicKey})
+
+	require.Eventually(t, func() bool {
+		_, ok := (*keys.keys.Load())["b"]
+		return ok
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestLoadKeySet_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwks.json")
+
+	cases := map[string]string{
+		"не json":            "{",
+		"нет ключей":         `{"keys": [{"kty": "oct", "k": "c2VjcmV0"}]}`,
+		"неизвестная кривая": `{"keys": [{"kty": "EC", "crv": "P-192", "x": "AA", "y": "AA"}]}`,
+		"повтор kid": `{"keys": [{"kty": "RSA", "kid": "a", "n": "AQAB", "e": "AQAB"},
+			{"kty": "RSA", "kid": "a", "n": "AQAB", "e": "AQAB"}]}`,
+	}
+
+	for name, content := range cases {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+			_, err := LoadKeySet(path)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestClaims_Owner(t *testing.T) {
+	local := &Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "42"}, Local: true}
+	idp := &Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "42"}}
+	other := &Claims{RegisteredClaims: jwt.RegisteredClaims{Issuer: "https://idp.example", Subject: "42"}}
+
+	assert.Equal(t, "local:42", local.Owner())
+	assert.Equal(t, "jwt:|42", idp.Owner())
+	assert.Equal(t, "jwt:https://idp.example|42", other.Owner())
+	assert.NotEqual(t, local.Owner(), idp.Owner())
+
+	// Признак Local нельзя выставить из самого токена
+	var decoded Claims
+	require.NoError(t, json.Unmarshal([]byte(`{"sub":"42","Local":true,"local":true}`), &decoded))
+	assert.False(t, decoded.Local)
+}
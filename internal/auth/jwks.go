@@ -0,0 +1,235 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"log/slog"
+	"math/big"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+)
+
+// KeySet — открытые ключи из JWKS-файла (RFC 7517). Файл перечитывается,
+// когда меняется время изменения или размер; если новый файл не
+// разбирается, остаются прежние ключи.
+type KeySet struct {
+	path string
+
+	keys atomic.Pointer[map[string]jwk]
+
+	mu    sync.Mutex
+	stamp fileStamp
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+type jwk struct {
+	key crypto.PublicKey
+	// alg — алгоритм, если он закреплён за ключом в JWKS
+	alg string
+}
+
+type rawJWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+var curves = map[string]elliptic.Curve{
+	"P-256": elliptic.P256(),
+	"P-384": elliptic.P384(),
+	"P-521": elliptic.P521(),
+}
+
+// LoadKeySet читает JWKS-файл; в нём должен быть хотя бы один ключ подписи.
+func LoadKeySet(path string) (*KeySet, error) {
+	k := &KeySet{path: path}
+	if _, err := k.Reload(); err != nil {
+		return nil, err
+	}
+
+	return k, nil
+}
+
+// Reload перечитывает файл, если он изменился, и сообщает, были ли ключи заменены.
+func (k *KeySet) Reload() (bool, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	fi, err := os.Stat(k.path)
+	if err != nil {
+		return false, errors.Wrap(err, "stat jwks")
+	}
+	stamp := fileStamp{modTime: fi.ModTime(), size: fi.Size()}
+
+	if k.keys.Load() != nil && stamp == k.stamp {
+		return false, nil
+	}
+
+	b, err := os.ReadFile(k.path)
+	if err != nil {
+		return false, errors.Wrap(err, "read jwks")
+	}
+
+	keys, err := parseJWKS(b)
+	if err != nil {
+		return false, errors.Wrapf(err, "parse jwks %q", k.path)
+	}
+
+	k.keys.Store(&keys)
+	k.stamp = stamp
+
+	return true, nil
+}
+
+// Watch проверяет файл каждые interval до отмены ctx.
+func (k *KeySet) Watch(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			changed, err := k.Reload()
+			switch {
+			case err != nil:
+				slog.Error("jwks reload failed, keeping the previous keys", "err", err)
+			case changed:
+				slog.Info("jwks reloaded", "path", k.path, "keys", len(*k.keys.Load()))
+			}
+		}
+	}
+}
+
+// key выбирает ключ по kid из заголовка токена. Без kid подходит
+// только единственный ключ в наборе.
+func (k *KeySet) key(t *jwt.Token) (any, error) {
+	keys := *k.keys.Load()
+
+	kid, _ := t.Header["kid"].(string)
+	if kid == "" && len(keys) == 1 {
+		for id := range keys {
+			kid = id
+		}
+	}
+
+	key, ok := keys[kid]
+	if !ok {
+		return nil, errors.Errorf("unknown key id %q", kid)
+	}
+	if key.alg != "" && key.alg != t.Method.Alg() {
+		return nil, errors.Errorf("key %q is for %s, token is signed with %s", kid, key.alg, t.Method.Alg())
+	}
+
+	return key.key, nil
+}
+
+func parseJWKS(b []byte) (map[string]jwk, error) {
+	var set struct {
+		Keys []rawJWK `json:"keys"`
+	}
+	if err := json.Unmarshal(b, &set); err != nil {
+		return nil, errors.Wrap(err, "decode")
+	}
+
+	keys := make(map[string]jwk, len(set.Keys))
+	for i, raw := range set.Keys {
+		// Ключи шифрования и симметричные ключи для проверки подписи не нужны
+		if (raw.Use != "" && raw.Use != "sig") || raw.Kty == "oct" {
+			continue
+		}
+
+		key, err := raw.publicKey()
+		if err != nil {
+			return nil, errors.Wrapf(err, "key %d (kid %q)", i, raw.Kid)
+		}
+		if _, dup := keys[raw.Kid]; dup {
+			return nil, errors.Errorf("duplicate kid %q", raw.Kid)
+		}
+
+		keys[raw.Kid] = jwk{key: key, alg: raw.Alg}
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("no signing keys")
+	}
+
+	return keys, nil
+}
+
+func (r rawJWK) publicKey() (crypto.PublicKey, error) {
+	switch r.Kty {
+	case "RSA":
+		n, err := decodeBase64URL(r.N)
+		if err != nil {
+			return nil, errors.Wrap(err, "n")
+		}
+		e, err := decodeBase64URL(r.E)
+		if err != nil {
+			return nil, errors.Wrap(err, "e")
+		}
+		if len(n) == 0 || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("invalid rsa key")
+		}
+
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "EC":
+		curve, ok := curves[r.Crv]
+		if !ok {
+			return nil, errors.Errorf("unsupported curve %q", r.Crv)
+		}
+		x, err := decodeBase64URL(r.X)
+		if err != nil {
+			return nil, errors.Wrap(err, "x")
+		}
+		y, err := decodeBase64URL(r.Y)
+		if err != nil {
+			return nil, errors.Wrap(err, "y")
+		}
+
+		size := (curve.Params().BitSize + 7) / 8
+		if len(x) != size || len(y) != size {
+			return nil, errors.New("invalid ec point size")
+		}
+
+		point := append(append([]byte{4}, x...), y...)
+		key, err := ecdsa.ParseUncompressedPublicKey(curve, point)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid ec key")
+		}
+
+		return key, nil
+	}
+
+	return nil, errors.Errorf("unsupported key type %q", r.Kty)
+}
+
+func decodeBase64URL(s string) ([]byte, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+
+	return b, errors.WithStack(err)
+}
@@ -0,0 +1,100 @@
+// Package certs держит TLS-сертификат сервера и перечитывает его с диска,
+// когда файлы меняются (например, после продления certbot или cert-manager).
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Reloader отдаёт текущий сертификат через GetCertificate. Файлы проверяются
+// раз в интервал Watch: меняется время изменения или размер — пара
+// перечитывается. Если новая пара не загружается, остаётся старая.
+type Reloader struct {
+	certFile string
+	keyFile  string
+
+	cert atomic.Pointer[tls.Certificate]
+
+	mu    sync.Mutex
+	stamp [2]fileStamp
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewReloader загружает пару сертификат/ключ; ошибка означает, что
+// сервер с такой парой не запустится.
+func NewReloader(certFile, keyFile string) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// GetCertificate подходит для tls.Config.GetCertificate.
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.cert.Load(), nil
+}
+
+// Reload перечитывает пару, если файлы изменились, и сообщает, была ли
+// она заменена.
+func (r *Reloader) Reload() (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var stamp [2]fileStamp
+	for i, path := range []string{r.certFile, r.keyFile} {
+		fi, err := os.Stat(path)
+		if err != nil {
+			return false, errors.Wrap(err, "stat tls file")
+		}
+		stamp[i] = fileStamp{modTime: fi.ModTime(), size: fi.Size()}
+	}
+
+	if r.cert.Load() != nil && stamp == r.stamp {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return false, errors.Wrap(err, "load tls key pair")
+	}
+
+	r.cert.Store(&cert)
+	r.stamp = stamp
+
+	return true, nil
+}
+
+// Watch проверяет файлы каждые interval до отмены ctx.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			changed, err := r.Reload()
+			switch {
+			case err != nil:
+				slog.Error("tls certificate reload failed, keeping the previous one", "err", err)
+			case changed:
+				slog.Info("tls certificate reloaded", "cert", r.certFile)
+			}
+		}
+	}
+}
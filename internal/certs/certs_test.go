@@ -0,0 +1,102 @@
+package certs
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePair пишет самоподписанный сертификат с CN name и его ключ;
+// mtime сдвигается, чтобы замена была заметна даже в пределах секунды.
+func writePair(t *testing.T, certFile, keyFile, name string, mtime time.Time) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	require.NoError(t, os.Chtimes(certFile, mtime, mtime))
+	require.NoError(t, os.Chtimes(keyFile, mtime, mtime))
+}
+
+func commonName(t *testing.T, r *Reloader) string {
+	t.Helper()
+
+	cert, err := r.GetCertificate(nil)
+	require.NoError(t, err)
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+
+	return leaf.Subject.CommonName
+}
+
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	now := time.Now()
+
+	writePair(t, certFile, keyFile, "old.example", now)
+
+	r, err := NewReloader(certFile, keyFile)
+	require.NoError(t, err)
+	assert.Equal(t, "old.example", commonName(t, r))
+
+	// Файлы не менялись — пара не перечитывается
+	changed, err := r.Reload()
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	writePair(t, certFile, keyFile, "new.example", now.Add(time.Minute))
+	changed, err = r.Reload()
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "new.example", commonName(t, r))
+
+	// Битый файл не заменяет рабочий сертификат
+	require.NoError(t, os.WriteFile(keyFile, []byte("garbage"), 0o600))
+	_, err = r.Reload()
+	require.Error(t, err)
+	assert.Equal(t, "new.example", commonName(t, r))
+
+	// Watch подхватывает исправленную пару сам
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Watch(ctx, 10*time.Millisecond)
+
+	writePair(t, certFile, keyFile, "renewed.example", now.Add(2*time.Minute))
+	assert.Eventually(t, func() bool {
+		return commonName(t, r) == "renewed.example"
+	}, 3*time.Second, 10*time.Millisecond)
+}
+
+func TestNewReloader_Missing(t *testing.T) {
+	_, err := NewReloader(filepath.Join(t.TempDir(), "nope.crt"), "nope.key")
+	require.ErrorContains(t, err, "stat tls file")
+}
@@ -0,0 +1,147 @@
+// Package cli — командная строка syncplay: один бинарник с подкомандами,
+// общей загрузкой конфига и глобальными флагами.
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+
+	"github.com/vpbuyanov/syncplay/internal/config"
+	"github.com/vpbuyanov/syncplay/internal/version"
+)
+
+const usage = `Usage: syncplay [-config path] <command> [args]
+
+Commands:
+  serve                    run the HTTP and WebSocket server
+  migrate <command>        manage the database schema (see "syncplay migrate help")
+  rooms create|list|delete manage rooms directly in the storage
+  tenants create|list|update
+                           manage tenants, their API keys and quotas
+  config validate          check the config file
+  version                  print the version
+
+Global flags:
+`
+
+const defaultConfigPath = "./config.yml"
+
+// errUsage — неверные аргументы; справка уже выведена.
+var errUsage = errors.New("invalid usage")
+
+type app struct {
+	stdout     io.Writer
+	stderr     io.Writer
+	configPath string
+}
+
+// Main разбирает глобальные флаги и выполняет команду. prefix подставляется
+// перед аргументами после флагов: так старые бинарники server и migrator
+// остаются обёртками над "serve" и "migrate". Возвращает код выхода.
+func Main(args []string, prefix ...string) int {
+	err := run(context.Background(), args, prefix, os.Stdout, os.Stderr)
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp):
+		return 2
+	}
+
+	fmt.Fprintf(os.Stderr, "syncplay: %v\n", err)
+
+	if errors.Is(err, errUsage) {
+		return 2
+	}
+
+	return 1
+}
+
+func run(ctx context.Context, args, prefix []string, stdout, stderr io.Writer) error {
+	a := &app{stdout: stdout, stderr: stderr}
+
+	fs := flag.NewFlagSet("syncplay", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&a.configPath, "config", "", "path to config (default $CONFIG_PATH, then ./config.yml if it exists, then environment only)")
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	args = append(append([]string{}, prefix...), fs.Args()...)
+	if len(args) == 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	switch args[0] {
+	case "serve":
+		return a.serve(ctx, args[1:])
+	case "migrate":
+		return a.migrate(args[1:])
+	case "rooms":
+		return a.rooms(ctx, args[1:])
+	case "tenants":
+		return a.tenants(ctx, args[1:])
+	case "config":
+		return a.config(args[1:])
+	case "version":
+		fmt.Fprintln(stdout, version.Version)
+		return nil
+	case "help":
+		fs.Usage()
+		return nil
+	}
+
+	fs.Usage()
+
+	return errors.Wrapf(errUsage, "unknown command %q", args[0])
+}
+
+// loadConfig читает конфиг из -config, $CONFIG_PATH или ./config.yml.
+// Если файл не указан и ./config.yml нет, конфиг собирается из окружения.
+func (a *app) loadConfig() (*config.Config, error) {
+	path := a.configPath
+	if path == "" {
+		path = os.Getenv("CONFIG_PATH")
+	}
+	if path == "" {
+		if _, err := os.Stat(defaultConfigPath); err == nil {
+			path = defaultConfigPath
+		}
+	}
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "load config")
+	}
+
+	if err = cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func (a *app) config(args []string) error {
+	if len(args) != 1 || args[0] != "validate" {
+		fmt.Fprintln(a.stderr, "Usage: syncplay config validate")
+		return errUsage
+	}
+
+	if _, err := a.loadConfig(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(a.stdout, "config is valid")
+
+	return nil
+}
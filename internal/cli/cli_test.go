@@ -0,0 +1,221 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vpbuyanov/syncplay/internal/server"
+	"github.com/vpbuyanov/syncplay/internal/version"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func sqliteConfig(t *testing.T) string {
+	t.Helper()
+
+	return writeConfig(t, "storage:\n  driver: sqlite\n  sqlite:\n    path: "+
+		filepath.Join(t.TempDir(), "syncplay.db")+"\n")
+}
+
+func runCLI(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	err := run(context.Background(), args, nil, &stdout, &stderr)
+
+	return stdout.String(), err
+}
+
+func TestRun_Version(t *testing.T) {
+	out, err := runCLI(t, "version")
+	require.NoError(t, err)
+	assert.Equal(t, version.Version+"\n", out)
+}
+
+func TestRun_Usage(t *testing.T) {
+	_, err := runCLI(t)
+	require.ErrorIs(t, err, errUsage)
+
+	_, err = runCLI(t, "bogus")
+	require.ErrorIs(t, err, errUsage)
+
+	_, err = runCLI(t, "-unknown-flag", "version")
+	require.ErrorIs(t, err, errUsage)
+}
+
+func TestRun_ConfigValidate(t *testing.T) {
+	out, err := runCLI(t, "-config", sqliteConfig(t), "config", "validate")
+	require.NoError(t, err)
+	assert.Equal(t, "config is valid\n", out)
+
+	_, err = runCLI(t, "-config", writeConfig(t, "storage:\n  driver: mysql\n"), "config", "validate")
+	require.ErrorContains(t, err, `unknown driver "mysql"`)
+
+	_, err = runCLI(t, "-config", filepath.Join(t.TempDir(), "nope.yml"), "config", "validate")
+	require.ErrorContains(t, err, "does not exist")
+}
+
+func TestRun_MigrateAndRooms(t *testing.T) {
+	cfg := sqliteConfig(t)
+
+	out, err := runCLI(t, "-config", cfg, "migrate", "status")
+	require.NoError(t, err)
+	assert.Contains(t, out, "up would apply")
+
+	out, err = runCLI(t, "-config", cfg, "migrate")
+	require.NoError(t, err)
+	assert.Contains(t, out, "version 3")
+
+	out, err = runCLI(t, "-config", cfg, "rooms", "create", "-title", "Movie night", "-tag", "movies", "-tag", "fun")
+	require.NoError(t, err)
+	id := regexp.MustCompile(`id: (\S+)`).FindStringSubmatch(out)
+	require.Len(t, id, 2, out)
+	assert.Contains(t, out, "owner token: ")
+
+	out, err = runCLI(t, "-config", cfg, "rooms", "list")
+	require.NoError(t, err)
+	assert.Contains(t, out, id[1])
+	assert.Contains(t, out, "Movie night")
+	assert.Contains(t, out, "movies,fun")
+
+	out, err = runCLI(t, "-config", cfg, "rooms", "delete", id[1])
+	require.NoError(t, err)
+	assert.Contains(t, out, "deleted")
+
+	out, err = runCLI(t, "-config", cfg, "rooms", "list")
+	require.NoError(t, err)
+	assert.NotContains(t, out, id[1])
+
+	_, err = runCLI(t, "-config", cfg, "rooms", "delete", "not-a-uuid")
+	require.ErrorIs(t, err, errUsage)
+}
+
+func TestRun_Tenants(t *testing.T) {
+	cfg := sqliteConfig(t)
+
+	_, err := runCLI(t, "-config", cfg, "migrate")
+	require.NoError(t, err)
+
+	out, err := runCLI(t, "-config", cfg, "tenants", "create", "-max-rooms", "5", "acme")
+	require.NoError(t, err)
+	assert.Contains(t, out, "name: acme")
+	assert.Regexp(t, `api key: spk_\S+`, out)
+
+	out, err = runCLI(t, "-config", cfg, "tenants", "update", "-max-peers", "10", "acme")
+	require.NoError(t, err)
+	assert.Contains(t, out, "max rooms 5, max peers 10, messages/min 0")
+
+	out, err = runCLI(t, "-config", cfg, "tenants", "list")
+	require.NoError(t, err)
+	assert.Regexp(t, `acme\s+\S+\s+5\s+10\s+0`, out)
+
+	_, err = runCLI(t, "-config", cfg, "tenants", "update", "acme")
+	require.ErrorIs(t, err, errUsage)
+
+	_, err = runCLI(t, "-config", cfg, "tenants", "update", "-max-rooms", "1", "nobody")
+	require.Error(t, err)
+}
+
+func TestRun_Prefix(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	err := run(context.Background(), []string{"-config", sqliteConfig(t), "version"}, []string{"migrate"}, &stdout, &stderr)
+	require.NoError(t, err)
+	assert.Equal(t, "version 0\n", stdout.String())
+}
+
+type fakeTunable struct {
+	got []server.Tunables
+}
+
+func (f *fakeTunable) SetTunables(t server.Tunables) { f.got = append(f.got, t) }
+
+func TestReloader_Reload(t *testing.T) {
+	path := writeConfig(t, "storage:\n  driver: memory\n")
+	a := &app{configPath: path}
+
+	cur, err := a.loadConfig()
+	require.NoError(t, err)
+
+	var (
+		level = new(slog.LevelVar)
+		srv   = &fakeTunable{}
+		r     = &reloader{load: a.loadConfig, level: level, server: srv, cur: cur}
+	)
+
+	// Настраиваемые поля применяются
+	require.NoError(t, os.WriteFile(path, []byte(
+		"storage:\n  driver: memory\nlog:\n  level: debug\nserver:\n  room_max_peers: 4\n"), 0o600))
+	require.NoError(t, r.reload())
+	assert.Equal(t, slog.LevelDebug, level.Level())
+	require.Len(t, srv.got, 1)
+	assert.Equal(t, 4, srv.got[0].RoomMaxPeers)
+
+	// Смена порта отклоняется, старые настройки остаются
+	require.NoError(t, os.WriteFile(path, []byte(
+		"storage:\n  driver: memory\nlog:\n  level: warn\nserver:\n  port: 9090\n"), 0o600))
+	require.ErrorContains(t, r.reload(), "server.port")
+	assert.Equal(t, slog.LevelDebug, level.Level())
+	assert.Len(t, srv.got, 1)
+
+	// Некорректный конфиг тоже не применяется
+	require.NoError(t, os.WriteFile(path, []byte("storage:\n  driver: memory\nlog:\n  level: loud\n"), 0o600))
+	require.ErrorContains(t, r.reload(), "log.level")
+	assert.Len(t, srv.got, 1)
+}
+
+func TestRun_ServeShutdown(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := l.Addr().(*net.TCPAddr).Port
+	require.NoError(t, l.Close())
+
+	cfg := writeConfig(t, fmt.Sprintf("storage:\n  driver: memory\nserver:\n  host: 127.0.0.1\n  port: %d\n", port))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		var stdout, stderr bytes.Buffer
+		done <- run(ctx, []string{"-config", cfg, "serve"}, nil, &stdout, &stderr)
+	}()
+
+	url := fmt.Sprintf("http://127.0.0.1:%d/api/v1/info", port)
+	require.Eventually(t, func() bool {
+		resp, err := http.Get(url)
+		if err != nil {
+			return false
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, 5*time.Second, 20*time.Millisecond)
+
+	// Отмена контекста равносильна SIGTERM: сервер останавливается без ошибки
+	cancel()
+
+	select {
+	case err = <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve did not stop")
+	}
+}
@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/vpbuyanov/syncplay/internal/migrator"
+)
+
+const migrateUsage = `Usage: syncplay migrate <command> [args]
+
+Commands:
+  up          apply all pending migrations (default)
+  down N      roll back the last N migrations
+  goto V      migrate up or down to version V
+  version     print the current schema version
+  force V     set version V without running migrations (clears the dirty flag)
+  status      list migrations and show what "up" would apply
+`
+
+func (a *app) migrate(args []string) (err error) {
+	if len(args) == 0 {
+		args = []string{"up"}
+	}
+
+	if args[0] == "help" {
+		fmt.Fprint(a.stdout, migrateUsage)
+		return nil
+	}
+
+	cfg, err := a.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	m, err := migrator.New(cfg.Storage, cfg.Postgres)
+	if errors.Is(err, migrator.ErrNoMigrations) {
+		fmt.Fprintf(a.stdout, "storage driver %q has no migrations, nothing to do\n", cfg.Storage.Driver)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := m.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	if err = a.runMigrate(m, args[0], args[1:]); err != nil {
+		return errors.Wrap(err, "migrate "+args[0])
+	}
+
+	return nil
+}
+
+func (a *app) runMigrate(m *migrator.Migrator, cmd string, args []string) error {
+	switch cmd {
+	case "up":
+		if err := m.Up(); err != nil {
+			return err
+		}
+		return a.printVersion(m)
+	case "down":
+		n, err := intArg(args)
+		if err != nil {
+			return err
+		}
+		if err = m.Down(n); err != nil {
+			return err
+		}
+		return a.printVersion(m)
+	case "goto":
+		v, err := intArg(args)
+		if err != nil {
+			return err
+		}
+		if v < 0 {
+			return errors.Errorf("version must not be negative, got %d", v)
+		}
+		if err = m.Goto(uint(v)); err != nil {
+			return err
+		}
+		return a.printVersion(m)
+	case "force":
+		v, err := intArg(args)
+		if err != nil {
+			return err
+		}
+		if err = m.Force(v); err != nil {
+			return err
+		}
+		return a.printVersion(m)
+	case "version":
+		return a.printVersion(m)
+	case "status":
+		return a.printStatus(m)
+	}
+
+	fmt.Fprint(a.stderr, migrateUsage)
+
+	return errors.Wrapf(errUsage, "unknown command %q", cmd)
+}
+
+func intArg(args []string) (int, error) {
+	if len(args) != 1 {
+		return 0, errors.Wrap(errUsage, "expected exactly one numeric argument")
+	}
+
+	n, err := strconv.Atoi(args[0])
+	if err != nil {
+		return 0, errors.Wrapf(errUsage, "invalid number %q", args[0])
+	}
+
+	return n, nil
+}
+
+func (a *app) printVersion(m *migrator.Migrator) error {
+	v, dirty, err := m.Version()
+	if err != nil {
+		return err
+	}
+
+	if dirty {
+		fmt.Fprintf(a.stdout, "version %d (dirty)\n", v)
+	} else {
+		fmt.Fprintf(a.stdout, "version %d\n", v)
+	}
+
+	return nil
+}
+
+func (a *app) printStatus(m *migrator.Migrator) error {
+	st, err := m.Status()
+	if err != nil {
+		return err
+	}
+
+	for _, mig := range st.Migrations {
+		mark := " "
+		if mig.Applied {
+			mark = "x"
+		}
+		fmt.Fprintf(a.stdout, "[%s] %06d %s\n", mark, mig.Version, mig.Name)
+	}
+
+	fmt.Fprintln(a.stdout)
+	if err = a.printVersion(m); err != nil {
+		return err
+	}
+
+	if len(st.Pending) == 0 {
+		fmt.Fprintln(a.stdout, "schema is up to date")
+		return nil
+	}
+
+	fmt.Fprintf(a.stdout, "up would apply %d migration(s):\n", len(st.Pending))
+	for _, mig := range st.Pending {
+		fmt.Fprintf(a.stdout, "  %06d %s\n", mig.Version, mig.Name)
+	}
+
+	return nil
+}
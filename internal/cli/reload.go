@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	"github.com/pkg/errors"
+
+	"github.com/vpbuyanov/syncplay/internal/config"
+	"github.com/vpbuyanov/syncplay/internal/server"
+)
+
+type tunable interface {
+	SetTunables(t server.Tunables)
+}
+
+// reloader перечитывает конфиг по SIGHUP и применяет к работающему серверу
+// поля, которые можно менять без перезапуска. Если изменились другие поля,
+// новый конфиг отклоняется целиком, и сервер продолжает работать со старым.
+type reloader struct {
+	load   func() (*config.Config, error)
+	level  *slog.LevelVar
+	server tunable
+
+	cur *config.Config
+}
+
+func (r *reloader) run(ctx context.Context, signals <-chan os.Signal) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+			if err := r.reload(); err != nil {
+				slog.Error("config reload rejected", "err", err)
+			}
+		}
+	}
+}
+
+func (r *reloader) reload() error {
+	next, err := r.load()
+	if err != nil {
+		return err
+	}
+
+	changes, err := config.Diff(r.cur, next)
+	if err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		slog.Info("config reloaded, nothing changed")
+		return nil
+	}
+
+	level, err := next.Log.SlogLevel()
+	if err != nil {
+		return errors.Wrap(err, "log level")
+	}
+
+	r.level.Set(level)
+	r.server.SetTunables(server.TunablesFrom(next))
+	r.cur = next
+
+	for _, c := range changes {
+		slog.Info("config reloaded", "field", c.Field, "old", c.Old, "new", c.New)
+	}
+
+	return nil
+}
@@ -0,0 +1,217 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+const roomsUsage = `Usage: syncplay rooms <command> [flags]
+
+Commands:
+  create [-title T] [-description D] [-visibility public|private] [-tag T]... [-ttl D]
+  list   [-limit N] [-visibility public|private] [-tag T] [-sort S] [-cursor C]
+  delete [-purge] ID
+
+Rooms are changed directly in the storage: peers connected to a running
+server are not notified about deleted rooms.
+`
+
+// stringsFlag — флаг, который можно указать несколько раз.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string { return strings.Join(*f, ",") }
+
+func (f *stringsFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+func (a *app) rooms(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(a.stderr, roomsUsage)
+		return errUsage
+	}
+
+	switch args[0] {
+	case "create":
+		return a.roomsCreate(ctx, args[1:])
+	case "list":
+		return a.roomsList(ctx, args[1:])
+	case "delete":
+		return a.roomsDelete(ctx, args[1:])
+	case "help":
+		fmt.Fprint(a.stdout, roomsUsage)
+		return nil
+	}
+
+	fmt.Fprint(a.stderr, roomsUsage)
+
+	return errors.Wrapf(errUsage, "unknown command %q", args[0])
+}
+
+// openRooms открывает хранилище из конфига и оборачивает его моделью.
+func (a *app) openRooms(ctx context.Context) (*model.Room, func(), error) {
+	cfg, err := a.loadConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	store, closeStore, err := openStore(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return model.NewModelRoom(store, model.WithRestoreWindow(cfg.Janitor.RestoreWindow)), closeStore, nil
+}
+
+func (a *app) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+
+	return fs
+}
+
+// parseFlags разбирает флаги подкоманды; ошибку и справку flag уже вывел сам.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	err := fs.Parse(args)
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return err
+	}
+
+	return errUsage
+}
+
+func (a *app) roomsCreate(ctx context.Context, args []string) error {
+	var (
+		meta model.RoomMeta
+		tags stringsFlag
+		ttl  time.Duration
+	)
+
+	fs := a.flagSet("rooms create")
+	fs.StringVar(&meta.Title, "title", "", "room title")
+	fs.StringVar(&meta.Description, "description", "", "room description")
+	fs.StringVar(&meta.Visibility, "visibility", model.VisibilityPublic, "public or private")
+	fs.Var(&tags, "tag", "room tag, can be repeated")
+	fs.DurationVar(&ttl, "ttl", 0, "delete the room after this duration (0 — never)")
+
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return errors.Wrapf(errUsage, "unexpected arguments %q", fs.Args())
+	}
+
+	meta.Tags = tags
+	if ttl > 0 {
+		expiresAt := time.Now().Add(ttl)
+		meta.ExpiresAt = &expiresAt
+	}
+
+	rooms, closeStore, err := a.openRooms(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	created, err := rooms.CreateRoom(ctx, meta)
+	if err != nil {
+		return errors.Wrap(err, "create room")
+	}
+
+	fmt.Fprintf(a.stdout, "id: %s\nowner token: %s\n", created.ID, created.OwnerToken)
+
+	return nil
+}
+
+func (a *app) roomsList(ctx context.Context, args []string) error {
+	var p model.ListRoomsParams
+
+	fs := a.flagSet("rooms list")
+	fs.IntVar(&p.Limit, "limit", 0, "page size")
+	fs.StringVar(&p.Visibility, "visibility", "", "public or private")
+	fs.StringVar(&p.Tag, "tag", "", "only rooms with this tag")
+	fs.StringVar(&p.Sort, "sort", model.SortCreatedDesc, "created_at, -created_at, title or -title")
+	fs.StringVar(&p.Cursor, "cursor", "", "cursor of the next page")
+
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return errors.Wrapf(errUsage, "unexpected arguments %q", fs.Args())
+	}
+
+	rooms, closeStore, err := a.openRooms(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	page, err := rooms.ListRooms(ctx, p)
+	if err != nil {
+		return errors.Wrap(err, "list rooms")
+	}
+
+	w := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTITLE\tVISIBILITY\tTAGS\tCREATED")
+	for _, r := range page.Items {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			r.ID, r.Title, r.Visibility, strings.Join(r.Tags, ","), r.CreatedAt.Format(time.RFC3339))
+	}
+	if err = w.Flush(); err != nil {
+		return errors.Wrap(err, "write rooms")
+	}
+
+	if page.NextCursor != "" {
+		fmt.Fprintf(a.stdout, "\nnext page: -cursor %s\n", page.NextCursor)
+	}
+
+	return nil
+}
+
+func (a *app) roomsDelete(ctx context.Context, args []string) error {
+	var purge bool
+
+	fs := a.flagSet("rooms delete")
+	fs.BoolVar(&purge, "purge", false, "delete permanently together with chat history")
+
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.Wrap(errUsage, "expected exactly one room id")
+	}
+
+	id, err := uuid.Parse(fs.Arg(0))
+	if err != nil {
+		return errors.Wrapf(errUsage, "invalid room id %q", fs.Arg(0))
+	}
+
+	rooms, closeStore, err := a.openRooms(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	if purge {
+		err = rooms.PurgeRoom(ctx, id)
+	} else {
+		err = rooms.DeleteRoom(ctx, id)
+	}
+	if err != nil {
+		return errors.Wrap(err, "delete room")
+	}
+
+	fmt.Fprintf(a.stdout, "room %s deleted\n", id)
+
+	return nil
+}
@@ -0,0 +1,198 @@
+package cli
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pkg/errors"
+
+	"github.com/vpbuyanov/syncplay/internal/config"
+	"github.com/vpbuyanov/syncplay/internal/janitor"
+	"github.com/vpbuyanov/syncplay/internal/logging"
+	"github.com/vpbuyanov/syncplay/internal/metrics"
+	"github.com/vpbuyanov/syncplay/internal/migrator"
+	"github.com/vpbuyanov/syncplay/internal/model"
+	"github.com/vpbuyanov/syncplay/internal/server"
+	"github.com/vpbuyanov/syncplay/internal/store/memory"
+	"github.com/vpbuyanov/syncplay/internal/store/postgresql"
+	"github.com/vpbuyanov/syncplay/internal/store/sqlite"
+	"github.com/vpbuyanov/syncplay/internal/tracing"
+)
+
+// tracingFlushTimeout ограничивает отправку оставшихся спанов при остановке.
+const tracingFlushTimeout = 5 * time.Second
+
+// recentErrorsSize — сколько последних ошибок из лога показывает /admin/dashboard.
+const recentErrorsSize = 50
+
+func (a *app) serve(ctx context.Context, args []string) error {
+	if len(args) != 0 {
+		return errors.Wrapf(errUsage, "serve takes no arguments, got %q", args)
+	}
+
+	cfg, err := a.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	level := new(slog.LevelVar)
+	lvl, err := cfg.Log.SlogLevel()
+	if err != nil {
+		return errors.Wrap(err, "log level")
+	}
+	level.Set(lvl)
+
+	recentErrors := logging.NewRecentErrors(recentErrorsSize)
+
+	logger, err := logging.New(a.stderr, cfg.Log, level, logging.WithRecentErrors(recentErrors))
+	if err != nil {
+		return errors.Wrap(err, "create logger")
+	}
+	slog.SetDefault(logger)
+
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing, a.stdout)
+	if err != nil {
+		return errors.Wrap(err, "setup tracing")
+	}
+	// Отложенные вызовы идут в обратном порядке: спаны отправляются
+	// после остановки сервера, но до закрытия хранилища
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), tracingFlushTimeout)
+		defer cancel()
+
+		if err := shutdownTracing(flushCtx); err != nil {
+			slog.Warn("flush traces", "err", err)
+		}
+	}()
+
+	if err = migrator.Prepare(cfg.Storage, cfg.Postgres, cfg.Storage.AutoMigrate); err != nil {
+		return errors.Wrap(err, "database schema")
+	}
+
+	store, closeStore, err := openStore(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	modelR := model.NewModelRoom(store,
+		model.WithRestoreWindow(cfg.Janitor.RestoreWindow),
+		model.WithSessionTTL(cfg.Auth.SessionTTL))
+
+	s, err := server.NewServer(cfg, modelR)
+	if err != nil {
+		return errors.Wrap(err, "create server")
+	}
+	s.SetRecentErrors(recentErrors)
+
+	// Схему проверяем и после старта: её могли откатить или пометить dirty
+	mig, err := migrator.New(cfg.Storage, cfg.Postgres)
+	switch {
+	case err == nil:
+		defer func() { _ = mig.Close() }()
+		s.AddCheck("migrations", func(context.Context) error { return mig.Check() })
+	case !errors.Is(err, migrator.ErrNoMigrations):
+		return errors.Wrap(err, "open migrations")
+	}
+
+	// Первый SIGINT/SIGTERM запускает плавную остановку, второй завершает процесс сразу
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	janitorDone := make(chan struct{})
+	if cfg.Janitor.Enabled {
+		j, err := janitor.New(cfg.Janitor, modelR, s)
+		if err != nil {
+			return errors.Wrap(err, "create janitor")
+		}
+
+		go func() {
+			defer close(janitorDone)
+			j.Run(ctx)
+		}()
+	} else {
+		close(janitorDone)
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	r := &reloader{load: a.loadConfig, level: level, server: s, cur: cfg}
+	go r.run(ctx, hup)
+
+	listenErr := make(chan error, 1)
+	go func() { listenErr <- s.Listen() }()
+
+	select {
+	case err = <-listenErr:
+		return errors.Wrap(err, "listen")
+	case <-ctx.Done():
+	}
+	stop()
+
+	slog.Info("shutting down", "timeout", cfg.Server.ShutdownTimeout)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	err = s.Shutdown(shutdownCtx)
+	<-janitorDone
+
+	// Хранилище закрывается отложенным closeStore уже после сервера и janitor
+	if err != nil {
+		return errors.Wrap(err, "shutdown")
+	}
+
+	slog.Info("server stopped")
+
+	return nil
+}
+
+// openStore открывает хранилище из конфига. Возвращаемая функция закрывает соединения.
+func openStore(ctx context.Context, cfg *config.Config) (model.Store, func(), error) {
+	switch cfg.Storage.Driver {
+	case "", config.StoragePostgres:
+		pcfg, err := pgxpool.ParseConfig(cfg.Postgres.String())
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "parse postgres config")
+		}
+		pcfg.ConnConfig.Tracer = postgresql.NewTracer()
+
+		db, err := pgxpool.NewWithConfig(ctx, pcfg)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "connect to postgres")
+		}
+
+		pool := postgresql.NewPoolCollector(db)
+		if err = metrics.Registry.Register(pool); err != nil {
+			db.Close()
+			return nil, nil, errors.Wrap(err, "register pool metrics")
+		}
+
+		closeDB := func() {
+			metrics.Registry.Unregister(pool)
+			db.Close()
+		}
+
+		return postgresql.NewRepos(db), closeDB, nil
+	case config.StorageSQLite:
+		db, err := sqlite.Open(cfg.Storage.SQLite.Path)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return sqlite.NewRepos(db), func() { _ = db.Close() }, nil
+	case config.StorageMemory:
+		slog.Warn("using in-memory storage, data will be lost on restart")
+
+		return memory.New(), func() {}, nil
+	}
+
+	return nil, nil, errors.Errorf("unknown storage driver %q", cfg.Storage.Driver)
+}
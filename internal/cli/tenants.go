@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+const tenantsUsage = `Usage: syncplay tenants <command> [flags]
+
+Commands:
+  create [-max-rooms N] [-max-peers N] [-messages-per-min N] NAME
+  list
+  update [-max-rooms N] [-max-peers N] [-messages-per-min N] NAME
+
+The API key is printed once by create: only its hash is stored.
+Quotas of 0 are unlimited; update changes only the given quotas.
+`
+
+func (a *app) tenants(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(a.stderr, tenantsUsage)
+		return errUsage
+	}
+
+	switch args[0] {
+	case "create":
+		return a.tenantsCreate(ctx, args[1:])
+	case "list":
+		return a.tenantsList(ctx, args[1:])
+	case "update":
+		return a.tenantsUpdate(ctx, args[1:])
+	case "help":
+		fmt.Fprint(a.stdout, tenantsUsage)
+		return nil
+	}
+
+	fmt.Fprint(a.stderr, tenantsUsage)
+
+	return errors.Wrapf(errUsage, "unknown command %q", args[0])
+}
+
+func quotaFlags(fs *flag.FlagSet, q *model.TenantQuotas) {
+	fs.IntVar(&q.MaxRooms, "max-rooms", q.MaxRooms, "maximum live rooms (0 — unlimited)")
+	fs.IntVar(&q.MaxPeers, "max-peers", q.MaxPeers, "maximum connected peers in all rooms (0 — unlimited)")
+	fs.IntVar(&q.MessagesPerMin, "messages-per-min", q.MessagesPerMin, "maximum WebSocket messages per minute (0 — unlimited)")
+}
+
+func (a *app) tenantsCreate(ctx context.Context, args []string) error {
+	var q model.TenantQuotas
+
+	fs := a.flagSet("tenants create")
+	quotaFlags(fs, &q)
+
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.Wrap(errUsage, "expected exactly one tenant name")
+	}
+
+	rooms, closeStore, err := a.openRooms(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	tenant, key, err := rooms.CreateTenant(ctx, fs.Arg(0), q)
+	if err != nil {
+		return errors.Wrap(err, "create tenant")
+	}
+
+	fmt.Fprintf(a.stdout, "id: %s\nname: %s\napi key: %s\n", tenant.ID, tenant.Name, key)
+
+	return nil
+}
+
+func (a *app) tenantsList(ctx context.Context, args []string) error {
+	fs := a.flagSet("tenants list")
+
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return errors.Wrapf(errUsage, "unexpected arguments %q", fs.Args())
+	}
+
+	rooms, closeStore, err := a.openRooms(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	tenants, err := rooms.ListTenants(ctx)
+	if err != nil {
+		return errors.Wrap(err, "list tenants")
+	}
+
+	w := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tID\tMAX ROOMS\tMAX PEERS\tMESSAGES/MIN\tCREATED")
+	for _, t := range tenants {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%s\n",
+			t.Name, t.ID, t.Quotas.MaxRooms, t.Quotas.MaxPeers, t.Quotas.MessagesPerMin, t.CreatedAt.Format(time.RFC3339))
+	}
+	if err = w.Flush(); err != nil {
+		return errors.Wrap(err, "write tenants")
+	}
+
+	return nil
+}
+
+func (a *app) tenantsUpdate(ctx context.Context, args []string) error {
+	var q model.TenantQuotas
+
+	fs := a.flagSet("tenants update")
+	quotaFlags(fs, &q)
+
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.Wrap(errUsage, "expected exactly one tenant name")
+	}
+
+	name := strings.ToLower(strings.TrimSpace(fs.Arg(0)))
+
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if len(set) == 0 {
+		return errors.Wrap(errUsage, "no quotas to update")
+	}
+
+	rooms, closeStore, err := a.openRooms(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	tenants, err := rooms.ListTenants(ctx)
+	if err != nil {
+		return errors.Wrap(err, "list tenants")
+	}
+
+	// Квоты, не указанные флагами, остаются прежними
+	cur, found := model.TenantQuotas{}, false
+	for _, t := range tenants {
+		if t.Name == name {
+			cur, found = t.Quotas, true
+			break
+		}
+	}
+	if !found {
+		return errors.Wrapf(model.ErrNotFound, "tenant %q", name)
+	}
+
+	if set["max-rooms"] {
+		cur.MaxRooms = q.MaxRooms
+	}
+	if set["max-peers"] {
+		cur.MaxPeers = q.MaxPeers
+	}
+	if set["messages-per-min"] {
+		cur.MessagesPerMin = q.MessagesPerMin
+	}
+
+	tenant, err := rooms.UpdateTenantQuotas(ctx, name, cur)
+	if err != nil {
+		return errors.Wrap(err, "update tenant")
+	}
+
+	fmt.Fprintf(a.stdout, "tenant %s: max rooms %d, max peers %d, messages/min %d\n",
+		tenant.Name, tenant.Quotas.MaxRooms, tenant.Quotas.MaxPeers, tenant.Quotas.MessagesPerMin)
+
+	return nil
+}
@@ -3,34 +3,227 @@ package config
 import (
 	"flag"
 	"fmt"
+	"log/slog"
 	"net/url"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
+	"github.com/pkg/errors"
 )
 
 type Config struct {
-	Postgres Postgres `yaml:"postgres"`
-	Server   Server   `yaml:"server"`
+	Log       Log       `yaml:"log"`
+	Storage   Storage   `yaml:"storage"`
+	Postgres  Postgres  `yaml:"postgres"`
+	Server    Server    `yaml:"server"`
+	Janitor   Janitor   `yaml:"janitor"`
+	Tracing   Tracing   `yaml:"tracing"`
+	RateLimit RateLimit `yaml:"rate_limit"`
+	Auth      Auth      `yaml:"auth"`
+	Tenants   Tenants   `yaml:"tenants"`
 }
 
+const (
+	StoragePostgres = "postgres"
+	StorageSQLite   = "sqlite"
+	StorageMemory   = "memory"
+)
+
+// Log — настройки логов; Level: debug, info, warn или error,
+// Format: "text" (по умолчанию) или "json".
+type Log struct {
+	Level  string `yaml:"level" env:"SYNCPLAY_LOG_LEVEL" env-default:"info"`
+	Format string `yaml:"format" env:"SYNCPLAY_LOG_FORMAT" env-default:"text"`
+}
+
+const (
+	LogText = "text"
+	LogJSON = "json"
+)
+
+// SlogLevel разбирает Level.
+func (l Log) SlogLevel() (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(l.Level)); err != nil {
+		return 0, errors.Wrapf(err, "unknown log level %q", l.Level)
+	}
+
+	return level, nil
+}
+
+// Storage — выбор хранилища. Driver: "postgres" (по умолчанию), "sqlite" или "memory";
+// sqlite хранит данные в одном файле, memory не требует базы и теряет данные при перезапуске.
+// AutoMigrate — применять миграции при старте сервера; если выключено,
+// сервер не стартует со схемой старше ожидаемой.
+type Storage struct {
+	Driver      string `yaml:"driver" env:"SYNCPLAY_STORAGE_DRIVER" env-default:"postgres"`
+	AutoMigrate bool   `yaml:"auto_migrate" env:"SYNCPLAY_STORAGE_AUTO_MIGRATE"`
+	SQLite      SQLite `yaml:"sqlite"`
+}
+
+// SQLite — настройки встроенной базы; Path — путь к файлу базы.
+type SQLite struct {
+	Path string `yaml:"path" env:"SYNCPLAY_SQLITE_PATH" env-default:"syncplay.db"`
+}
+
+// URL возвращает адрес базы для golang-migrate.
+func (s SQLite) URL() string {
+	return "sqlite://" + s.Path
+}
+
+// Server — HTTP-сервер. AllowedOrigins — разрешённые источники для CORS
+// и WebSocket: "*" — любые, "https://*.example.com" — поддомены example.com;
+// RoomMaxPeers — максимум участников в комнате (0 — без ограничения).
+// При остановке сервер ждёт завершения запросов не дольше ShutdownTimeout,
+// а участникам советует переподключиться через ReconnectDelay.
+// MetricsAddr — отдельный адрес для /metrics (например, ":9090");
+// если пуст, метрики отдаются на основном порту.
+// TrustedProxies — подсети прокси (например, Traefik), которым доверяется
+// X-Forwarded-For; без них адрес клиента берётся из соединения.
+// AdminToken — bearer-токен API /admin на листенере MetricsAddr
+// (AdminTokenFile — файл с ним); без токена /admin доступен только по mTLS.
 type Server struct {
-	Host    string        `yaml:"host"`
-	Port    int           `yaml:"port"`
-	TimeOut time.Duration `yaml:"timeout"`
+	Host            string        `yaml:"host" env:"SYNCPLAY_SERVER_HOST" env-default:"0.0.0.0"`
+	Port            int           `yaml:"port" env:"SYNCPLAY_SERVER_PORT" env-default:"8080"`
+	TimeOut         time.Duration `yaml:"timeout" env:"SYNCPLAY_SERVER_TIMEOUT" env-default:"30s"`
+	AllowedOrigins  []string      `yaml:"allowed_origins" env:"SYNCPLAY_SERVER_ALLOWED_ORIGINS" env-default:"*"`
+	RoomMaxPeers    int           `yaml:"room_max_peers" env:"SYNCPLAY_SERVER_ROOM_MAX_PEERS" env-default:"0"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SYNCPLAY_SERVER_SHUTDOWN_TIMEOUT" env-default:"30s"`
+	ReconnectDelay  time.Duration `yaml:"reconnect_delay" env:"SYNCPLAY_SERVER_RECONNECT_DELAY" env-default:"5s"`
+	MetricsAddr     string        `yaml:"metrics_addr" env:"SYNCPLAY_SERVER_METRICS_ADDR"`
+	TrustedProxies  []string      `yaml:"trusted_proxies" env:"SYNCPLAY_SERVER_TRUSTED_PROXIES"`
+	AdminToken      string        `yaml:"admin_token" env:"SYNCPLAY_SERVER_ADMIN_TOKEN"`
+	AdminTokenFile  string        `yaml:"admin_token_file" env:"SYNCPLAY_SERVER_ADMIN_TOKEN_FILE"`
+	TLS             TLS           `yaml:"tls"`
+}
+
+// TLS — встроенный TLS для развёртываний без Traefik; включается, если задан CertFile.
+// Сертификат и ключ перечитываются с диска при изменении без перезапуска.
+// MinVersion: "1.2" или "1.3". ClientCA — CA клиентских сертификатов:
+// с ним листенер server.metrics_addr (админка) требует mTLS.
+// RedirectAddr — адрес HTTP-листенера, перенаправляющего запросы на HTTPS.
+type TLS struct {
+	CertFile     string `yaml:"cert_file" env:"SYNCPLAY_TLS_CERT_FILE"`
+	KeyFile      string `yaml:"key_file" env:"SYNCPLAY_TLS_KEY_FILE"`
+	MinVersion   string `yaml:"min_version" env:"SYNCPLAY_TLS_MIN_VERSION" env-default:"1.2"`
+	ClientCA     string `yaml:"client_ca" env:"SYNCPLAY_TLS_CLIENT_CA"`
+	RedirectAddr string `yaml:"redirect_addr" env:"SYNCPLAY_TLS_REDIRECT_ADDR"`
+}
+
+// Enabled сообщает, включён ли TLS.
+func (t TLS) Enabled() bool {
+	return t.CertFile != ""
 }
 
 func (s Server) String() string {
 	return fmt.Sprintf("%s:%d", s.Host, s.Port)
 }
 
+// Tracing — экспорт трейсов OpenTelemetry. Exporter: "none" (по умолчанию),
+// "stdout" или "otlp" — OTLP/HTTP на Endpoint. SampleRatio — доля
+// сохраняемых трейсов, начатых сервером; входящий контекст W3C учитывается всегда.
+type Tracing struct {
+	Exporter    string  `yaml:"exporter" env:"SYNCPLAY_TRACING_EXPORTER" env-default:"none"`
+	Endpoint    string  `yaml:"endpoint" env:"SYNCPLAY_TRACING_ENDPOINT" env-default:"localhost:4318"`
+	Insecure    bool    `yaml:"insecure" env:"SYNCPLAY_TRACING_INSECURE"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"SYNCPLAY_TRACING_SAMPLE_RATIO" env-default:"1"`
+	ServiceName string  `yaml:"service_name" env:"SYNCPLAY_TRACING_SERVICE_NAME" env-default:"syncplay"`
+}
+
+const (
+	TracingNone   = "none"
+	TracingStdout = "stdout"
+	TracingOTLP   = "otlp"
+)
+
+// RateLimit — ограничения частоты (token bucket): *Rate — событий в секунду,
+// *Burst — допустимый всплеск; Rate = 0 снимает ограничение.
+// HTTP и CreateRoom считаются по IP клиента, Peer — по участнику,
+// Room — по всей комнате. WSPerIP — максимум одновременных WebSocket-соединений
+// с одного IP (0 — без ограничения).
+type RateLimit struct {
+	HTTPRate        float64 `yaml:"http_rate" env:"SYNCPLAY_RATE_LIMIT_HTTP_RATE" env-default:"20"`
+	HTTPBurst       int     `yaml:"http_burst" env:"SYNCPLAY_RATE_LIMIT_HTTP_BURST" env-default:"40"`
+	CreateRoomRate  float64 `yaml:"create_room_rate" env:"SYNCPLAY_RATE_LIMIT_CREATE_ROOM_RATE" env-default:"0.2"`
+	CreateRoomBurst int     `yaml:"create_room_burst" env:"SYNCPLAY_RATE_LIMIT_CREATE_ROOM_BURST" env-default:"5"`
+	AuthRate        float64 `yaml:"auth_rate" env:"SYNCPLAY_RATE_LIMIT_AUTH_RATE" env-default:"0.2"`
+	AuthBurst       int     `yaml:"auth_burst" env:"SYNCPLAY_RATE_LIMIT_AUTH_BURST" env-default:"10"`
+	PeerRate        float64 `yaml:"peer_rate" env:"SYNCPLAY_RATE_LIMIT_PEER_RATE" env-default:"50"`
+	PeerBurst       int     `yaml:"peer_burst" env:"SYNCPLAY_RATE_LIMIT_PEER_BURST" env-default:"100"`
+	RoomRate        float64 `yaml:"room_rate" env:"SYNCPLAY_RATE_LIMIT_ROOM_RATE" env-default:"200"`
+	RoomBurst       int     `yaml:"room_burst" env:"SYNCPLAY_RATE_LIMIT_ROOM_BURST" env-default:"400"`
+	WSPerIP         int     `yaml:"ws_per_ip" env:"SYNCPLAY_RATE_LIMIT_WS_PER_IP" env-default:"20"`
+}
+
+// Auth — проверка JWT внешнего провайдера учётных записей. Включается,
+// если задан Secret (HS256) или JWKSFile — ключи RS/PS/ES в формате JWKS;
+// файл перечитывается при изменении, проверка раз в JWKSRefresh.
+// SecretFile — файл с секретом (например, Docker secret).
+// Required — запросы без токена отклоняются; без него токен необязателен,
+// но неверный токен всё равно отклоняется. Issuer и Audience, если заданы,
+// должны совпадать с iss и aud токена; Leeway — допуск расхождения часов.
+// Accounts включает встроенные учётные записи: регистрацию, вход и сессии
+// сроком SessionTTL; работает вместе с JWT или без него.
+type Auth struct {
+	Required    bool          `yaml:"required" env:"SYNCPLAY_AUTH_REQUIRED"`
+	Secret      string        `yaml:"secret" env:"SYNCPLAY_AUTH_SECRET"`
+	SecretFile  string        `yaml:"secret_file" env:"SYNCPLAY_AUTH_SECRET_FILE"`
+	JWKSFile    string        `yaml:"jwks_file" env:"SYNCPLAY_AUTH_JWKS_FILE"`
+	JWKSRefresh time.Duration `yaml:"jwks_refresh" env:"SYNCPLAY_AUTH_JWKS_REFRESH" env-default:"1m"`
+	Issuer      string        `yaml:"issuer" env:"SYNCPLAY_AUTH_ISSUER"`
+	Audience    string        `yaml:"audience" env:"SYNCPLAY_AUTH_AUDIENCE"`
+	Leeway      time.Duration `yaml:"leeway" env:"SYNCPLAY_AUTH_LEEWAY" env-default:"30s"`
+	Accounts    bool          `yaml:"accounts" env:"SYNCPLAY_AUTH_ACCOUNTS"`
+	SessionTTL  time.Duration `yaml:"session_ttl" env:"SYNCPLAY_AUTH_SESSION_TTL" env-default:"720h"`
+}
+
+// JWT сообщает, включена ли проверка JWT.
+func (a Auth) JWT() bool {
+	return a.Secret != "" || a.JWKSFile != ""
+}
+
+// Enabled сообщает, включена ли хоть какая-то аутентификация.
+func (a Auth) Enabled() bool {
+	return a.JWT() || a.Accounts
+}
+
+// Tenants — арендаторы с API-ключами. Ключ передаётся в X-API-Key
+// (для WebSocket — ещё и параметром api_key); запросы без ключа относятся
+// к арендатору по умолчанию. Required — запросы без ключа отклоняются.
+type Tenants struct {
+	Required bool `yaml:"required" env:"SYNCPLAY_TENANTS_REQUIRED"`
+}
+
+// Janitor — политика автоматической очистки комнат.
+// Action: "delete" удаляет комнату вместе с чатом, "archive" помечает её архивной.
+// IdleTTL = 0 отключает очистку простаивающих комнат.
+// RestoreWindow — сколько удалённая комната доступна для восстановления,
+// после этого janitor удаляет её окончательно.
+type Janitor struct {
+	Enabled       bool          `yaml:"enabled" env:"SYNCPLAY_JANITOR_ENABLED"`
+	Interval      time.Duration `yaml:"interval" env:"SYNCPLAY_JANITOR_INTERVAL" env-default:"1m"`
+	IdleTTL       time.Duration `yaml:"idle_ttl" env:"SYNCPLAY_JANITOR_IDLE_TTL" env-default:"0s"`
+	WarnBefore    time.Duration `yaml:"warn_before" env:"SYNCPLAY_JANITOR_WARN_BEFORE" env-default:"0s"`
+	Action        string        `yaml:"action" env:"SYNCPLAY_JANITOR_ACTION" env-default:"archive"`
+	BatchSize     int           `yaml:"batch_size" env:"SYNCPLAY_JANITOR_BATCH_SIZE" env-default:"100"`
+	RestoreWindow time.Duration `yaml:"restore_window" env:"SYNCPLAY_JANITOR_RESTORE_WINDOW" env-default:"168h"`
+}
+
+// Postgres — подключение к базе. Пароль можно не класть в конфиг:
+// PasswordFile указывает на файл с паролем (например, Docker secret).
+// SSLMode — режим libpq (disable, allow, prefer, require, verify-ca, verify-full),
+// SSLRootCert — сертификат CA для verify-ca и verify-full.
 type Postgres struct {
-	Host     string `yaml:"host"`
-	User     string `yaml:"user"`
-	Password string `yaml:"password"`
-	DBName   string `yaml:"dbname"`
-	Port     int    `yaml:"port"`
+	Host         string `yaml:"host" env:"SYNCPLAY_POSTGRES_HOST" env-default:"localhost"`
+	User         string `yaml:"user" env:"SYNCPLAY_POSTGRES_USER" env-default:"postgres"`
+	Password     string `yaml:"password" env:"SYNCPLAY_POSTGRES_PASSWORD"`
+	PasswordFile string `yaml:"password_file" env:"SYNCPLAY_POSTGRES_PASSWORD_FILE"`
+	DBName       string `yaml:"dbname" env:"SYNCPLAY_POSTGRES_DB" env-default:"syncplay"`
+	Port         int    `yaml:"port" env:"SYNCPLAY_POSTGRES_PORT" env-default:"5432"`
+	SSLMode      string `yaml:"sslmode" env:"SYNCPLAY_POSTGRES_SSLMODE" env-default:"disable"`
+	SSLRootCert  string `yaml:"sslrootcert" env:"SYNCPLAY_POSTGRES_SSLROOTCERT"`
 }
 
 func (p Postgres) String() string {
@@ -41,8 +234,16 @@ func (p Postgres) String() string {
 		Path:   p.DBName,
 	}
 
+	sslMode := p.SSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+
 	q := u.Query()
-	q.Set("sslmode", "disable")
+	q.Set("sslmode", sslMode)
+	if p.SSLRootCert != "" {
+		q.Set("sslrootcert", p.SSLRootCert)
+	}
 
 	u.RawQuery = q.Encode()
 
@@ -64,17 +265,86 @@ func MustConfig(p *string) *Config {
 		path = *p
 	}
 
-	if _, ok := os.Stat(path); os.IsNotExist(ok) {
-		panic("Config file does not exist: " + path)
+	cfg, err := Load(path)
+	if err != nil {
+		panic(err.Error())
 	}
 
+	return cfg
+}
+
+// Load читает конфиг из файла, переменные окружения SYNCPLAY_* переопределяют
+// значения из него. С пустым path конфиг собирается только из окружения
+// и значений по умолчанию.
+func Load(path string) (*Config, error) {
 	cfg := New()
 
-	if err := cleanenv.ReadConfig(path, cfg); err != nil {
-		panic("failed to read config: " + err.Error())
+	if path == "" {
+		if err := cleanenv.ReadEnv(cfg); err != nil {
+			return nil, errors.Wrap(err, "failed to read config from env")
+		}
+	} else {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return nil, errors.New("Config file does not exist: " + path)
+		}
+
+		if err := cleanenv.ReadConfig(path, cfg); err != nil {
+			return nil, errors.Wrap(err, "failed to read config")
+		}
 	}
 
-	return cfg
+	if err := cfg.Postgres.readPasswordFile(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Auth.readSecretFile(); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Server.readAdminTokenFile(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// readPasswordFile подставляет пароль из PasswordFile. Завершающий перевод
+// строки отбрасывается: его оставляет большинство редакторов и echo.
+func (p *Postgres) readPasswordFile() error {
+	if p.PasswordFile == "" {
+		return nil
+	}
+	if p.Password != "" {
+		return errors.New("postgres: set either password or password_file, not both")
+	}
+
+	b, err := os.ReadFile(p.PasswordFile)
+	if err != nil {
+		return errors.Wrap(err, "postgres.password_file")
+	}
+
+	p.Password = strings.TrimRight(string(b), "\r\n")
+
+	return nil
+}
+
+// readSecretFile подставляет секрет из SecretFile, как readPasswordFile.
+func (a *Auth) readSecretFile() error {
+	if a.SecretFile == "" {
+		return nil
+	}
+	if a.Secret != "" {
+		return errors.New("auth: set either secret or secret_file, not both")
+	}
+
+	b, err := os.ReadFile(a.SecretFile)
+	if err != nil {
+		return errors.Wrap(err, "auth.secret_file")
+	}
+
+	a.Secret = strings.TrimRight(string(b), "\r\n")
+
+	return nil
 }
 
 func fetchConfigPath() string {
@@ -90,3 +360,22 @@ func fetchConfigPath() string {
 
 	return res
 }
+
+// readAdminTokenFile подставляет токен админки из AdminTokenFile, как readPasswordFile.
+func (s *Server) readAdminTokenFile() error {
+	if s.AdminTokenFile == "" {
+		return nil
+	}
+	if s.AdminToken != "" {
+		return errors.New("server: set either admin_token or admin_token_file, not both")
+	}
+
+	b, err := os.ReadFile(s.AdminTokenFile)
+	if err != nil {
+		return errors.Wrap(err, "server.admin_token_file")
+	}
+
+	s.AdminToken = strings.TrimRight(string(b), "\r\n")
+
+	return nil
+}
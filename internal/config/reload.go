@@ -0,0 +1,102 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// reloadable — поля, которые применяются к работающему серверу без перезапуска;
+// имя секции разрешает все её поля.
+var reloadable = map[string]bool{
+	"log.level":              true,
+	"server.allowed_origins": true,
+	"server.room_max_peers":  true,
+	"rate_limit":             true,
+}
+
+// Change — изменённое поле конфига; Field — путь по yaml-именам.
+type Change struct {
+	Field string
+	Old   any
+	New   any
+}
+
+// Diff сравнивает текущий и новый конфиги. Если изменились поля, которые
+// нельзя применить без перезапуска (например, адрес сервера), возвращает
+// ошибку с их списком, и новый конфиг применять нельзя.
+func Diff(cur, next *Config) ([]Change, error) {
+	var changes []Change
+	diff("", reflect.ValueOf(*cur), reflect.ValueOf(*next), &changes)
+
+	var fixed []string
+	for _, c := range changes {
+		if !isReloadable(c.Field) {
+			fixed = append(fixed, c.Field)
+		}
+	}
+
+	if len(fixed) > 0 {
+		return nil, errors.Errorf("changes to %s require a restart", strings.Join(fixed, ", "))
+	}
+
+	return changes, nil
+}
+
+func isReloadable(field string) bool {
+	for {
+		if reloadable[field] {
+			return true
+		}
+
+		i := strings.LastIndexByte(field, '.')
+		if i < 0 {
+			return false
+		}
+		field = field[:i]
+	}
+}
+
+func diff(prefix string, a, b reflect.Value, out *[]Change) {
+	if !isSection(a.Type()) {
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			*out = append(*out, Change{Field: prefix, Old: a.Interface(), New: b.Interface()})
+		}
+		return
+	}
+
+	for i := range a.NumField() {
+		f := a.Type().Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "" {
+			name = f.Name
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		diff(name, a.Field(i), b.Field(i), out)
+	}
+}
+
+// isSection сообщает, что t — секция конфига: структура с полями,
+// помеченными yaml. Прочие структуры (time.Time, tls.Config, …)
+// сравниваются целиком, в их неэкспортируемые поля не заходим.
+func isSection(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+
+	for i := range t.NumField() {
+		if f := t.Field(i); f.IsExported() && f.Tag.Get("yaml") != "" {
+			return true
+		}
+	}
+
+	return false
+}
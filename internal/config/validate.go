@@ -0,0 +1,279 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// minAdminTokenLen — минимальная длина токена админки.
+const minAdminTokenLen = 16
+
+var sslModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// ValidationError перечисляет все некорректные поля конфига сразу,
+// чтобы их не приходилось исправлять по одному.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid config:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+type validator struct {
+	problems []string
+}
+
+func (v *validator) addf(format string, args ...any) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+func (v *validator) port(field string, port int) {
+	if port < 1 || port > 65535 {
+		v.addf("%s: must be between 1 and 65535, got %d", field, port)
+	}
+}
+
+func (v *validator) nonNegative(field string, d time.Duration) {
+	if d < 0 {
+		v.addf("%s: must not be negative, got %s", field, d)
+	}
+}
+
+func (v *validator) required(field, value, hint string) {
+	if strings.TrimSpace(value) == "" {
+		v.addf("%s: is required %s", field, hint)
+	}
+}
+
+// Validate проверяет значения конфига и возвращает *ValidationError
+// со всеми найденными ошибками.
+func (c *Config) Validate() error {
+	var v validator
+
+	if _, err := c.Log.SlogLevel(); err != nil {
+		v.addf("log.level: unknown level %q, expected debug, info, warn or error", c.Log.Level)
+	}
+	switch c.Log.Format {
+	case "", LogText, LogJSON:
+	default:
+		v.addf("log.format: unknown format %q, expected %s or %s", c.Log.Format, LogText, LogJSON)
+	}
+
+	switch c.Storage.Driver {
+	case "", StoragePostgres:
+		c.Postgres.validate(&v)
+	case StorageSQLite:
+		v.required("storage.sqlite.path", c.Storage.SQLite.Path, "for the sqlite driver")
+	case StorageMemory:
+	default:
+		v.addf("storage.driver: unknown driver %q, expected %s, %s or %s",
+			c.Storage.Driver, StoragePostgres, StorageSQLite, StorageMemory)
+	}
+
+	v.port("server.port", c.Server.Port)
+	v.nonNegative("server.timeout", c.Server.TimeOut)
+	v.nonNegative("server.shutdown_timeout", c.Server.ShutdownTimeout)
+	v.nonNegative("server.reconnect_delay", c.Server.ReconnectDelay)
+	if c.Server.MetricsAddr != "" {
+		if _, _, err := net.SplitHostPort(c.Server.MetricsAddr); err != nil {
+			v.addf("server.metrics_addr: expected host:port, got %q", c.Server.MetricsAddr)
+		}
+	}
+	if c.Server.AdminToken != "" {
+		if c.Server.MetricsAddr == "" {
+			v.addf("server.admin_token: requires server.metrics_addr, the admin API is served there")
+		}
+		if len(c.Server.AdminToken) < minAdminTokenLen {
+			v.addf("server.admin_token: must be at least %d bytes, got %d", minAdminTokenLen, len(c.Server.AdminToken))
+		}
+	}
+	if c.Server.RoomMaxPeers < 0 {
+		v.addf("server.room_max_peers: must not be negative, got %d", c.Server.RoomMaxPeers)
+	}
+	for _, origin := range c.Server.AllowedOrigins {
+		if !validOrigin(origin) {
+			v.addf("server.allowed_origins: expected \"*\" or scheme://host[:port], got %q", origin)
+		}
+	}
+	for _, cidr := range c.Server.TrustedProxies {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			v.addf("server.trusted_proxies: expected CIDR, got %q", cidr)
+		}
+	}
+
+	c.Server.TLS.validate(&v, c.Server.MetricsAddr)
+	c.Janitor.validate(&v)
+	c.Tracing.validate(&v)
+	c.RateLimit.validate(&v)
+	c.Auth.validate(&v)
+
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+
+	return nil
+}
+
+// validOrigin проверяет шаблон источника: "*", "https://example.com"
+// или "https://*.example.com".
+func validOrigin(origin string) bool {
+	if origin == "*" {
+		return true
+	}
+
+	scheme, host, ok := strings.Cut(strings.TrimSuffix(origin, "/"), "://")
+	if !ok || scheme == "" {
+		return false
+	}
+	host = strings.TrimPrefix(host, "*.")
+
+	u, err := url.Parse(scheme + "://" + host)
+
+	return err == nil && host != "" && u.Host == host && !strings.Contains(host, "*")
+}
+
+func (p Postgres) validate(v *validator) {
+	v.required("postgres.host", p.Host, "(SYNCPLAY_POSTGRES_HOST)")
+	v.required("postgres.user", p.User, "(SYNCPLAY_POSTGRES_USER)")
+	v.required("postgres.dbname", p.DBName, "(SYNCPLAY_POSTGRES_DB)")
+	v.port("postgres.port", p.Port)
+
+	if p.SSLMode != "" && !slices.Contains(sslModes, p.SSLMode) {
+		v.addf("postgres.sslmode: unknown mode %q, expected one of %s", p.SSLMode, strings.Join(sslModes, ", "))
+	}
+
+	if p.SSLRootCert != "" {
+		if _, err := os.Stat(p.SSLRootCert); err != nil {
+			v.addf("postgres.sslrootcert: cannot read %q: %v", p.SSLRootCert, err)
+		}
+	}
+}
+
+func (t TLS) validate(v *validator, metricsAddr string) {
+	if !t.Enabled() {
+		if t.KeyFile != "" || t.ClientCA != "" || t.RedirectAddr != "" {
+			v.addf("server.tls.cert_file: is required when other server.tls fields are set")
+		}
+		return
+	}
+
+	v.required("server.tls.key_file", t.KeyFile, "with server.tls.cert_file")
+	files := []struct{ field, path string }{
+		{"server.tls.cert_file", t.CertFile},
+		{"server.tls.key_file", t.KeyFile},
+		{"server.tls.client_ca", t.ClientCA},
+	}
+	for _, f := range files {
+		if f.path == "" {
+			continue
+		}
+		if _, err := os.Stat(f.path); err != nil {
+			v.addf("%s: cannot read %q: %v", f.field, f.path, err)
+		}
+	}
+
+	switch t.MinVersion {
+	case "", "1.2", "1.3":
+	default:
+		v.addf("server.tls.min_version: expected 1.2 or 1.3, got %q", t.MinVersion)
+	}
+
+	if t.ClientCA != "" && metricsAddr == "" {
+		v.addf("server.tls.client_ca: requires server.metrics_addr, mTLS applies to that listener")
+	}
+
+	if t.RedirectAddr != "" {
+		if _, _, err := net.SplitHostPort(t.RedirectAddr); err != nil {
+			v.addf("server.tls.redirect_addr: expected host:port, got %q", t.RedirectAddr)
+		}
+	}
+}
+
+func (j Janitor) validate(v *validator) {
+	switch j.Action {
+	case "", "delete", "archive":
+	default:
+		v.addf("janitor.action: unknown action %q, expected delete or archive", j.Action)
+	}
+
+	v.nonNegative("janitor.interval", j.Interval)
+	v.nonNegative("janitor.idle_ttl", j.IdleTTL)
+	v.nonNegative("janitor.warn_before", j.WarnBefore)
+	v.nonNegative("janitor.restore_window", j.RestoreWindow)
+
+	if j.BatchSize < 0 {
+		v.addf("janitor.batch_size: must not be negative, got %d", j.BatchSize)
+	}
+}
+
+func (t Tracing) validate(v *validator) {
+	switch t.Exporter {
+	case "", TracingNone, TracingStdout:
+	case TracingOTLP:
+		v.required("tracing.endpoint", t.Endpoint, "for the otlp exporter")
+	default:
+		v.addf("tracing.exporter: unknown exporter %q, expected %s, %s or %s",
+			t.Exporter, TracingNone, TracingStdout, TracingOTLP)
+	}
+
+	if t.SampleRatio < 0 || t.SampleRatio > 1 {
+		v.addf("tracing.sample_ratio: must be between 0 and 1, got %g", t.SampleRatio)
+	}
+}
+
+func (r RateLimit) validate(v *validator) {
+	limits := []struct {
+		name  string
+		rate  float64
+		burst int
+	}{
+		{"http", r.HTTPRate, r.HTTPBurst},
+		{"create_room", r.CreateRoomRate, r.CreateRoomBurst},
+		{"auth", r.AuthRate, r.AuthBurst},
+		{"peer", r.PeerRate, r.PeerBurst},
+		{"room", r.RoomRate, r.RoomBurst},
+	}
+
+	for _, l := range limits {
+		if l.rate < 0 {
+			v.addf("rate_limit.%s_rate: must not be negative, got %g", l.name, l.rate)
+		}
+		if l.rate > 0 && l.burst < 1 {
+			v.addf("rate_limit.%s_burst: must be at least 1 when %s_rate is set, got %d", l.name, l.name, l.burst)
+		}
+	}
+
+	if r.WSPerIP < 0 {
+		v.addf("rate_limit.ws_per_ip: must not be negative, got %d", r.WSPerIP)
+	}
+}
+
+// minSecretLen — минимальная длина секрета HS256 (RFC 7518, 3.2).
+const minSecretLen = 32
+
+func (a Auth) validate(v *validator) {
+	if a.Required && !a.Enabled() {
+		v.addf("auth.required: needs auth.secret, auth.jwks_file or auth.accounts")
+	}
+	if a.Secret != "" && a.JWKSFile != "" {
+		v.addf("auth: set either secret or jwks_file, not both")
+	}
+	if a.Secret != "" && len(a.Secret) < minSecretLen {
+		v.addf("auth.secret: must be at least %d bytes, got %d", minSecretLen, len(a.Secret))
+	}
+	if a.JWKSFile != "" {
+		if _, err := os.Stat(a.JWKSFile); err != nil {
+			v.addf("auth.jwks_file: cannot read %q: %v", a.JWKSFile, err)
+		}
+	}
+
+	v.nonNegative("auth.jwks_refresh", a.JWKSRefresh)
+	v.nonNegative("auth.leeway", a.Leeway)
+	v.nonNegative("auth.session_ttl", a.SessionTTL)
+}
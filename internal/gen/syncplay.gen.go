@@ -12,6 +12,7 @@ import (
 	"net/url"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/labstack/echo/v4"
@@ -19,9 +20,71 @@ import (
 	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
+// Defines values for CheckStatus.
+const (
+	CheckStatusFail CheckStatus = "fail"
+	CheckStatusOk   CheckStatus = "ok"
+)
+
+// Defines values for ProblemCode.
+const (
+	BadRequest       ProblemCode = "bad-request"
+	Conflict         ProblemCode = "conflict"
+	Forbidden        ProblemCode = "forbidden"
+	Internal         ProblemCode = "internal"
+	MethodNotAllowed ProblemCode = "method-not-allowed"
+	NotFound         ProblemCode = "not-found"
+	QuotaExceeded    ProblemCode = "quota-exceeded"
+	RateLimited      ProblemCode = "rate-limited"
+	Unauthorized     ProblemCode = "unauthorized"
+	Unavailable      ProblemCode = "unavailable"
+	ValidationFailed ProblemCode = "validation-failed"
+)
+
+// Defines values for RoomVisibility.
+const (
+	RoomVisibilityPrivate RoomVisibility = "private"
+	RoomVisibilityPublic  RoomVisibility = "public"
+)
+
+// Defines values for RoomParamsVisibility.
+const (
+	RoomParamsVisibilityPrivate RoomParamsVisibility = "private"
+	RoomParamsVisibilityPublic  RoomParamsVisibility = "public"
+)
+
+// Defines values for SearchHitKind.
+const (
+	SearchHitKindMessage SearchHitKind = "message"
+	SearchHitKindRoom    SearchHitKind = "room"
+)
+
+// Defines values for ServerState.
+const (
+	ServerStateDraining ServerState = "draining"
+	ServerStateFail     ServerState = "fail"
+	ServerStateOk       ServerState = "ok"
+)
+
+// Defines values for ListRoomsParamsVisibility.
+const (
+	Private ListRoomsParamsVisibility = "private"
+	Public  ListRoomsParamsVisibility = "public"
+)
+
+// Defines values for ListRoomsParamsSort.
+const (
+	CreatedAt      ListRoomsParamsSort = "created_at"
+	MinusCreatedAt ListRoomsParamsSort = "-created_at"
+	MinusTitle     ListRoomsParamsSort = "-title"
+	Title          ListRoomsParamsSort = "title"
+)
+
 // CreateRoom defines model for CreateRoom.
 type CreateRoom struct {
-	RoomId openapi_types.UUID `json:"room_id"`
+	// OwnerToken Секрет владельца комнаты; показывается только один раз
+	OwnerToken string             `json:"owner_token"`
+	RoomId     openapi_types.UUID `json:"room_id"`
 }
 
 // GetInfo defines model for GetInfo.
@@ -29,26 +92,316 @@ type GetInfo struct {
 	Version string `json:"version"`
 }
 
-// ErrorResponse Ответ с информацией об ошибке
-type ErrorResponse struct {
+// RoomList defines model for RoomList.
+type RoomList struct {
+	Items []Room `json:"items"`
+
+	// NextCursor Курсор следующей страницы; отсутствует на последней странице
+	NextCursor *string `json:"next_cursor,omitempty"`
+}
+
+// SearchResult defines model for SearchResult.
+type SearchResult struct {
+	Items []SearchHit `json:"items"`
+}
+
+// Check Результат проверки компонента
+type Check struct {
+	// Error Причина неудачи; /readyz и /api/v1/status её не отдают, она пишется в лог и видна в /admin/dashboard
+	Error *string `json:"error,omitempty"`
+
+	// LatencyMs Длительность проверки в миллисекундах
+	LatencyMs float64 `json:"latency_ms"`
+
+	// Name Компонент
+	Name   string      `json:"name"`
+	Status CheckStatus `json:"status"`
+}
+
+// CheckStatus defines model for Check.Status.
+type CheckStatus string
+
+// Credentials Имя и пароль встроенной учётной записи
+type Credentials struct {
+	// Password Не короче 8 символов
+	Password string `json:"password"`
+
+	// Username 3–32 символа: латинские буквы, цифры, '.', '_' или '-'; регистр не важен
+	Username string `json:"username"`
+}
+
+// Problem Ответ об ошибке в формате RFC 7807 (application/problem+json)
+type Problem struct {
+	// Code Стабильный машиночитаемый код ошибки
+	Code ProblemCode `json:"code"`
+
 	// Detail Информация об ошибке
-	Detail string `json:"detail"`
+	Detail *string `json:"detail,omitempty"`
+
+	// Status HTTP-статус
+	Status int `json:"status"`
+
+	// Title Краткое описание HTTP-статуса
+	Title string `json:"title"`
+
+	// Type URI типа ошибки
+	Type string `json:"type"`
+}
+
+// ProblemCode Стабильный машиночитаемый код ошибки
+type ProblemCode string
+
+// Readiness Готовность сервера принимать запросы
+type Readiness struct {
+	Checks []Check `json:"checks"`
+
+	// Status ok — сервер готов, fail — не прошла одна из проверок, draining — сервер останавливается
+	Status ServerState `json:"status"`
+}
+
+// Room Комната с метаданными и текущей заполненностью
+type Room struct {
+	// CreatedAt Время создания
+	CreatedAt time.Time `json:"created_at"`
+
+	// CreatedBy Создатель: local:<id учётной записи> или jwt:<iss>|<sub> из JWT; отсутствует у анонимных комнат
+	CreatedBy *string `json:"created_by,omitempty"`
+
+	// Description Описание комнаты
+	Description string `json:"description"`
+
+	// ExpiresAt Момент истечения комнаты, если задан
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
+	// Peers Количество подключённых участников
+	Peers  int                `json:"peers"`
+	RoomId openapi_types.UUID `json:"room_id"`
+
+	// Tags Теги комнаты
+	Tags []string `json:"tags"`
+
+	// Title Название комнаты
+	Title string `json:"title"`
+
+	// Visibility Видимость комнаты
+	Visibility RoomVisibility `json:"visibility"`
+}
+
+// RoomVisibility Видимость комнаты
+type RoomVisibility string
+
+// RoomParams Метаданные создаваемой комнаты
+type RoomParams struct {
+	// Description Описание комнаты
+	Description *string `json:"description,omitempty"`
+
+	// ExpiresAt Момент, после которого комната будет закрыта и удалена
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
+	// Tags Теги комнаты
+	Tags *[]string `json:"tags,omitempty"`
+
+	// Title Название комнаты
+	Title *string `json:"title,omitempty"`
+
+	// Ttl Время жизни комнаты в секундах; альтернатива expires_at
+	Ttl *int `json:"ttl,omitempty"`
+
+	// Visibility Видимость комнаты
+	Visibility *RoomParamsVisibility `json:"visibility,omitempty"`
+}
+
+// RoomParamsVisibility Видимость комнаты
+type RoomParamsVisibility string
+
+// SearchHit Результат полнотекстового поиска
+type SearchHit struct {
+	CreatedAt time.Time `json:"created_at"`
+
+	// Kind Тип найденного объекта
+	Kind SearchHitKind `json:"kind"`
+
+	// MessageId Идентификатор сообщения чата (для kind=message)
+	MessageId *int64 `json:"message_id,omitempty"`
+
+	// Rank Релевантность
+	Rank   float32            `json:"rank"`
+	RoomId openapi_types.UUID `json:"room_id"`
+
+	// Sender Отправитель сообщения (для kind=message)
+	Sender *string `json:"sender,omitempty"`
+
+	// Snippet Фрагмент текста с HTML-подсветкой совпадений в <mark>
+	Snippet string `json:"snippet"`
+
+	// Title Название комнаты (для kind=room)
+	Title *string `json:"title,omitempty"`
 }
 
+// SearchHitKind Тип найденного объекта
+type SearchHitKind string
+
+// ServerState ok — сервер готов, fail — не прошла одна из проверок, draining — сервер останавливается
+type ServerState string
+
+// ServerStatus Подробное состояние сервера
+type ServerStatus struct {
+	Checks []Check `json:"checks"`
+
+	// Peers Подключённые участники
+	Peers int `json:"peers"`
+
+	// Rooms Комнаты с подключёнными участниками
+	Rooms int `json:"rooms"`
+
+	// Status ok — сервер готов, fail — не прошла одна из проверок, draining — сервер останавливается
+	Status ServerState `json:"status"`
+
+	// UptimeSeconds Время работы процесса в секундах
+	UptimeSeconds int    `json:"uptime_seconds"`
+	Version       string `json:"version"`
+}
+
+// Session Сессия после входа; token передаётся как Bearer или приходит в cookie
+type Session struct {
+	ExpiresAt time.Time `json:"expires_at"`
+
+	// Token Токен сессии; показывается только один раз
+	Token string `json:"token"`
+
+	// User Встроенная учётная запись
+	User User `json:"user"`
+}
+
+// User Встроенная учётная запись
+type User struct {
+	CreatedAt time.Time          `json:"created_at"`
+	Id        openapi_types.UUID `json:"id"`
+	Username  string             `json:"username"`
+}
+
+// ListRoomsParams defines parameters for ListRooms.
+type ListRoomsParams struct {
+	// Limit Размер страницы
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Cursor Курсор следующей страницы из next_cursor
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+
+	// Visibility Фильтр по видимости комнаты; по умолчанию только публичные. Приватные комнаты видны только владельцу вместе с mine=true
+	Visibility *ListRoomsParamsVisibility `form:"visibility,omitempty" json:"visibility,omitempty"`
+
+	// CreatedAfter Только комнаты, созданные после указанного момента
+	CreatedAfter *time.Time `form:"created_after,omitempty" json:"created_after,omitempty"`
+
+	// Tag Фильтр по тегу
+	Tag *string `form:"tag,omitempty" json:"tag,omitempty"`
+
+	// HasActivePeers Только комнаты с подключёнными участниками (true) или без них (false)
+	HasActivePeers *bool `form:"has_active_peers,omitempty" json:"has_active_peers,omitempty"`
+
+	// Mine Только комнаты, созданные текущим пользователем; требует аутентификации
+	Mine *bool `form:"mine,omitempty" json:"mine,omitempty"`
+
+	// Sort Сортировка; префикс '-' означает убывание
+	Sort *ListRoomsParamsSort `form:"sort,omitempty" json:"sort,omitempty"`
+}
+
+// ListRoomsParamsVisibility defines parameters for ListRooms.
+type ListRoomsParamsVisibility string
+
+// ListRoomsParamsSort defines parameters for ListRooms.
+type ListRoomsParamsSort string
+
+// DeleteRoomParams defines parameters for DeleteRoom.
+type DeleteRoomParams struct {
+	// Purge Удалить окончательно, без возможности восстановления
+	Purge *bool `form:"purge,omitempty" json:"purge,omitempty"`
+
+	// XRoomToken Секрет владельца комнаты; не нужен создателю комнаты, вошедшему в учётную запись
+	XRoomToken *string `json:"X-Room-Token,omitempty"`
+}
+
+// ArchiveRoomParams defines parameters for ArchiveRoom.
+type ArchiveRoomParams struct {
+	// XRoomToken Секрет владельца комнаты; не нужен создателю комнаты, вошедшему в учётную запись
+	XRoomToken *string `json:"X-Room-Token,omitempty"`
+}
+
+// RestoreRoomParams defines parameters for RestoreRoom.
+type RestoreRoomParams struct {
+	// XRoomToken Секрет владельца комнаты; не нужен создателю комнаты, вошедшему в учётную запись
+	XRoomToken *string `json:"X-Room-Token,omitempty"`
+}
+
+// SearchParams defines parameters for Search.
+type SearchParams struct {
+	// Q Поисковый запрос (синтаксис websearch)
+	Q string `form:"q" json:"q"`
+
+	// RoomId UUID комнаты для поиска по истории чата
+	RoomId *openapi_types.UUID `form:"room_id,omitempty" json:"room_id,omitempty"`
+
+	// Limit Максимальное количество результатов
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// XRoomToken Секрет владельца комнаты; нужен вместе с room_id, если запрос не от создателя комнаты
+	XRoomToken *string `json:"X-Room-Token,omitempty"`
+}
+
+// LoginJSONRequestBody defines body for Login for application/json ContentType.
+type LoginJSONRequestBody = Credentials
+
+// RegisterJSONRequestBody defines body for Register for application/json ContentType.
+type RegisterJSONRequestBody = Credentials
+
+// CreateRoomJSONRequestBody defines body for CreateRoom for application/json ContentType.
+type CreateRoomJSONRequestBody = RoomParams
+
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
 
+	// (POST /api/v1/auth/login)
+	Login(ctx echo.Context) error
+
+	// (POST /api/v1/auth/logout)
+	Logout(ctx echo.Context) error
+
+	// (POST /api/v1/auth/register)
+	Register(ctx echo.Context) error
+
 	// (GET /api/v1/info)
 	GetInfo(ctx echo.Context) error
 
+	// (GET /api/v1/rooms)
+	ListRooms(ctx echo.Context, params ListRoomsParams) error
+
 	// (POST /api/v1/rooms)
 	CreateRoom(ctx echo.Context) error
 
 	// (DELETE /api/v1/rooms/{id})
-	DeleteRoom(ctx echo.Context, id openapi_types.UUID) error
+	DeleteRoom(ctx echo.Context, id openapi_types.UUID, params DeleteRoomParams) error
+
+	// (POST /api/v1/rooms/{id}/archive)
+	ArchiveRoom(ctx echo.Context, id openapi_types.UUID, params ArchiveRoomParams) error
+
+	// (POST /api/v1/rooms/{id}/restore)
+	RestoreRoom(ctx echo.Context, id openapi_types.UUID, params RestoreRoomParams) error
+
+	// (GET /api/v1/search)
+	Search(ctx echo.Context, params SearchParams) error
+
+	// (GET /api/v1/status)
+	GetStatus(ctx echo.Context) error
 
 	// (GET /api/v1/ws/{id})
 	ConnectRoomWS(ctx echo.Context, id openapi_types.UUID) error
+
+	// (GET /healthz)
+	Healthz(ctx echo.Context) error
+
+	// (GET /readyz)
+	Readyz(ctx echo.Context) error
 }
 
 // ServerInterfaceWrapper converts echo contexts to parameters.
@@ -56,6 +409,33 @@ type ServerInterfaceWrapper struct {
 	Handler ServerInterface
 }
 
+// Login converts echo context to params.
+func (w *ServerInterfaceWrapper) Login(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.Login(ctx)
+	return err
+}
+
+// Logout converts echo context to params.
+func (w *ServerInterfaceWrapper) Logout(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.Logout(ctx)
+	return err
+}
+
+// Register converts echo context to params.
+func (w *ServerInterfaceWrapper) Register(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.Register(ctx)
+	return err
+}
+
 // GetInfo converts echo context to params.
 func (w *ServerInterfaceWrapper) GetInfo(ctx echo.Context) error {
 	var err error
@@ -65,6 +445,73 @@ func (w *ServerInterfaceWrapper) GetInfo(ctx echo.Context) error {
 	return err
 }
 
+// ListRooms converts echo context to params.
+func (w *ServerInterfaceWrapper) ListRooms(ctx echo.Context) error {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListRoomsParams
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", ctx.QueryParams(), &params.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+	}
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", ctx.QueryParams(), &params.Cursor)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter cursor: %s", err))
+	}
+
+	// ------------- Optional query parameter "visibility" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "visibility", ctx.QueryParams(), &params.Visibility)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter visibility: %s", err))
+	}
+
+	// ------------- Optional query parameter "created_after" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "created_after", ctx.QueryParams(), &params.CreatedAfter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter created_after: %s", err))
+	}
+
+	// ------------- Optional query parameter "tag" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "tag", ctx.QueryParams(), &params.Tag)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter tag: %s", err))
+	}
+
+	// ------------- Optional query parameter "has_active_peers" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "has_active_peers", ctx.QueryParams(), &params.HasActivePeers)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter has_active_peers: %s", err))
+	}
+
+	// ------------- Optional query parameter "mine" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "mine", ctx.QueryParams(), &params.Mine)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter mine: %s", err))
+	}
+
+	// ------------- Optional query parameter "sort" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sort", ctx.QueryParams(), &params.Sort)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter sort: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ListRooms(ctx, params)
+	return err
+}
+
 // CreateRoom converts echo context to params.
 func (w *ServerInterfaceWrapper) CreateRoom(ctx echo.Context) error {
 	var err error
@@ -85,8 +532,164 @@ func (w *ServerInterfaceWrapper) DeleteRoom(ctx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
 	}
 
+	// Parameter object where we will unmarshal all parameters from the context
+	var params DeleteRoomParams
+	// ------------- Optional query parameter "purge" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "purge", ctx.QueryParams(), &params.Purge)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter purge: %s", err))
+	}
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "X-Room-Token" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("X-Room-Token")]; found {
+		var XRoomToken string
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for X-Room-Token, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "X-Room-Token", valueList[0], &XRoomToken, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter X-Room-Token: %s", err))
+		}
+
+		params.XRoomToken = &XRoomToken
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.DeleteRoom(ctx, id, params)
+	return err
+}
+
+// ArchiveRoom converts echo context to params.
+func (w *ServerInterfaceWrapper) ArchiveRoom(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ArchiveRoomParams
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "X-Room-Token" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("X-Room-Token")]; found {
+		var XRoomToken string
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for X-Room-Token, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "X-Room-Token", valueList[0], &XRoomToken, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter X-Room-Token: %s", err))
+		}
+
+		params.XRoomToken = &XRoomToken
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.ArchiveRoom(ctx, id, params)
+	return err
+}
+
+// RestoreRoom converts echo context to params.
+func (w *ServerInterfaceWrapper) RestoreRoom(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id openapi_types.UUID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", ctx.Param("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params RestoreRoomParams
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "X-Room-Token" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("X-Room-Token")]; found {
+		var XRoomToken string
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for X-Room-Token, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "X-Room-Token", valueList[0], &XRoomToken, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter X-Room-Token: %s", err))
+		}
+
+		params.XRoomToken = &XRoomToken
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.RestoreRoom(ctx, id, params)
+	return err
+}
+
+// Search converts echo context to params.
+func (w *ServerInterfaceWrapper) Search(ctx echo.Context) error {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params SearchParams
+	// ------------- Required query parameter "q" -------------
+
+	err = runtime.BindQueryParameter("form", true, true, "q", ctx.QueryParams(), &params.Q)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter q: %s", err))
+	}
+
+	// ------------- Optional query parameter "room_id" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "room_id", ctx.QueryParams(), &params.RoomId)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter room_id: %s", err))
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", ctx.QueryParams(), &params.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+	}
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "X-Room-Token" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("X-Room-Token")]; found {
+		var XRoomToken string
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for X-Room-Token, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "X-Room-Token", valueList[0], &XRoomToken, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter X-Room-Token: %s", err))
+		}
+
+		params.XRoomToken = &XRoomToken
+	}
+
 	// Invoke the callback with all the unmarshaled arguments
-	err = w.Handler.DeleteRoom(ctx, id)
+	err = w.Handler.Search(ctx, params)
+	return err
+}
+
+// GetStatus converts echo context to params.
+func (w *ServerInterfaceWrapper) GetStatus(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetStatus(ctx)
 	return err
 }
 
@@ -106,6 +709,24 @@ func (w *ServerInterfaceWrapper) ConnectRoomWS(ctx echo.Context) error {
 	return err
 }
 
+// Healthz converts echo context to params.
+func (w *ServerInterfaceWrapper) Healthz(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.Healthz(ctx)
+	return err
+}
+
+// Readyz converts echo context to params.
+func (w *ServerInterfaceWrapper) Readyz(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.Readyz(ctx)
+	return err
+}
+
 // This is a simple interface which specifies echo.Route addition functions which
 // are present on both echo.Echo and echo.Group, since we want to allow using
 // either of them for path registration
@@ -134,30 +755,99 @@ func RegisterHandlersWithBaseURL(router EchoRouter, si ServerInterface, baseURL
 		Handler: si,
 	}
 
+	router.POST(baseURL+"/api/v1/auth/login", wrapper.Login)
+	router.POST(baseURL+"/api/v1/auth/logout", wrapper.Logout)
+	router.POST(baseURL+"/api/v1/auth/register", wrapper.Register)
 	router.GET(baseURL+"/api/v1/info", wrapper.GetInfo)
+	router.GET(baseURL+"/api/v1/rooms", wrapper.ListRooms)
 	router.POST(baseURL+"/api/v1/rooms", wrapper.CreateRoom)
 	router.DELETE(baseURL+"/api/v1/rooms/:id", wrapper.DeleteRoom)
+	router.POST(baseURL+"/api/v1/rooms/:id/archive", wrapper.ArchiveRoom)
+	router.POST(baseURL+"/api/v1/rooms/:id/restore", wrapper.RestoreRoom)
+	router.GET(baseURL+"/api/v1/search", wrapper.Search)
+	router.GET(baseURL+"/api/v1/status", wrapper.GetStatus)
 	router.GET(baseURL+"/api/v1/ws/:id", wrapper.ConnectRoomWS)
+	router.GET(baseURL+"/healthz", wrapper.Healthz)
+	router.GET(baseURL+"/readyz", wrapper.Readyz)
 
 }
 
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/9SVz24rNRTGX2VkWM7NJHDZzA64gCIkqG501UUVVe7MaeoyY09tp6WqIiUpAiQWFRIL",
-	"ViDUF0jTRoSmnWc4foU+CbInaf7MqGygKqs4sv199s/fOXNGIpFmggPXioRnREUHkFI3/FQC1fBWiNT+",
-	"y6TIQGoGbk4Kke6y2A73hUypJiHpdllMfKJPMyAhUVoy3iG9nk8kHHWZhJiEO48b2z7RTCd25YrP426x",
-	"dwiRJj2ffAG6yfdF+QjHIBUT3A6ftlwsXLFciFb4gZRC7kpQmeAKrHoMKpIs086M4G9miGOcmKFnBh5O",
-	"8d58h7np4x2OzPc4xQn+5WGOVx7m5kec4hXe4oT4G6ePQVOWVMj/uiloLirlnr7zXL5duqBdyOY8FzRa",
-	"pzzaSuip9/FWk/hLsKReq9caForIgNOMkZB8WKvX6vY2VB+4iwQ0Y8FxI1iIdkBX3Op3zHFmzs0POMF7",
-	"C8lzDPtmgFOcemZg/+AYp2aAI+IMJbWbm/Hacy3exVl/UK/bn0hwDdy50ixLWOQ2BoeqCEeRaDt6X8I+",
-	"Ccl7wTLywTzvwcLCAVo//NdfWgQf/YtmGxmr8GxyDZLTxGuBPAbpfWZ32IU9/5G4LSXHIROqivkfmOOf",
-	"eIOjBfFbzPEO73FkhuanEuS1MvzPOK+4/N9QB2cs7hWcE9BVreHS0Z4tM/408TdOZ048o5KmoEEqEu5s",
-	"Kr9713xTVmN2yhYi8QmnqS1l14GXjUDLLvgrpP6pV7dLL/+6fM3ikV4XU8/0SF8J/bno8vhlxuNkmY3q",
-	"9ndpBmboCjHH8UpAtmGvJaJvQD/0fzYDzHGCN/aTUiywnf8GZ+bCc23y2j49ztz8NY48HK9Fwn0VNkpa",
-	"cA6Rtgnbbr3UjDXqjTKx1gnT0QHjHW9LCi0ikSjvof/LEphXxoUTz5xXgM6LuD5naD6h8Vs46oLSLyew",
-	"Pvn21QnsKYfvVQpK0Q4UQZgb1morjjV7rsojKNbhNNmdK5Be2x1CObdCrysTEpLATv0dAAD//zERujtg",
-	"CgAA",
+	"H4sIAAAAAAAC/+xcfW8bR3r/KoNtAdvoUqJsX+9K4/5Ick3j1ncNZBspcBWEJXckbbTcZXaXspTUAClG",
+	"ia8ypDp3QA9FrjnfFei/FGXaG0mkvsLMV8gnKZ5nZt9nScq2fArKfwSR3J2X5+33vM18oTXcZst1qBP4",
+	"Wu0LzW9s0KaB/37gUSOgy67bhE8tz21RL7Ao/uY+cqi3Grib1IGPJvUbntUKLNfRahp7zobshHfYkO8S",
+	"NmCnrM9esCE75U/5V6xP2AkbszM2Yn2+y/fvEHbOxuyE9dkrvs8GrA/v8S4/JHyXjeEteIGwMXvBQjYi",
+	"vAOParoW7LSoVtP8wLOcde2xrnmu21y1TFjRmus1jUCrae22ZRafhYfpZ23Lo6ZW+3X8op7Z2IquBVZg",
+	"w3spWsRjufVPaSOAef+BBnedNbdIpi3q+UiTL6YsIHowNWU0qGI+WMc9yw+KE1oBbWb/+WuPrmk17a8W",
+	"Ez4vSiYvwra1x/EEhucZO/DZodvBaqPt+a6n4O5/8R7v8C4b8w7hXXbKhuwF7/ED/hs2ZN8T3uW7yKIR",
+	"C/lXyN8x8rOHf3fZgPeEZIxAFs7ZOBqEjRQDsOFU7om9pkgX00dBu/vU8Boby9Rv229MPx/HWt2wgiIV",
+	"p60xsw7FOhsbtLGpoP4f2ZC94j1Upl3QIMLOeYeN2YANeYedsDDSL9AqoOgIntP03E6p5ym5+x3vsJB/",
+	"zULBnhEb8h57wfrw1R2y6FHD3PmcsJAsGi1rcWtp0Q+MoO0TNuTP8HFkN75xwHd1wsYRn0P+JNZsNiDs",
+	"lI3ZMYzEBixE7vfh+0XDbFrOomn4G3XX8EyVottGQJ3GzmrTV+zgd+yUhXxXGBw2Avniu/ypgk4Dws5Y",
+	"yE7xhS4arR4b4dr3ND2xIabbrts0WYjTbtaph5piNKlKRfIM0HSNbhvNli324XrGOlVtTBAT+eO0myA4",
+	"7iasxLBsbaXwfE7GcDHxGBkqragkzKMmdQLLsFVU/D07Az6FwLk+Eu4UaDiQ2jmGfQFxQWF7/Gv+jO/K",
+	"j+wV6yO7uywsiF3L8P1HrmcqZvwDyM4JmBU25l+zIfkZmJeQnbEBzA6sSzMlHklBxrZPPTVrbv3Q+e2t",
+	"m9mB+zUQxj7fBaHnXZANWMoR77ETNuD7OuFfsZB/yTvw/7WFazq5tnqNoOSE5Frl2h3ApCE7ZqGgjtQD",
+	"gLKXQKepFixer55sS8WylufWbdpUEO+/wbAKszpmR6CDT1jIjtgJLoTwL5GuZ7jLIVn+8APy059Vf0qu",
+	"G62WbTUMGGZRjv43n/quc6PAuYZrqkT9OVgXdsRCoW18HyQA5nmCJgQ4GeIjQ3YmfgTr9CK9QpCSSNy3",
+	"DNsycTkVEHoK7G07RjvYcD3rc/y45np1yzSpo+ma4waVNbftwPdNGmy4ZgW+MmzbfYQPN1xnzbYaYGI/",
+	"a7uBUaHbDUpN/M0zAlqxraYV4Me6YVaAKRSBo+0YW4ZlG0LxLScADqmUUNdMGoB+qnRolCI8yNBhkT0Z",
+	"0wCITBw3INGmJliI7GQfPXjwcQXxFbyqHu+mx71dvR0PBVtZF9ZLYpEC4TswCnIK7LnUZkRkNiSFqVg/",
+	"PZn2KzcgH5atX3yRn/Lh8l2C+nfO+mnqhJmBjbrbDmp123A2p6oU/hptMWUUUYxVugXIZjnUVxnD3yKm",
+	"AXYkcAKAwTsSTvoCXUKkEGoZfyoNIZqzLt8vKhQg/OzOBj6u8tYSiZjsq3hb1FuFh2mBWAl1xJqU9JGB",
+	"gBLq0JdnfcK7oPxD+B9gFCCC7wPIApIgJp/wnnQUJU6ABR5FaCJoyw+KxEIH3Fw1AsUavkHzC3gFbil7",
+	"JacO+WEGxUHdA6upBN5o/PqOMp6Rg0ZeRY3YbsOwa//arlZvNSxzEgTiMzRCi08fBdFbvi9++jfx2W/X",
+	"k0dfkX/85EGp58x7BDc4luI24vt8LxNWaUozldpUEUBySp4N0lTj0e2W5VFfzZJv8W30fYiARTYEUBds",
+	"yY2ug/fYRfog5ZB9M7OuRannl0jmKfqyQ0E9CCLPMYw8Yaf8ADgmBJTvIQNZH5+D/Z9Ib6NoM2ePMXUt",
+	"MNZVC/uT8BWKJI7tQInVTHS+zHL/AUJjdDxm5eKW5Vt1y7YCleB/g445iFjsROcHjIC71a7bVgP11toC",
+	"G7Mye9AdGen07JmVSVLqaTMQMb7MVq22DM9QBgjfFgzUMG04BsJZEYqc327WKr2hSjWN7XvUWQ82tNrN",
+	"arX6BjqmpwJpnAfgCt30YzbOTMz6wrN9IZxFULcTcGzFLyHBn/owDjw/sxK+tqinaHDrpmLgprF9Vzy7",
+	"VH2LepAlvWpDgT0RadhLMNMwfG5wdLdzweQdsNciYgdvYSSDjQHrkxSHda1pOVYT9GlJZXryqrpmYA4j",
+	"Ub3L1t6CnqUSILNlKiTaj6Ur0JVelRRT+DnEAKw/xQGYTSg3LcdUCmXIzjH9xL4HPZCB7LFIMh7xf8e1",
+	"iaRJRCBPpP6a1PeNdRV54t9WLdWcvxcTAdv5lwgwfaGiwvLAtL+J0RGhCPTxOnvBTvkhgY38XI5/I62T",
+	"lhP87W01ToGTrGYK6DaqhvBXhGykR12zXSNQpTsuAn4+dUzqlYSq55jgGyR5GhUZynZfnMqxWi2qksH/",
+	"wXmOY1ckJXforH704Jf3KsIp4F0RQKOOfC/WM4B4RIpICHAwIMJZaxreJv6nNoavZZWy+wVS35ga5cCj",
+	"mp6C0ogUUgAyiLmi1OBUWFBYsbtJfuj8LhPqENATobY6gRAdnxAJDxHrPIFkikjXY0oPvNlM7m3MTnRi",
+	"eoblWM66YoKx4A+8jdWDMF0U0HRFakzXouGUmpnapCp0Zt8h/2FhR2gJhDsgrBM/lMzKxnuXFs2VebPf",
+	"qRxXNiw6rmGp2+pPDt/4PoZvSg8ZQ7jCVH12VjLd68SkutZugSVf9WnDdUx/cqDXwazTGJctBe8rdPS7",
+	"Io2syOcqEHXW+kwcIEdvFBYbx84RrSf5pz71fbXP+FzuAYOklEs34HvIl/4dgsUp+HGI1IDdPYvS6sCT",
+	"E/I+NTzqRSGnyEzI90MsyZGG625atFgUyLibM3p+JUXAP4Geg+UUmoObghLCW6v2QdJ0moThM4XUEK44",
+	"41zL0VS8iqYpiGI2Ed7nh+ksAHxMsgD8aYHUr+PPzIi86ez3lMqZqenp5PNEsICXLVnmjKtYO07jY9vY",
+	"Ie99fDelHTWtulBdWILFuC3qGC0LUu8L1YUqZriDDSRBVEGC7O6i7a5bKEYt11fnePZE3vgcpCMUiC7y",
+	"SqkKxUENVGU/UoqU6PEDeFQiCzoeUgmIv+M0WraxsxppJS7awyT0XVOrafdwaYJy1A/ed80dkRB3Aurg",
+	"WtNZdMieJ5X0qTiQKsI8zrIn8NoUv/BbruMLqblZrb61qaPt4rRZav/zPwHrbk+cLF0tmH1S+ZZq0vcN",
+	"c1lQWEy+9C4nf5iuMeD0t9/l9L9yA5Exh6lv/t27nPqB65JfGs4OkcQH5NqghindkGUaeDuV99YCpRX8",
+	"XwlCrwiGbYn9TsMvwWTKS3CthPUfyOxEWEiTa3pqQ3m8xsX/5N0K5V1Z+CH30Vchf49lc1zHrXe5Dpje",
+	"alDyMFWWEgTJW1G3HUwwo/+J1m/IO/yJMKBsGAVFvbiBI4HrmkBlgePoyb8n1QT3GbkYsTtRMJywmoIN",
+	"u11cWGRx/mJaN5erSXLl0XXLlyagRLL+mK6AJ+XOizcMZGVoOZr5iuDv0lubWvqmBZaIXjPzCiDwXw4C",
+	"q+8UAj+ImgTm6DtH39ezklFgtE6DkuTNKaZPIsyVIIwgm6SWQtlLkTWBSSfopcUC0RSlscDVYHyG5HFK",
+	"S03z5xJSxuwkk2iFJBem4LEugekF2Z+wSXd8GlQw53ssGiABxcApKro2lh8sR3keKDTSAFX21wpchKTv",
+	"GaY2872x2Fmk1bTP2tSDKqeI2jXsScooY1zsuVnF2pWsElWrk2tGj/U37d4VTl+6IVi95PjHggFJUg/F",
+	"7HzCBRnXD7Ilq0JxTaSPoEh5hir1tVzpQS57dM577EhW/zFPukBEZytknPiu+K5YuMPZR3w/N1quhR3a",
+	"LwbIUWxrAIFqWg79OTgMJeTJ1LITEl2o/KbMsMVrzDVUZBph5G6TdCJ2NvbR9KdqX2dJPZn1SzYSZ4jW",
+	"hE+W7GWWFNYsMgAkZce8V7KAwFi/oJSVk+m18t3kOvD5RpxdPUIARincI9fXDNunN0rWvmH4q0YjsLbo",
+	"qsgNKzZSd12bGs7bYHgqrgvZmay+8qfsFRtLNRC1wLM7BJV+iI0B2BbQh56jQsESjGFYsjfQgIvu5zlY",
+	"IZxBFIdOIL2NzshQzMm70F5LcGcj7EHvywYodiSzxwinJWvyXa/EjGqVTCdJpIeZL7OPRD0qFfGPQkFX",
+	"LhGf4xMN82SdMln3Tr3BD+MG5LlHnPOI9bLswPN0X6aqISfrXmXOW11G3J/uEHssA/9L0t3UXubae8W0",
+	"d55omCcaflyJBox6F7+wzMeCTzZVNe6wP6e6OIv2FqIhdRiVaxWNukH5YXJ074wfsmPkPgvlsb8U4wcY",
+	"uUUNPGNRauVP75BW21sXMVJuVDnGGKceyd63+PBeIdSS3eQoWCJkFW8U8ya/QOJIEJkYpD98ePcXih5V",
+	"B4+ZBRuJR2mZEo+SFLEyBio7dVzKJ6kjJUTQ4zBjgDga0zsKkotUP43a6Ep8Y2SH2jnGGEafzYG/2EFv",
+	"7BIb8Z44FpeKWeRmD4oHAwbYUQZJiifY+tPD5p64gACpi3yjBW5X2LZkv/9SAUGoPJC9H+UB5MpFylX/",
+	"j4FzXqm7utCwCG3Z1hadUK77D97he5gPi6JvtWteK8uQqFoPxdlv+aQ4AQ6YoWeMdrrBGROReyLhmGBM",
+	"wZK/J7ZztU353BjOjeHcGF5BY+hRP3C9ScbwmzL3CVS0r7SUsnVBJIFjh/ZZ8kvhUJBIbAqbAB3JwtsT",
+	"t15MdN/yDRG4m7ktnNvCuS2c28JZbKE4pjelPaF4LE9c1xEfysN/i6XVs1zOgJ1Jq6gTeZ6paAB0OVY6",
+	"lA8Tr3BKclhcnTS18P5dtPBkL6kUFLmOzRdY64Qtw6PkEa0LWpVV8D6baCfzh0qblhN9XprBairMdUzC",
+	"1NHIibQrWXfqgNgbWPVvI1LBFRdRbiKKGPJH7Xknf/5Tnql/k46Hn1y84eHCUBSjUD7zJImYv60gEqj4",
+	"8qsifB2WoPBbxKK3V6/I3Ew2r1jM8XWOr9PwNT7tWI6vFzxfWpvpWr8xHnSMLk2BB1/GbSoCFEK0VGOC",
+	"llvcOVC8LYaoml2EsS50It6PTkJe4rmk9GndH1NH4qOkMKMWhD+XxJif0Pp9t7FJgx86zxA8huL0Y3Jf",
+	"jnADEHuPgWkAP2zEjsVh1zQz2bDAtg9cx6EN7Ff85P5VCRrzELYkbHpO9x5ZQWMDjol/7LmB23BtHw+M",
+	"xwQjRXKJzGSR0OM5bM0L7fNC+7zQXg7qurZdgRgQTUtFXjkijKScZ2EhNdECLEeNYda6Y9ircgTt8QrC",
+	"xAY17GDj81J8uGdtUYf6fkVi/1HiB0SXG4h7hwZYeofbUsQ1CGilo8P0xXsHs2jwkVzFVAgP6Haw2LIN",
+	"K0fy5EJGV3UPoxKv8Wt5e3Dp/pejKxgzBIjqQyKrAH3qAIfi2poeOxf1cd7le3hrV1+6OrwXh6h9PXfB",
+	"iAzUJt8yks+64sov0e1J7p9UkJA9T68/voFluuJc2hqQhqmFCA4L301oTNuztZq2CML/fwMAvcpb4mZe",
+	"AAA=",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
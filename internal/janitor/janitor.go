@@ -0,0 +1,212 @@
+package janitor
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/vpbuyanov/syncplay/internal/config"
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+const (
+	ActionDelete  = "delete"
+	ActionArchive = "archive"
+)
+
+const (
+	defaultInterval  = time.Minute
+	defaultBatchSize = 100
+)
+
+//go:generate mockgen -source=janitor.go -destination janitor_mock.go -package janitor JANITOR
+type roomStore interface {
+	ExpiringRooms(ctx context.Context, before time.Time, limit int) ([]model.RoomInfo, error)
+	IdleRooms(ctx context.Context, before time.Time, exclude []string, limit int) ([]string, error)
+	DeletedRooms(ctx context.Context, before time.Time, limit int) ([]string, error)
+	RetireRooms(ctx context.Context, ids []string, archive bool) (int64, error)
+}
+
+type sessions interface {
+	ActiveRoomIDs() []string
+	WarnRoom(roomID string, closesAt time.Time)
+	CloseRoom(roomID string, reason string)
+}
+
+// Janitor периодически удаляет или архивирует истёкшие и простаивающие комнаты.
+// Участников комнаты с истекающим expires_at заранее предупреждают,
+// а в момент истечения принудительно отключают. Простаивающие комнаты
+// с подключёнными участниками не трогаются. Удалённые комнаты по истечении
+// окна восстановления удаляются окончательно.
+type Janitor struct {
+	cfg      config.Janitor
+	rooms    roomStore
+	sessions sessions
+	now      func() time.Time
+
+	// warned — комнаты, участники которых уже получили предупреждение
+	warned map[string]time.Time
+}
+
+func New(cfg config.Janitor, rooms roomStore, sessions sessions) (*Janitor, error) {
+	switch cfg.Action {
+	case "":
+		cfg.Action = ActionArchive
+	case ActionDelete, ActionArchive:
+	default:
+		return nil, errors.Errorf("unknown janitor action %q", cfg.Action)
+	}
+
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultInterval
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	if cfg.RestoreWindow <= 0 {
+		cfg.RestoreWindow = model.DefaultRestoreWindow
+	}
+
+	return &Janitor{
+		cfg:      cfg,
+		rooms:    rooms,
+		sessions: sessions,
+		now:      time.Now,
+		warned:   make(map[string]time.Time),
+	}, nil
+}
+
+// Run выполняет очистку каждые cfg.Interval до отмены ctx.
+func (j *Janitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := j.Sweep(ctx); err != nil {
+			slog.Error("janitor sweep failed", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep выполняет один проход очистки.
+func (j *Janitor) Sweep(ctx context.Context) error {
+	now := j.now().UTC()
+
+	active := make(map[string]struct{})
+	for _, id := range j.sessions.ActiveRoomIDs() {
+		active[id] = struct{}{}
+	}
+
+	expired, err := j.sweepExpiring(ctx, now, active)
+	if err != nil {
+		return err
+	}
+
+	idle, err := j.sweepIdle(ctx, now, active)
+	if err != nil {
+		return err
+	}
+
+	purged, err := j.sweepDeleted(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	if expired > 0 || idle > 0 || purged > 0 {
+		slog.Info("janitor sweep", "action", j.cfg.Action, "expired", expired, "idle", idle, "purged", purged)
+	}
+
+	return nil
+}
+
+func (j *Janitor) sweepExpiring(ctx context.Context, now time.Time, active map[string]struct{}) (int64, error) {
+	rooms, err := j.rooms.ExpiringRooms(ctx, now.Add(j.cfg.WarnBefore), j.cfg.BatchSize)
+	if err != nil {
+		return 0, errors.Wrap(err, "expiring rooms")
+	}
+
+	var expired []string
+	for _, r := range rooms {
+		if r.ExpiresAt == nil {
+			continue
+		}
+
+		if r.ExpiresAt.After(now) {
+			_, live := active[r.ID]
+			if warnedAt, ok := j.warned[r.ID]; live && (!ok || !warnedAt.Equal(*r.ExpiresAt)) {
+				j.sessions.WarnRoom(r.ID, *r.ExpiresAt)
+				j.warned[r.ID] = *r.ExpiresAt
+			}
+
+			continue
+		}
+
+		expired = append(expired, r.ID)
+	}
+
+	// Сначала убираем комнату из БД, чтобы к ней нельзя было переподключиться,
+	// и только потом отключаем участников
+	n, err := j.rooms.RetireRooms(ctx, expired, j.cfg.Action == ActionArchive)
+	if err != nil {
+		return 0, errors.Wrap(err, "retire expired rooms")
+	}
+
+	for _, id := range expired {
+		if _, live := active[id]; live {
+			j.sessions.CloseRoom(id, "expired")
+		}
+	}
+
+	for id, at := range j.warned {
+		if !at.After(now) {
+			delete(j.warned, id)
+		}
+	}
+
+	return n, nil
+}
+
+func (j *Janitor) sweepIdle(ctx context.Context, now time.Time, active map[string]struct{}) (int64, error) {
+	if j.cfg.IdleTTL <= 0 {
+		return 0, nil
+	}
+
+	exclude := make([]string, 0, len(active))
+	for id := range active {
+		exclude = append(exclude, id)
+	}
+
+	idle, err := j.rooms.IdleRooms(ctx, now.Add(-j.cfg.IdleTTL), exclude, j.cfg.BatchSize)
+	if err != nil {
+		return 0, errors.Wrap(err, "idle rooms")
+	}
+
+	n, err := j.rooms.RetireRooms(ctx, idle, j.cfg.Action == ActionArchive)
+	if err != nil {
+		return 0, errors.Wrap(err, "retire idle rooms")
+	}
+
+	return n, nil
+}
+
+func (j *Janitor) sweepDeleted(ctx context.Context, now time.Time) (int64, error) {
+	deleted, err := j.rooms.DeletedRooms(ctx, now.Add(-j.cfg.RestoreWindow), j.cfg.BatchSize)
+	if err != nil {
+		return 0, errors.Wrap(err, "deleted rooms")
+	}
+
+	n, err := j.rooms.RetireRooms(ctx, deleted, false)
+	if err != nil {
+		return 0, errors.Wrap(err, "purge deleted rooms")
+	}
+
+	return n, nil
+}
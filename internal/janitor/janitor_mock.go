@@ -0,0 +1,162 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: janitor.go
+//
+// Generated by this command:
+//
+//	mockgen -source=janitor.go -destination janitor_mock.go -package janitor JANITOR
+//
+// Package janitor is a generated GoMock package.
+package janitor
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	model "github.com/vpbuyanov/syncplay/internal/model"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockroomStore is a mock of roomStore interface.
+type MockroomStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockroomStoreMockRecorder
+}
+
+// MockroomStoreMockRecorder is the mock recorder for MockroomStore.
+type MockroomStoreMockRecorder struct {
+	mock *MockroomStore
+}
+
+// NewMockroomStore creates a new mock instance.
+func NewMockroomStore(ctrl *gomock.Controller) *MockroomStore {
+	mock := &MockroomStore{ctrl: ctrl}
+	mock.recorder = &MockroomStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockroomStore) EXPECT() *MockroomStoreMockRecorder {
+	return m.recorder
+}
+
+// DeletedRooms mocks base method.
+func (m *MockroomStore) DeletedRooms(ctx context.Context, before time.Time, limit int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletedRooms", ctx, before, limit)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeletedRooms indicates an expected call of DeletedRooms.
+func (mr *MockroomStoreMockRecorder) DeletedRooms(ctx, before, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletedRooms", reflect.TypeOf((*MockroomStore)(nil).DeletedRooms), ctx, before, limit)
+}
+
+// ExpiringRooms mocks base method.
+func (m *MockroomStore) ExpiringRooms(ctx context.Context, before time.Time, limit int) ([]model.RoomInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpiringRooms", ctx, before, limit)
+	ret0, _ := ret[0].([]model.RoomInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpiringRooms indicates an expected call of ExpiringRooms.
+func (mr *MockroomStoreMockRecorder) ExpiringRooms(ctx, before, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpiringRooms", reflect.TypeOf((*MockroomStore)(nil).ExpiringRooms), ctx, before, limit)
+}
+
+// IdleRooms mocks base method.
+func (m *MockroomStore) IdleRooms(ctx context.Context, before time.Time, exclude []string, limit int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IdleRooms", ctx, before, exclude, limit)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IdleRooms indicates an expected call of IdleRooms.
+func (mr *MockroomStoreMockRecorder) IdleRooms(ctx, before, exclude, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IdleRooms", reflect.TypeOf((*MockroomStore)(nil).IdleRooms), ctx, before, exclude, limit)
+}
+
+// RetireRooms mocks base method.
+func (m *MockroomStore) RetireRooms(ctx context.Context, ids []string, archive bool) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetireRooms", ctx, ids, archive)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RetireRooms indicates an expected call of RetireRooms.
+func (mr *MockroomStoreMockRecorder) RetireRooms(ctx, ids, archive any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetireRooms", reflect.TypeOf((*MockroomStore)(nil).RetireRooms), ctx, ids, archive)
+}
+
+// Mocksessions is a mock of sessions interface.
+type Mocksessions struct {
+	ctrl     *gomock.Controller
+	recorder *MocksessionsMockRecorder
+}
+
+// MocksessionsMockRecorder is the mock recorder for Mocksessions.
+type MocksessionsMockRecorder struct {
+	mock *Mocksessions
+}
+
+// NewMocksessions creates a new mock instance.
+func NewMocksessions(ctrl *gomock.Controller) *Mocksessions {
+	mock := &Mocksessions{ctrl: ctrl}
+	mock.recorder = &MocksessionsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *Mocksessions) EXPECT() *MocksessionsMockRecorder {
+	return m.recorder
+}
+
+// ActiveRoomIDs mocks base method.
+func (m *Mocksessions) ActiveRoomIDs() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ActiveRoomIDs")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// ActiveRoomIDs indicates an expected call of ActiveRoomIDs.
+func (mr *MocksessionsMockRecorder) ActiveRoomIDs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ActiveRoomIDs", reflect.TypeOf((*Mocksessions)(nil).ActiveRoomIDs))
+}
+
+// CloseRoom mocks base method.
+func (m *Mocksessions) CloseRoom(roomID, reason string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CloseRoom", roomID, reason)
+}
+
+// CloseRoom indicates an expected call of CloseRoom.
+func (mr *MocksessionsMockRecorder) CloseRoom(roomID, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseRoom", reflect.TypeOf((*Mocksessions)(nil).CloseRoom), roomID, reason)
+}
+
+// WarnRoom mocks base method.
+func (m *Mocksessions) WarnRoom(roomID string, closesAt time.Time) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "WarnRoom", roomID, closesAt)
+}
+
+// WarnRoom indicates an expected call of WarnRoom.
+func (mr *MocksessionsMockRecorder) WarnRoom(roomID, closesAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WarnRoom", reflect.TypeOf((*Mocksessions)(nil).WarnRoom), roomID, closesAt)
+}
@@ -0,0 +1,143 @@
+package janitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/vpbuyanov/syncplay/internal/config"
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+func newTestJanitor(t *testing.T, cfg config.Janitor) (*Janitor, *MockroomStore, *Mocksessions, time.Time) {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+	rooms := NewMockroomStore(ctrl)
+	sessions := NewMocksessions(ctrl)
+
+	j, err := New(cfg, rooms, sessions)
+	require.NoError(t, err)
+
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	j.now = func() time.Time { return now }
+
+	return j, rooms, sessions, now
+}
+
+func TestNew(t *testing.T) {
+	j, err := New(config.Janitor{}, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, ActionArchive, j.cfg.Action)
+	assert.Equal(t, defaultInterval, j.cfg.Interval)
+	assert.Equal(t, defaultBatchSize, j.cfg.BatchSize)
+	assert.Equal(t, model.DefaultRestoreWindow, j.cfg.RestoreWindow)
+
+	_, err = New(config.Janitor{Action: "explode"}, nil, nil)
+	require.Error(t, err)
+}
+
+func TestJanitor_Sweep(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.Janitor{
+		IdleTTL:    time.Hour,
+		WarnBefore: 5 * time.Minute,
+		Action:     ActionDelete,
+		BatchSize:  10,
+	}
+
+	t.Run("expired rooms are retired and live peers disconnected", func(t *testing.T) {
+		j, rooms, sessions, now := newTestJanitor(t, cfg)
+
+		past := now.Add(-time.Second)
+		soon := now.Add(time.Minute)
+
+		sessions.EXPECT().ActiveRoomIDs().Return([]string{"live-expired", "live-soon"})
+		rooms.EXPECT().
+			ExpiringRooms(ctx, now.Add(cfg.WarnBefore), cfg.BatchSize).
+			Return([]model.RoomInfo{
+				{ID: "live-expired", RoomMeta: model.RoomMeta{ExpiresAt: &past}},
+				{ID: "empty-expired", RoomMeta: model.RoomMeta{ExpiresAt: &past}},
+				{ID: "live-soon", RoomMeta: model.RoomMeta{ExpiresAt: &soon}},
+				{ID: "empty-soon", RoomMeta: model.RoomMeta{ExpiresAt: &soon}},
+			}, nil)
+		sessions.EXPECT().WarnRoom("live-soon", soon)
+		rooms.EXPECT().
+			RetireRooms(ctx, []string{"live-expired", "empty-expired"}, false).
+			Return(int64(2), nil)
+		sessions.EXPECT().CloseRoom("live-expired", "expired")
+		rooms.EXPECT().
+			IdleRooms(ctx, now.Add(-cfg.IdleTTL), gomock.InAnyOrder([]string{"live-expired", "live-soon"}), cfg.BatchSize).
+			Return([]string{"idle"}, nil)
+		rooms.EXPECT().
+			RetireRooms(ctx, []string{"idle"}, false).
+			Return(int64(1), nil)
+		rooms.EXPECT().
+			DeletedRooms(ctx, now.Add(-model.DefaultRestoreWindow), cfg.BatchSize).
+			Return([]string{"deleted"}, nil)
+		rooms.EXPECT().
+			RetireRooms(ctx, []string{"deleted"}, false).
+			Return(int64(1), nil)
+
+		require.NoError(t, j.Sweep(ctx))
+	})
+
+	t.Run("warning is sent once", func(t *testing.T) {
+		j, rooms, sessions, now := newTestJanitor(t, config.Janitor{WarnBefore: time.Minute, Action: ActionArchive})
+
+		soon := now.Add(30 * time.Second)
+
+		sessions.EXPECT().ActiveRoomIDs().Return([]string{"room"}).Times(2)
+		rooms.EXPECT().
+			ExpiringRooms(ctx, gomock.Any(), gomock.Any()).
+			Return([]model.RoomInfo{{ID: "room", RoomMeta: model.RoomMeta{ExpiresAt: &soon}}}, nil).
+			Times(2)
+		rooms.EXPECT().RetireRooms(ctx, nil, true).Return(int64(0), nil).Times(2)
+		rooms.EXPECT().DeletedRooms(ctx, gomock.Any(), gomock.Any()).Return(nil, nil).Times(2)
+		rooms.EXPECT().RetireRooms(ctx, nil, false).Return(int64(0), nil).Times(2)
+		sessions.EXPECT().WarnRoom("room", soon).Times(1)
+
+		require.NoError(t, j.Sweep(ctx))
+		require.NoError(t, j.Sweep(ctx))
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		j, rooms, sessions, _ := newTestJanitor(t, cfg)
+
+		sessions.EXPECT().ActiveRoomIDs().Return(nil)
+		rooms.EXPECT().
+			ExpiringRooms(ctx, gomock.Any(), gomock.Any()).
+			Return(nil, assert.AnError)
+
+		require.ErrorIs(t, j.Sweep(ctx), assert.AnError)
+	})
+}
+
+func TestJanitor_Run_StopsOnCancel(t *testing.T) {
+	j, rooms, sessions, _ := newTestJanitor(t, config.Janitor{Interval: time.Hour})
+
+	sessions.EXPECT().ActiveRoomIDs().Return(nil)
+	rooms.EXPECT().ExpiringRooms(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+	rooms.EXPECT().DeletedRooms(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+	rooms.EXPECT().RetireRooms(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(0), nil).Times(2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		j.Run(ctx)
+		close(done)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("janitor did not stop")
+	}
+}
@@ -0,0 +1,89 @@
+// Package logging — общий slog-логгер syncplay: формат и уровень из конфига
+// и атрибуты корреляции (request_id, room_id, peer_id, trace_id) из контекста.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/vpbuyanov/syncplay/internal/config"
+)
+
+// Option дополняет обработчик записей логгера.
+type Option func(slog.Handler) slog.Handler
+
+// New создаёт логгер в формате cfg.Format. level задаётся отдельно,
+// чтобы его можно было менять на лету через slog.LevelVar.
+func New(w io.Writer, cfg config.Log, level slog.Leveler, options ...Option) (*slog.Logger, error) {
+	opts := &slog.HandlerOptions{Level: level}
+
+	var h slog.Handler
+	switch cfg.Format {
+	case "", config.LogText:
+		h = slog.NewTextHandler(w, opts)
+	case config.LogJSON:
+		h = slog.NewJSONHandler(w, opts)
+	default:
+		return nil, errors.Errorf("unknown log format %q", cfg.Format)
+	}
+
+	for _, opt := range options {
+		h = opt(h)
+	}
+
+	return slog.New(contextHandler{h}), nil
+}
+
+type ctxKey struct{}
+
+// With добавляет атрибуты ко всем записям, сделанным с этим контекстом
+// через slog.*Context.
+func With(ctx context.Context, args ...any) context.Context {
+	prev, _ := ctx.Value(ctxKey{}).([]slog.Attr)
+	attrs := append(prev[:len(prev):len(prev)], argsToAttrs(args)...)
+
+	return context.WithValue(ctx, ctxKey{}, attrs)
+}
+
+func argsToAttrs(args []any) []slog.Attr {
+	var r slog.Record
+	r.Add(args...)
+
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+
+	return attrs
+}
+
+// contextHandler дописывает в запись атрибуты из контекста и trace_id
+// текущего span, чтобы логи можно было сопоставить с трейсами.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if attrs, ok := ctx.Value(ctxKey{}).([]slog.Attr); ok {
+		r.AddAttrs(attrs...)
+	}
+
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()))
+	}
+
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
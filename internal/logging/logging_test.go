@@ -0,0 +1,82 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/vpbuyanov/syncplay/internal/config"
+)
+
+func TestNew_JSON(t *testing.T) {
+	var out bytes.Buffer
+
+	l, err := New(&out, config.Log{Format: config.LogJSON}, slog.LevelInfo)
+	require.NoError(t, err)
+
+	ctx := With(context.Background(), "request_id", "r1")
+	ctx = With(ctx, "room_id", "room", "peer_id", "p1")
+	ctx, span := sdktrace.NewTracerProvider().Tracer("test").Start(ctx, "op")
+	defer span.End()
+
+	l.DebugContext(ctx, "hidden")
+	l.With("component", "ws").InfoContext(ctx, "hello")
+
+	var rec map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &rec), "должна быть одна JSON-запись")
+	assert.Equal(t, "hello", rec["msg"])
+	assert.Equal(t, "ws", rec["component"])
+	assert.Equal(t, "r1", rec["request_id"])
+	assert.Equal(t, "room", rec["room_id"])
+	assert.Equal(t, "p1", rec["peer_id"])
+	assert.Equal(t, span.SpanContext().TraceID().String(), rec["trace_id"])
+}
+
+func TestWith_DoesNotShareAttrs(t *testing.T) {
+	var out bytes.Buffer
+
+	l, err := New(&out, config.Log{}, slog.LevelInfo)
+	require.NoError(t, err)
+
+	// Соседние контексты от одного родителя не видят атрибуты друг друга
+	base := With(context.Background(), "request_id", "r1")
+	a := With(base, "peer_id", "a")
+	_ = With(base, "peer_id", "b")
+
+	l.InfoContext(a, "hello")
+	assert.Contains(t, out.String(), "request_id=r1 peer_id=a")
+	assert.NotContains(t, out.String(), "peer_id=b")
+}
+
+func TestNew_UnknownFormat(t *testing.T) {
+	_, err := New(&bytes.Buffer{}, config.Log{Format: "xml"}, slog.LevelInfo)
+	require.ErrorContains(t, err, `unknown log format "xml"`)
+}
+
+func TestRecentErrors(t *testing.T) {
+	errs := NewRecentErrors(2)
+
+	l, err := New(&bytes.Buffer{}, config.Log{}, slog.LevelInfo, WithRecentErrors(errs))
+	require.NoError(t, err)
+
+	ctx := With(context.Background(), "room_id", "room")
+	l.InfoContext(ctx, "not an error")
+	l.ErrorContext(ctx, "first")
+	l.With("component", "ws").ErrorContext(ctx, "second", "err", "boom")
+	assert.Len(t, errs.List(), 2)
+
+	// Старые записи вытесняются новыми
+	l.Error("third")
+
+	got := errs.List()
+	require.Len(t, got, 2)
+	assert.Equal(t, "third", got[0].Message)
+	assert.Equal(t, "second", got[1].Message)
+	assert.Equal(t, "component=ws err=boom room_id=room", got[1].Attrs)
+}
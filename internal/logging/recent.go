@@ -0,0 +1,103 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrorEntry — запись лога уровня Error.
+type ErrorEntry struct {
+	Time    time.Time
+	Message string
+	// Attrs — атрибуты записи в виде key=value через пробел
+	Attrs string
+}
+
+// RecentErrors хранит последние записи уровня Error, чтобы показать их
+// в админке без доступа к логам.
+type RecentErrors struct {
+	mu      sync.Mutex
+	entries []ErrorEntry
+	next    int
+	full    bool
+}
+
+// NewRecentErrors создаёт буфер на size записей.
+func NewRecentErrors(size int) *RecentErrors {
+	return &RecentErrors{entries: make([]ErrorEntry, max(size, 1))}
+}
+
+// List возвращает записи от новых к старым.
+func (r *RecentErrors) List() []ErrorEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := r.next
+	if r.full {
+		n = len(r.entries)
+	}
+
+	res := make([]ErrorEntry, 0, n)
+	for i := 1; i <= n; i++ {
+		res = append(res, r.entries[(r.next-i+len(r.entries))%len(r.entries)])
+	}
+
+	return res
+}
+
+func (r *RecentErrors) add(e ErrorEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries[r.next] = e
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// WithRecentErrors копирует записи уровня Error в r. Атрибуты из контекста
+// (request_id, room_id, …) попадают в копию.
+func WithRecentErrors(r *RecentErrors) Option {
+	return func(h slog.Handler) slog.Handler {
+		return recentHandler{Handler: h, errs: r}
+	}
+}
+
+type recentHandler struct {
+	slog.Handler
+	errs  *RecentErrors
+	attrs []slog.Attr
+}
+
+func (h recentHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= slog.LevelError {
+		parts := make([]string, 0, len(h.attrs)+r.NumAttrs())
+		for _, a := range h.attrs {
+			parts = append(parts, a.String())
+		}
+		r.Attrs(func(a slog.Attr) bool {
+			parts = append(parts, a.String())
+			return true
+		})
+
+		h.errs.add(ErrorEntry{Time: r.Time, Message: r.Message, Attrs: strings.Join(parts, " ")})
+	}
+
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h recentHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return recentHandler{
+		Handler: h.Handler.WithAttrs(attrs),
+		errs:    h.errs,
+		attrs:   append(h.attrs[:len(h.attrs):len(h.attrs)], attrs...),
+	}
+}
+
+func (h recentHandler) WithGroup(name string) slog.Handler {
+	return recentHandler{Handler: h.Handler.WithGroup(name), errs: h.errs, attrs: h.attrs}
+}
@@ -0,0 +1,33 @@
+// Package metrics — реестр метрик Prometheus и обработчик /metrics.
+// Пакеты регистрируют свои метрики в Registry сами.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace — общий префикс имён метрик.
+const Namespace = "syncplay"
+
+// Registry — реестр метрик сервера; кроме своих метрик в нём
+// метрики рантайма Go и процесса.
+var Registry = newRegistry()
+
+func newRegistry() *prometheus.Registry {
+	r := prometheus.NewRegistry()
+	r.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return r
+}
+
+// Handler отдаёт метрики из Registry в формате Prometheus.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
@@ -0,0 +1,285 @@
+// Package migrator применяет встроенные в бинарник миграции схемы
+// к выбранному в конфиге хранилищу.
+package migrator
+
+import (
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/pkg/errors"
+
+	"github.com/vpbuyanov/syncplay/internal/config"
+	"github.com/vpbuyanov/syncplay/migrations"
+)
+
+var (
+	// ErrNoMigrations — у хранилища нет схемы, мигрировать нечего.
+	ErrNoMigrations = errors.New("storage has no migrations")
+	// ErrSchemaOutdated — схема базы старше, чем ожидает бинарник.
+	ErrSchemaOutdated = errors.New("database schema is outdated")
+)
+
+// lockTimeout — сколько ждать блокировку миграций. Драйвер Postgres берёт
+// pg_advisory_lock, поэтому реплики, запущенные одновременно, ждут, пока
+// первая закончит миграции, а не применяют их параллельно.
+const lockTimeout = 5 * time.Minute
+
+// Migration — миграция из встроенного набора.
+type Migration struct {
+	Version uint
+	Name    string
+	Applied bool
+}
+
+// Status — состояние схемы: текущая версия и все известные миграции.
+// Pending — миграции, которые применит Up.
+type Status struct {
+	Version    uint
+	Dirty      bool
+	Migrations []Migration
+	Pending    []Migration
+}
+
+type Migrator struct {
+	m   *migrate.Migrate
+	src source.Driver
+}
+
+// New открывает миграции для хранилища из конфига.
+// Для хранилища в памяти возвращает ErrNoMigrations.
+func New(storage config.Storage, pg config.Postgres) (*Migrator, error) {
+	var (
+		fsys        fs.FS
+		dir         string
+		databaseURL string
+	)
+
+	switch storage.Driver {
+	case "", config.StoragePostgres:
+		fsys, dir, databaseURL = migrations.Postgres, ".", pg.String()
+	case config.StorageSQLite:
+		fsys, dir, databaseURL = migrations.SQLite, "sqlite", storage.SQLite.URL()
+	case config.StorageMemory:
+		return nil, ErrNoMigrations
+	default:
+		return nil, errors.Errorf("unknown storage driver %q", storage.Driver)
+	}
+
+	src, err := iofs.New(fsys, dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "open embedded migrations")
+	}
+
+	// golang-migrate закрывает свой источник в Close, поэтому для списка
+	// миграций открываем отдельный экземпляр
+	list, err := iofs.New(fsys, dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "open embedded migrations")
+	}
+
+	m, err := migrate.NewWithSourceInstance("iofs", src, databaseURL)
+	if err != nil {
+		_ = list.Close()
+		return nil, errors.Wrap(err, "connect to database")
+	}
+	m.Log = logger{}
+	m.LockTimeout = lockTimeout
+
+	return &Migrator{m: m, src: list}, nil
+}
+
+// Up применяет все неприменённые миграции. Отсутствие изменений ошибкой не считается.
+func (m *Migrator) Up() error {
+	return noChange(m.m.Up())
+}
+
+// Down откатывает n последних миграций.
+func (m *Migrator) Down(n int) error {
+	if n <= 0 {
+		return errors.Errorf("number of migrations to roll back must be positive, got %d", n)
+	}
+
+	return noChange(m.m.Steps(-n))
+}
+
+// Goto переводит схему на версию v вверх или вниз.
+func (m *Migrator) Goto(v uint) error {
+	return noChange(m.m.Migrate(v))
+}
+
+// Force записывает версию v без выполнения миграций и снимает признак dirty.
+// v = -1 означает "ни одна миграция не применена".
+func (m *Migrator) Force(v int) error {
+	return errors.Wrap(m.m.Force(v), "force version")
+}
+
+// Check проверяет, что схема чистая и не старше последней встроенной миграции.
+// Схема новее бинарника допустима: миграции обратно совместимы.
+func (m *Migrator) Check() error {
+	v, dirty, err := m.Version()
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return dirtyErr(v)
+	}
+
+	latest, err := m.Latest()
+	if err != nil {
+		return err
+	}
+
+	if v < latest {
+		return errors.Wrapf(ErrSchemaOutdated, "schema version is %d, but the binary requires %d: "+
+			"run \"migrator up\" or enable storage.auto_migrate", v, latest)
+	}
+
+	return nil
+}
+
+// Version возвращает текущую версию схемы; 0 — ни одна миграция не применена.
+func (m *Migrator) Version() (uint, bool, error) {
+	v, dirty, err := m.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, errors.Wrap(err, "read schema version")
+	}
+
+	return v, dirty, nil
+}
+
+// Latest возвращает версию последней встроенной миграции.
+func (m *Migrator) Latest() (uint, error) {
+	list, err := m.list()
+	if err != nil {
+		return 0, err
+	}
+	if len(list) == 0 {
+		return 0, nil
+	}
+
+	return list[len(list)-1].Version, nil
+}
+
+// Status сообщает текущую версию схемы и список миграций, ничего не меняя.
+func (m *Migrator) Status() (Status, error) {
+	v, dirty, err := m.Version()
+	if err != nil {
+		return Status{}, err
+	}
+
+	list, err := m.list()
+	if err != nil {
+		return Status{}, err
+	}
+
+	st := Status{Version: v, Dirty: dirty, Migrations: list}
+	for i := range st.Migrations {
+		st.Migrations[i].Applied = st.Migrations[i].Version <= v
+		if !st.Migrations[i].Applied {
+			st.Pending = append(st.Pending, st.Migrations[i])
+		}
+	}
+
+	return st, nil
+}
+
+func (m *Migrator) Close() error {
+	srcErr, dbErr := m.m.Close()
+	listErr := m.src.Close()
+
+	for _, err := range []error{srcErr, dbErr, listErr} {
+		if err != nil {
+			return errors.Wrap(err, "close migrator")
+		}
+	}
+
+	return nil
+}
+
+// list перечисляет встроенные миграции по возрастанию версии.
+func (m *Migrator) list() ([]Migration, error) {
+	var res []Migration
+
+	v, err := m.src.First()
+	for err == nil {
+		name := ""
+		r, ident, readErr := m.src.ReadUp(v)
+		if readErr == nil {
+			_ = r.Close()
+			name = ident
+		}
+		res = append(res, Migration{Version: v, Name: name})
+
+		v, err = m.src.Next(v)
+	}
+
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, errors.Wrap(err, "list migrations")
+	}
+
+	return res, nil
+}
+
+// Prepare готовит схему к запуску сервера: при auto применяет недостающие
+// миграции, иначе только проверяет версию схемы.
+func Prepare(storage config.Storage, pg config.Postgres, auto bool) (err error) {
+	m, err := New(storage, pg)
+	if errors.Is(err, ErrNoMigrations) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := m.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	if auto {
+		if err = m.Up(); err != nil {
+			return err
+		}
+	}
+
+	return m.Check()
+}
+
+func noChange(err error) error {
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+
+	var dirty migrate.ErrDirty
+	if errors.As(err, &dirty) {
+		return dirtyErr(uint(dirty.Version))
+	}
+
+	return errors.Wrap(err, "migrate")
+}
+
+func dirtyErr(v uint) error {
+	return errors.Errorf("schema is dirty at version %d: a previous migration failed halfway, "+
+		"fix the schema manually and run \"migrator force %d\"", v, v)
+}
+
+// logger выводит ход миграций golang-migrate в slog.
+type logger struct{}
+
+func (logger) Printf(format string, v ...any) {
+	slog.Info("migrate: " + strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
+
+func (logger) Verbose() bool { return false }
@@ -0,0 +1,120 @@
+package migrator
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vpbuyanov/syncplay/internal/config"
+)
+
+func newSQLite(t *testing.T) *Migrator {
+	t.Helper()
+
+	m, err := New(config.Storage{
+		Driver: config.StorageSQLite,
+		SQLite: config.SQLite{Path: filepath.Join(t.TempDir(), "syncplay.db")},
+	}, config.Postgres{})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = m.Close() })
+
+	return m
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(config.Storage{Driver: config.StorageMemory}, config.Postgres{})
+	require.ErrorIs(t, err, ErrNoMigrations)
+
+	_, err = New(config.Storage{Driver: "mysql"}, config.Postgres{})
+	require.EqualError(t, err, `unknown storage driver "mysql"`)
+}
+
+func TestMigrator_UpDown(t *testing.T) {
+	m := newSQLite(t)
+
+	st, err := m.Status()
+	require.NoError(t, err)
+	assert.Zero(t, st.Version)
+	require.NotEmpty(t, st.Migrations)
+	assert.Equal(t, st.Migrations, st.Pending)
+	assert.Equal(t, "base", st.Migrations[0].Name)
+
+	latest, err := m.Latest()
+	require.NoError(t, err)
+
+	require.NoError(t, m.Up())
+	require.NoError(t, m.Up(), "повторный up без изменений")
+
+	v, dirty, err := m.Version()
+	require.NoError(t, err)
+	assert.Equal(t, latest, v)
+	assert.False(t, dirty)
+
+	st, err = m.Status()
+	require.NoError(t, err)
+	assert.Empty(t, st.Pending)
+	for _, mig := range st.Migrations {
+		assert.True(t, mig.Applied, mig.Name)
+	}
+
+	require.NoError(t, m.Down(1))
+	require.Error(t, m.Down(0))
+
+	require.NoError(t, m.Goto(latest))
+	v, _, err = m.Version()
+	require.NoError(t, err)
+	assert.Equal(t, latest, v)
+}
+
+func TestMigrator_Force(t *testing.T) {
+	m := newSQLite(t)
+
+	require.NoError(t, m.Force(1))
+
+	v, dirty, err := m.Version()
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), v)
+	assert.False(t, dirty)
+
+	require.NoError(t, m.Force(-1))
+
+	v, _, err = m.Version()
+	require.NoError(t, err)
+	assert.Zero(t, v)
+}
+
+func TestPrepare(t *testing.T) {
+	storage := config.Storage{
+		Driver: config.StorageSQLite,
+		SQLite: config.SQLite{Path: filepath.Join(t.TempDir(), "syncplay.db")},
+	}
+
+	err := Prepare(storage, config.Postgres{}, false)
+	require.ErrorIs(t, err, ErrSchemaOutdated)
+	assert.Contains(t, err.Error(), "schema version is 0")
+
+	require.NoError(t, Prepare(storage, config.Postgres{}, true))
+	require.NoError(t, Prepare(storage, config.Postgres{}, false))
+
+	require.NoError(t, Prepare(config.Storage{Driver: config.StorageMemory}, config.Postgres{}, false))
+}
+
+func TestMigrator_CheckDirty(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "syncplay.db")
+	storage := config.Storage{Driver: config.StorageSQLite, SQLite: config.SQLite{Path: path}}
+	require.NoError(t, Prepare(storage, config.Postgres{}, true))
+
+	// Так выглядит схема после миграции, упавшей на середине
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	_, err = db.Exec(`update schema_migrations set dirty = 1`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	err = Prepare(storage, config.Postgres{}, false)
+	require.ErrorContains(t, err, "schema is dirty at version 3")
+	require.ErrorContains(t, Prepare(storage, config.Postgres{}, true), "migrator force 3")
+}
@@ -0,0 +1,173 @@
+package model
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// DefaultSessionTTL — срок жизни сессии встроенной учётной записи.
+const DefaultSessionTTL = 30 * 24 * time.Hour
+
+const (
+	minPasswordLen = 8
+	// maxPasswordBytes ограничивает работу argon2 на один запрос
+	maxPasswordBytes = 1024
+)
+
+var usernameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{2,31}$`)
+
+// User — встроенная учётная запись.
+type User struct {
+	ID        string
+	Username  string
+	CreatedAt time.Time
+}
+
+// Session — сессия, выданная при входе. Token отдаётся клиенту один раз,
+// в БД хранится только его хеш.
+type Session struct {
+	Token     string
+	User      User
+	ExpiresAt time.Time
+}
+
+// WithSessionTTL задаёт срок жизни сессий.
+func WithSessionTTL(d time.Duration) Option {
+	return func(r *Room) {
+		if d > 0 {
+			r.sessionTTL = d
+		}
+	}
+}
+
+// dummyHash сравнивается с паролем, когда пользователя нет, чтобы время
+// ответа не выдавало существующие имена.
+var dummyHash = sync.OnceValue(func() string {
+	h, _ := hashPassword("dummy password")
+	return h
+})
+
+// Register создаёт учётную запись; имя приводится к нижнему регистру.
+func (r *Room) Register(ctx context.Context, username, password string) (_ User, err error) {
+	ctx, span := startSpan(ctx, "Register")
+	defer func() { endSpan(span, err) }()
+
+	username = strings.ToLower(strings.TrimSpace(username))
+	if !usernameRe.MatchString(username) {
+		return User{}, errors.Wrap(ErrInvalidArgument,
+			"username must be 3 to 32 characters: latin letters, digits, '.', '_' or '-'")
+	}
+	if utf8.RuneCountInString(password) < minPasswordLen || len(password) > maxPasswordBytes {
+		return User{}, errors.Wrapf(ErrInvalidArgument,
+			"password must be at least %d characters and at most %d bytes", minPasswordLen, maxPasswordBytes)
+	}
+
+	release, err := acquireHashSlot(ctx)
+	if err != nil {
+		return User{}, errors.Wrap(err, "Register model err")
+	}
+	hash, err := hashPassword(password)
+	release()
+	if err != nil {
+		return User{}, errors.Wrap(err, "Register model err")
+	}
+
+	user, err := r.CreateUser(ctx, User{ID: uuid.NewString(), Username: username}, hash)
+	if err != nil {
+		return User{}, errors.Wrap(err, "Register model err")
+	}
+
+	return user, nil
+}
+
+// Login проверяет пароль и выдаёт новую сессию. Заодно удаляются
+// истёкшие сессии пользователя.
+func (r *Room) Login(ctx context.Context, username, password string) (_ Session, err error) {
+	ctx, span := startSpan(ctx, "Login")
+	defer func() { endSpan(span, err) }()
+
+	username = strings.ToLower(strings.TrimSpace(username))
+	if len(password) > maxPasswordBytes {
+		return Session{}, errors.Wrap(ErrUnauthorized, "invalid username or password")
+	}
+
+	// Слот нужен и для несуществующего пользователя: там проверяется dummyHash
+	release, err := acquireHashSlot(ctx)
+	if err != nil {
+		return Session{}, errors.Wrap(err, "Login model err")
+	}
+	defer release()
+
+	user, hash, err := r.UserByName(ctx, username)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		_, _ = checkPassword(password, dummyHash())
+		return Session{}, errors.Wrap(ErrUnauthorized, "invalid username or password")
+	case err != nil:
+		return Session{}, errors.Wrap(err, "Login model err")
+	}
+
+	ok, err := checkPassword(password, hash)
+	if err != nil {
+		return Session{}, errors.Wrap(err, "Login model err")
+	}
+	if !ok {
+		return Session{}, errors.Wrap(ErrUnauthorized, "invalid username or password")
+	}
+
+	now := time.Now()
+	if err = r.DeleteExpiredSessions(ctx, user.ID, now); err != nil {
+		return Session{}, errors.Wrap(err, "Login model err")
+	}
+
+	token, tokenHash, err := newToken()
+	if err != nil {
+		return Session{}, errors.Wrap(err, "Login model err")
+	}
+
+	expiresAt := now.Add(r.sessionTTL).UTC().Truncate(time.Second)
+	if err = r.CreateSession(ctx, tokenHash, user.ID, expiresAt); err != nil {
+		return Session{}, errors.Wrap(err, "Login model err")
+	}
+
+	return Session{Token: token, User: user, ExpiresAt: expiresAt}, nil
+}
+
+// Logout удаляет сессию; неизвестный токен ошибкой не считается.
+func (r *Room) Logout(ctx context.Context, token string) (err error) {
+	ctx, span := startSpan(ctx, "Logout")
+	defer func() { endSpan(span, err) }()
+
+	if err = r.DeleteSession(ctx, hashToken(token)); err != nil {
+		return errors.Wrap(err, "Logout model err")
+	}
+
+	return nil
+}
+
+// SessionUser возвращает владельца действующей сессии.
+func (r *Room) SessionUser(ctx context.Context, token string) (_ User, _ time.Time, err error) {
+	ctx, span := startSpan(ctx, "SessionUser")
+	defer func() { endSpan(span, err) }()
+
+	user, expiresAt, err := r.SessionByHash(ctx, hashToken(token))
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return User{}, time.Time{}, errors.Wrap(ErrUnauthorized, "unknown session")
+	case err != nil:
+		return User{}, time.Time{}, errors.Wrap(err, "SessionUser model err")
+	}
+
+	if !expiresAt.After(time.Now()) {
+		return User{}, time.Time{}, errors.Wrap(ErrUnauthorized, "session expired")
+	}
+
+	return user, expiresAt, nil
+}
@@ -0,0 +1,205 @@
+package model
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// cheapPasswords ускоряет argon2 в тестах.
+func cheapPasswords(t *testing.T) {
+	t.Helper()
+
+	prev := passwordParams
+	passwordParams = argonParams{time: 1, memory: 64, threads: 1, saltLen: 16, keyLen: 32}
+	t.Cleanup(func() { passwordParams = prev })
+}
+
+func TestPassword(t *testing.T) {
+	cheapPasswords(t)
+
+	hash, err := hashPassword("correct horse")
+	require.NoError(t, err)
+	assert.Contains(t, hash, "$argon2id$v=19$m=64,t=1,p=1$")
+
+	other, err := hashPassword("correct horse")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, other, "соль должна быть случайной")
+
+	ok, err := checkPassword("correct horse", hash)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = checkPassword("wrong horse", hash)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = checkPassword("correct horse", "$2a$10$bcrypt")
+	require.Error(t, err)
+}
+
+func TestRoom_PasswordSlots(t *testing.T) {
+	cheapPasswords(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	r := NewModelRoom(NewMockstorePG(ctrl))
+
+	// Все слоты заняты: вход и регистрация отказывают, не трогая хранилище
+	for range cap(hashSlots) {
+		hashSlots <- struct{}{}
+	}
+	defer func() {
+		for range cap(hashSlots) {
+			<-hashSlots
+		}
+	}()
+
+	_, err := r.Login(context.Background(), "alice", "password1")
+	require.ErrorIs(t, err, ErrRateLimited)
+
+	_, err = r.Register(context.Background(), "alice", "password1")
+	require.ErrorIs(t, err, ErrRateLimited)
+}
+
+func TestRoom_Register(t *testing.T) {
+	cheapPasswords(t)
+
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := NewMockstorePG(ctrl)
+	r := NewModelRoom(mockStore)
+
+	t.Run("success", func(t *testing.T) {
+		mockStore.
+			EXPECT().
+			CreateUser(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, u User, hash string) (User, error) {
+				assert.Equal(t, "alice", u.Username)
+				assert.NotEmpty(t, u.ID)
+
+				ok, err := checkPassword("password1", hash)
+				require.NoError(t, err)
+				assert.True(t, ok)
+
+				return u, nil
+			})
+
+		user, err := r.Register(ctx, " Alice ", "password1")
+		require.NoError(t, err)
+		assert.Equal(t, "alice", user.Username)
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		_, err := r.Register(ctx, "a", "password1")
+		require.ErrorIs(t, err, ErrInvalidArgument)
+
+		_, err = r.Register(ctx, "alice!", "password1")
+		require.ErrorIs(t, err, ErrInvalidArgument)
+
+		_, err = r.Register(ctx, "alice", "short")
+		require.ErrorIs(t, err, ErrInvalidArgument)
+	})
+
+	t.Run("taken", func(t *testing.T) {
+		mockStore.
+			EXPECT().
+			CreateUser(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(User{}, errors.Wrap(ErrConflict, "duplicate"))
+
+		_, err := r.Register(ctx, "alice", "password1")
+		require.ErrorIs(t, err, ErrConflict)
+	})
+}
+
+func TestRoom_Login(t *testing.T) {
+	cheapPasswords(t)
+
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := NewMockstorePG(ctrl)
+	r := NewModelRoom(mockStore, WithSessionTTL(time.Hour))
+
+	hash, err := hashPassword("password1")
+	require.NoError(t, err)
+	alice := User{ID: "user-1", Username: "alice"}
+
+	t.Run("success", func(t *testing.T) {
+		mockStore.EXPECT().UserByName(gomock.Any(), "alice").Return(alice, hash, nil)
+		mockStore.EXPECT().DeleteExpiredSessions(gomock.Any(), alice.ID, gomock.Any()).Return(nil)
+
+		var stored []byte
+		mockStore.
+			EXPECT().
+			CreateSession(gomock.Any(), gomock.Any(), alice.ID, gomock.Any()).
+			DoAndReturn(func(_ context.Context, tokenHash []byte, _ string, _ time.Time) error {
+				stored = tokenHash
+				return nil
+			})
+
+		session, err := r.Login(ctx, "Alice", "password1")
+		require.NoError(t, err)
+		assert.Equal(t, alice, session.User)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), session.ExpiresAt, time.Minute)
+		assert.Equal(t, hashToken(session.Token), stored, "в БД хранится только хеш токена")
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		mockStore.EXPECT().UserByName(gomock.Any(), "alice").Return(alice, hash, nil)
+
+		_, err := r.Login(ctx, "alice", "password2")
+		require.ErrorIs(t, err, ErrUnauthorized)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		mockStore.EXPECT().UserByName(gomock.Any(), "bob").Return(User{}, "", errors.Wrap(ErrNotFound, "no user"))
+
+		_, err := r.Login(ctx, "bob", "password1")
+		require.ErrorIs(t, err, ErrUnauthorized)
+	})
+}
+
+func TestRoom_SessionUser(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := NewMockstorePG(ctrl)
+	r := NewModelRoom(mockStore)
+
+	alice := User{ID: "user-1", Username: "alice"}
+
+	t.Run("success", func(t *testing.T) {
+		expiresAt := time.Now().Add(time.Hour)
+		mockStore.EXPECT().SessionByHash(gomock.Any(), hashToken("token")).Return(alice, expiresAt, nil)
+
+		user, exp, err := r.SessionUser(ctx, "token")
+		require.NoError(t, err)
+		assert.Equal(t, alice, user)
+		assert.Equal(t, expiresAt, exp)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		mockStore.EXPECT().SessionByHash(gomock.Any(), gomock.Any()).Return(alice, time.Now().Add(-time.Second), nil)
+
+		_, _, err := r.SessionUser(ctx, "token")
+		require.ErrorIs(t, err, ErrUnauthorized)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		mockStore.EXPECT().SessionByHash(gomock.Any(), gomock.Any()).Return(User{}, time.Time{}, errors.Wrap(ErrNotFound, "no session"))
+
+		_, _, err := r.SessionUser(ctx, "token")
+		require.ErrorIs(t, err, ErrUnauthorized)
+	})
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+const maxChatTextLen = 2000
+
+// ChatMessage — сообщение чата комнаты.
+type ChatMessage struct {
+	ID        int64
+	RoomID    string
+	Sender    string
+	Text      string
+	CreatedAt time.Time
+}
+
+func (r *Room) SaveChatMessage(ctx context.Context, roomID, sender, text string) (_ ChatMessage, err error) {
+	ctx, span := startSpan(ctx, "SaveChatMessage")
+	defer func() { endSpan(span, err) }()
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return ChatMessage{}, errors.Wrap(ErrInvalidArgument, "empty chat message")
+	}
+	if utf8.RuneCountInString(text) > maxChatTextLen {
+		return ChatMessage{}, errors.Wrapf(ErrInvalidArgument, "chat message is longer than %d characters", maxChatTextLen)
+	}
+
+	msg, err := r.storePG.SaveChatMessage(ctx, ChatMessage{
+		RoomID: roomID,
+		Sender: sender,
+		Text:   text,
+	})
+	if err != nil {
+		return ChatMessage{}, errors.Wrap(err, "SaveChatMessage model err")
+	}
+
+	return msg, nil
+}
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRoom_SaveChatMessage(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := NewMockstorePG(ctrl)
+	r := NewModelRoom(mockStore)
+
+	roomID := uuid.NewString()
+
+	t.Run("success", func(t *testing.T) {
+		saved := ChatMessage{ID: 1, RoomID: roomID, Sender: "peer", Text: "hi", CreatedAt: time.Now()}
+		mockStore.
+			EXPECT().
+			SaveChatMessage(gomock.Any(), ChatMessage{RoomID: roomID, Sender: "peer", Text: "hi"}).
+			Return(saved, nil)
+
+		got, err := r.SaveChatMessage(ctx, roomID, "peer", "  hi ")
+		require.NoError(t, err)
+		assert.Equal(t, saved, got)
+	})
+
+	t.Run("invalid text", func(t *testing.T) {
+		_, err := r.SaveChatMessage(ctx, roomID, "peer", " ")
+		require.ErrorIs(t, err, ErrInvalidArgument)
+
+		_, err = r.SaveChatMessage(ctx, roomID, "peer", strings.Repeat("a", maxChatTextLen+1))
+		require.ErrorIs(t, err, ErrInvalidArgument)
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		mockStore.
+			EXPECT().
+			SaveChatMessage(gomock.Any(), gomock.Any()).
+			Return(ChatMessage{}, assert.AnError)
+
+		_, err := r.SaveChatMessage(ctx, roomID, "peer", "hi")
+		require.ErrorIs(t, err, assert.AnError)
+	})
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Виды доменных ошибок. Конкретные ошибки оборачивают один из них
+// через errors.Wrap, вызывающий различает их через errors.Is.
+var (
+	// ErrInvalidArgument возвращается, когда входные параметры не прошли валидацию.
+	ErrInvalidArgument = errors.New("invalid argument")
+	// ErrNotFound возвращается, когда запрошенный объект не существует.
+	ErrNotFound = errors.New("not found")
+	// ErrConflict возвращается, когда объект уже существует или изменён параллельно.
+	ErrConflict = errors.New("conflict")
+	// ErrUnauthorized возвращается, когда вызывающий не предъявил действительных учётных данных.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden возвращается, когда у вызывающего нет прав на операцию.
+	ErrForbidden = errors.New("forbidden")
+	// ErrUnavailable возвращается, когда хранилище временно недоступно.
+	ErrUnavailable = errors.New("unavailable")
+	// ErrRateLimited возвращается, когда вызывающий превысил допустимую частоту запросов.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrQuotaExceeded возвращается, когда арендатор исчерпал квоту.
+	ErrQuotaExceeded = errors.New("quota exceeded")
+)
+
+// Машиночитаемые коды ошибок. Коды — часть публичного API, их нельзя менять.
+const (
+	CodeValidation    = "validation-failed"
+	CodeNotFound      = "not-found"
+	CodeConflict      = "conflict"
+	CodeUnauthorized  = "unauthorized"
+	CodeForbidden     = "forbidden"
+	CodeUnavailable   = "unavailable"
+	CodeRateLimited   = "rate-limited"
+	CodeQuotaExceeded = "quota-exceeded"
+	CodeInternal      = "internal"
+)
+
+var kinds = []struct {
+	err  error
+	code string
+}{
+	{ErrInvalidArgument, CodeValidation},
+	{ErrNotFound, CodeNotFound},
+	{ErrConflict, CodeConflict},
+	{ErrUnauthorized, CodeUnauthorized},
+	{ErrForbidden, CodeForbidden},
+	{ErrUnavailable, CodeUnavailable},
+	{ErrRateLimited, CodeRateLimited},
+	{ErrQuotaExceeded, CodeQuotaExceeded},
+}
+
+// ErrorCode возвращает код доменной ошибки; для ошибок вне таксономии — CodeInternal.
+func ErrorCode(err error) string {
+	for _, k := range kinds {
+		if errors.Is(err, k.err) {
+			return k.code
+		}
+	}
+
+	return CodeInternal
+}
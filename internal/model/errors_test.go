@@ -0,0 +1,21 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorCode(t *testing.T) {
+	assert.Equal(t, CodeValidation, ErrorCode(errors.Wrap(ErrInvalidArgument, "bad")))
+	assert.Equal(t, CodeNotFound, ErrorCode(errors.Wrap(errors.Wrap(ErrNotFound, "room"), "model")))
+	assert.Equal(t, CodeConflict, ErrorCode(ErrConflict))
+	assert.Equal(t, CodeForbidden, ErrorCode(ErrForbidden))
+	assert.Equal(t, CodeUnavailable, ErrorCode(ErrUnavailable))
+	assert.Equal(t, CodeRateLimited, ErrorCode(errors.Wrap(ErrRateLimited, "too many rooms")))
+	assert.Equal(t, CodeUnauthorized, ErrorCode(errors.Wrap(ErrUnauthorized, "missing token")))
+	assert.Equal(t, CodeQuotaExceeded, ErrorCode(errors.Wrap(ErrQuotaExceeded, "room limit")))
+	assert.Equal(t, CodeInternal, ErrorCode(errors.New("boom")))
+	assert.Equal(t, CodeInternal, ErrorCode(nil))
+}
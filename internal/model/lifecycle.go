@@ -0,0 +1,94 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"github.com/pkg/errors"
+)
+
+// TouchRoomUUID отмечает комнату активной: от этого момента отсчитывается idle TTL.
+func (r *Room) TouchRoomUUID(ctx context.Context, roomID openapi_types.UUID) (err error) {
+	ctx, span := startSpan(ctx, "TouchRoomUUID")
+	defer func() { endSpan(span, err) }()
+
+	err = r.TouchRoom(ctx, roomID.String())
+	if err != nil {
+		return errors.Wrap(err, "TouchRoom model err")
+	}
+
+	return nil
+}
+
+// RetireRooms архивирует комнаты или удаляет их вместе с историей чата.
+func (r *Room) RetireRooms(ctx context.Context, ids []string, archive bool) (_ int64, err error) {
+	ctx, span := startSpan(ctx, "RetireRooms")
+	defer func() { endSpan(span, err) }()
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	var n int64
+	if archive {
+		n, err = r.ArchiveRooms(ctx, ids)
+	} else {
+		n, err = r.PurgeRooms(ctx, ids)
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "RetireRooms model err")
+	}
+
+	return n, nil
+}
+
+// RestoreRoom восстанавливает архивированную или удалённую комнату,
+// если с момента удаления не прошло окно восстановления.
+func (r *Room) RestoreRoom(ctx context.Context, id openapi_types.UUID) (err error) {
+	ctx, span := startSpan(ctx, "RestoreRoom")
+	defer func() { endSpan(span, err) }()
+
+	deletedAfter := time.Now().Add(-r.restoreWindow).UTC()
+
+	err = r.RestoreRoomById(ctx, id.String(), deletedAfter)
+	if err != nil {
+		return errors.Wrap(err, "RestoreRoom model err")
+	}
+
+	return nil
+}
+
+// ArchiveRoom переводит комнату в архив; история чата сохраняется.
+func (r *Room) ArchiveRoom(ctx context.Context, id openapi_types.UUID) (err error) {
+	ctx, span := startSpan(ctx, "ArchiveRoom")
+	defer func() { endSpan(span, err) }()
+
+	n, err := r.ArchiveRooms(ctx, []string{id.String()})
+	if err != nil {
+		return errors.Wrap(err, "ArchiveRoom model err")
+	}
+
+	if n == 0 {
+		return errors.Wrap(ErrNotFound, "room not found")
+	}
+
+	return nil
+}
+
+// PurgeRoom окончательно удаляет комнату вместе с историей чата.
+func (r *Room) PurgeRoom(ctx context.Context, id openapi_types.UUID) (err error) {
+	ctx, span := startSpan(ctx, "PurgeRoom")
+	defer func() { endSpan(span, err) }()
+
+	n, err := r.PurgeRooms(ctx, []string{id.String()})
+	if err != nil {
+		return errors.Wrap(err, "PurgeRoom model err")
+	}
+
+	if n == 0 {
+		return errors.Wrap(ErrNotFound, "room not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package model
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRoom_TouchRoomUUID(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := NewMockstorePG(ctrl)
+	r := NewModelRoom(mockStore)
+
+	id := uuid.New()
+	mockStore.EXPECT().TouchRoom(gomock.Any(), id.String()).Return(nil)
+	require.NoError(t, r.TouchRoomUUID(ctx, id))
+
+	mockStore.EXPECT().TouchRoom(gomock.Any(), id.String()).Return(assert.AnError)
+	require.ErrorIs(t, r.TouchRoomUUID(ctx, id), assert.AnError)
+}
+
+func TestRoom_RetireRooms(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := NewMockstorePG(ctrl)
+	r := NewModelRoom(mockStore)
+
+	ids := []string{uuid.NewString()}
+
+	t.Run("empty", func(t *testing.T) {
+		n, err := r.RetireRooms(ctx, nil, true)
+		require.NoError(t, err)
+		assert.Zero(t, n)
+	})
+
+	t.Run("archive", func(t *testing.T) {
+		mockStore.EXPECT().ArchiveRooms(gomock.Any(), ids).Return(int64(1), nil)
+
+		n, err := r.RetireRooms(ctx, ids, true)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		mockStore.EXPECT().PurgeRooms(gomock.Any(), ids).Return(int64(1), nil)
+
+		n, err := r.RetireRooms(ctx, ids, false)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		mockStore.EXPECT().PurgeRooms(gomock.Any(), ids).Return(int64(0), assert.AnError)
+
+		_, err := r.RetireRooms(ctx, ids, false)
+		require.ErrorIs(t, err, assert.AnError)
+	})
+}
+
+func TestRoom_RestoreRoom(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := NewMockstorePG(ctrl)
+	r := NewModelRoom(mockStore, WithRestoreWindow(time.Hour))
+
+	id := uuid.New()
+
+	t.Run("success", func(t *testing.T) {
+		start := time.Now()
+		mockStore.
+			EXPECT().
+			RestoreRoomById(gomock.Any(), id.String(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, deletedAfter time.Time) error {
+				assert.WithinDuration(t, start.Add(-time.Hour), deletedAfter, 5*time.Second)
+				return nil
+			})
+
+		require.NoError(t, r.RestoreRoom(ctx, id))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockStore.
+			EXPECT().
+			RestoreRoomById(gomock.Any(), id.String(), gomock.Any()).
+			Return(ErrNotFound)
+
+		require.ErrorIs(t, r.RestoreRoom(ctx, id), ErrNotFound)
+	})
+}
+
+func TestRoom_ArchiveAndPurgeRoom(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := NewMockstorePG(ctrl)
+	r := NewModelRoom(mockStore)
+
+	id := uuid.New()
+	ids := []string{id.String()}
+
+	t.Run("archive", func(t *testing.T) {
+		mockStore.EXPECT().ArchiveRooms(gomock.Any(), ids).Return(int64(1), nil)
+		require.NoError(t, r.ArchiveRoom(ctx, id))
+
+		mockStore.EXPECT().ArchiveRooms(gomock.Any(), ids).Return(int64(0), nil)
+		require.ErrorIs(t, r.ArchiveRoom(ctx, id), ErrNotFound)
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		mockStore.EXPECT().PurgeRooms(gomock.Any(), ids).Return(int64(1), nil)
+		require.NoError(t, r.PurgeRoom(ctx, id))
+
+		mockStore.EXPECT().PurgeRooms(gomock.Any(), ids).Return(int64(0), nil)
+		require.ErrorIs(t, r.PurgeRoom(ctx, id), ErrNotFound)
+
+		mockStore.EXPECT().PurgeRooms(gomock.Any(), ids).Return(int64(0), assert.AnError)
+		require.ErrorIs(t, r.PurgeRoom(ctx, id), assert.AnError)
+	})
+}
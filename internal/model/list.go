@@ -0,0 +1,191 @@
+package model
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const (
+	SortCreatedAsc  = "created_at"
+	SortCreatedDesc = "-created_at"
+	SortTitleAsc    = "title"
+	SortTitleDesc   = "-title"
+)
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+// ListRoomsParams — параметры запроса списка комнат.
+// ActiveIDs — комнаты, в которых сейчас есть подключённые участники;
+// используется фильтром HasActivePeers. CreatedBy оставляет комнаты
+// одного пользователя ("мои комнаты").
+type ListRoomsParams struct {
+	Limit          int
+	Cursor         string
+	Visibility     string
+	CreatedAfter   *time.Time
+	CreatedBy      string
+	Tag            string
+	HasActivePeers *bool
+	ActiveIDs      []uuid.UUID
+	Sort           string
+}
+
+// RoomFilter — условия выборки комнат, передаваемые в хранилище.
+// After — последняя комната предыдущей страницы (keyset-пагинация).
+type RoomFilter struct {
+	Visibility     string
+	CreatedAfter   *time.Time
+	CreatedBy      string
+	Tag            string
+	HasActivePeers *bool
+	ActiveIDs      []uuid.UUID
+	Sort           string
+	After          *RoomInfo
+	Limit          int
+}
+
+// RoomPage — страница списка комнат.
+type RoomPage struct {
+	Items      []RoomInfo
+	NextCursor string
+}
+
+type cursor struct {
+	Sort      string    `json:"s"`
+	ID        string    `json:"id"`
+	Title     string    `json:"t,omitempty"`
+	CreatedAt time.Time `json:"c,omitempty"`
+}
+
+func (r *Room) ListRooms(ctx context.Context, p ListRoomsParams) (_ RoomPage, err error) {
+	ctx, span := startSpan(ctx, "ListRooms")
+	defer func() { endSpan(span, err) }()
+
+	f, err := p.filter()
+	if err != nil {
+		return RoomPage{}, err
+	}
+
+	// Среди пустого множества активных комнат искать нечего
+	if f.HasActivePeers != nil && *f.HasActivePeers && len(f.ActiveIDs) == 0 {
+		return RoomPage{Items: []RoomInfo{}}, nil
+	}
+
+	// Запрашиваем на одну запись больше, чтобы понять, есть ли следующая страница
+	limit := f.Limit
+	f.Limit++
+
+	items, err := r.storePG.ListRooms(ctx, f)
+	if err != nil {
+		return RoomPage{}, errors.Wrap(err, "ListRooms model err")
+	}
+
+	page := RoomPage{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+
+		page.NextCursor, err = encodeCursor(f.Sort, page.Items[limit-1])
+		if err != nil {
+			return RoomPage{}, err
+		}
+	}
+
+	return page, nil
+}
+
+func (p ListRoomsParams) filter() (RoomFilter, error) {
+	f := RoomFilter{
+		Visibility:     p.Visibility,
+		CreatedBy:      p.CreatedBy,
+		Tag:            strings.ToLower(strings.TrimSpace(p.Tag)),
+		HasActivePeers: p.HasActivePeers,
+		ActiveIDs:      p.ActiveIDs,
+		Sort:           p.Sort,
+		Limit:          p.Limit,
+	}
+
+	switch {
+	case f.Limit == 0:
+		f.Limit = defaultListLimit
+	case f.Limit < 0 || f.Limit > maxListLimit:
+		return f, errors.Wrapf(ErrInvalidArgument, "limit must be between 1 and %d", maxListLimit)
+	}
+
+	switch f.Visibility {
+	case "", VisibilityPublic, VisibilityPrivate:
+	default:
+		return f, errors.Wrapf(ErrInvalidArgument, "unknown visibility %q", f.Visibility)
+	}
+
+	switch f.Sort {
+	case "":
+		f.Sort = SortCreatedDesc
+	case SortCreatedAsc, SortCreatedDesc, SortTitleAsc, SortTitleDesc:
+	default:
+		return f, errors.Wrapf(ErrInvalidArgument, "unknown sort %q", f.Sort)
+	}
+
+	if p.CreatedAfter != nil {
+		t := p.CreatedAfter.UTC()
+		f.CreatedAfter = &t
+	}
+
+	if p.Cursor != "" {
+		after, err := decodeCursor(p.Cursor, f.Sort)
+		if err != nil {
+			return f, err
+		}
+		f.After = &after
+	}
+
+	return f, nil
+}
+
+func encodeCursor(sort string, last RoomInfo) (string, error) {
+	c := cursor{Sort: sort, ID: last.ID}
+
+	switch sort {
+	case SortTitleAsc, SortTitleDesc:
+		c.Title = last.Title
+	default:
+		c.CreatedAt = last.CreatedAt
+	}
+
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return "", errors.Wrap(err, "encode cursor")
+	}
+
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func decodeCursor(s, sort string) (RoomInfo, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return RoomInfo{}, errors.Wrap(ErrInvalidArgument, "malformed cursor")
+	}
+
+	var c cursor
+	if err = json.Unmarshal(raw, &c); err != nil || c.ID == "" {
+		return RoomInfo{}, errors.Wrap(ErrInvalidArgument, "malformed cursor")
+	}
+
+	if c.Sort != sort {
+		return RoomInfo{}, errors.Wrap(ErrInvalidArgument, "cursor was issued for a different sort order")
+	}
+
+	return RoomInfo{
+		ID:        c.ID,
+		RoomMeta:  RoomMeta{Title: c.Title},
+		CreatedAt: c.CreatedAt,
+	}, nil
+}
@@ -0,0 +1,119 @@
+package model
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRoom_ListRooms(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := NewMockstorePG(ctrl)
+	r := NewModelRoom(mockStore)
+
+	rooms := []RoomInfo{
+		{ID: uuid.NewString(), CreatedAt: time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)},
+		{ID: uuid.NewString(), CreatedAt: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{ID: uuid.NewString(), CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	t.Run("first page and next cursor", func(t *testing.T) {
+		mockStore.
+			EXPECT().
+			ListRooms(gomock.Any(), RoomFilter{Sort: SortCreatedDesc, Limit: 3}).
+			Return(rooms, nil)
+
+		page, err := r.ListRooms(ctx, ListRoomsParams{Limit: 2})
+		require.NoError(t, err)
+		assert.Equal(t, rooms[:2], page.Items)
+		require.NotEmpty(t, page.NextCursor)
+
+		mockStore.
+			EXPECT().
+			ListRooms(gomock.Any(), RoomFilter{
+				Sort:  SortCreatedDesc,
+				Limit: 3,
+				After: &RoomInfo{ID: rooms[1].ID, CreatedAt: rooms[1].CreatedAt},
+			}).
+			Return(rooms[2:], nil)
+
+		page, err = r.ListRooms(ctx, ListRoomsParams{Limit: 2, Cursor: page.NextCursor})
+		require.NoError(t, err)
+		assert.Equal(t, rooms[2:], page.Items)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("filters are passed to store", func(t *testing.T) {
+		after := time.Date(2025, 1, 1, 3, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+		utc := after.UTC()
+		active := false
+		ids := []uuid.UUID{uuid.New()}
+
+		mockStore.
+			EXPECT().
+			ListRooms(gomock.Any(), RoomFilter{
+				Visibility:     VisibilityPrivate,
+				CreatedAfter:   &utc,
+				Tag:            "movies",
+				HasActivePeers: &active,
+				ActiveIDs:      ids,
+				Sort:           SortTitleAsc,
+				Limit:          defaultListLimit + 1,
+			}).
+			Return(nil, nil)
+
+		page, err := r.ListRooms(ctx, ListRoomsParams{
+			Visibility:     VisibilityPrivate,
+			CreatedAfter:   &after,
+			Tag:            " Movies ",
+			HasActivePeers: &active,
+			ActiveIDs:      ids,
+			Sort:           SortTitleAsc,
+		})
+		require.NoError(t, err)
+		assert.Empty(t, page.Items)
+	})
+
+	t.Run("no active rooms", func(t *testing.T) {
+		active := true
+
+		page, err := r.ListRooms(ctx, ListRoomsParams{HasActivePeers: &active})
+		require.NoError(t, err)
+		assert.Empty(t, page.Items)
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		titleCursor, err := encodeCursor(SortTitleAsc, rooms[0])
+		require.NoError(t, err)
+
+		for _, p := range []ListRoomsParams{
+			{Limit: maxListLimit + 1},
+			{Limit: -1},
+			{Sort: "peers"},
+			{Visibility: "secret"},
+			{Cursor: "%%%"},
+			{Cursor: titleCursor},
+		} {
+			_, err := r.ListRooms(ctx, p)
+			assert.ErrorIs(t, err, ErrInvalidArgument, "params %+v", p)
+		}
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		mockStore.
+			EXPECT().
+			ListRooms(gomock.Any(), gomock.Any()).
+			Return(nil, assert.AnError)
+
+		_, err := r.ListRooms(ctx, ListRoomsParams{})
+		require.ErrorIs(t, err, assert.AnError)
+	})
+}
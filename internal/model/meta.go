@@ -0,0 +1,94 @@
+package model
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	VisibilityPublic  = "public"
+	VisibilityPrivate = "private"
+)
+
+const (
+	maxTitleLen       = 200
+	maxDescriptionLen = 2000
+	maxTags           = 10
+	maxTagLen         = 32
+)
+
+// RoomMeta — пользовательские метаданные комнаты. CreatedBy — создатель
+// в виде local:<id учётной записи> или jwt:<iss>|<sub>; пусто для
+// анонимных комнат.
+type RoomMeta struct {
+	Title       string
+	Description string
+	Visibility  string
+	Tags        []string
+	ExpiresAt   *time.Time
+	CreatedBy   string
+}
+
+// RoomInfo — комната в том виде, в котором она хранится в БД.
+type RoomInfo struct {
+	ID string
+	RoomMeta
+	CreatedAt time.Time
+}
+
+// normalize приводит метаданные к каноничному виду и проверяет ограничения.
+func (m RoomMeta) normalize() (RoomMeta, error) {
+	m.Title = strings.TrimSpace(m.Title)
+	m.Description = strings.TrimSpace(m.Description)
+
+	if utf8.RuneCountInString(m.Title) > maxTitleLen {
+		return m, errors.Wrapf(ErrInvalidArgument, "title is longer than %d characters", maxTitleLen)
+	}
+	if utf8.RuneCountInString(m.Description) > maxDescriptionLen {
+		return m, errors.Wrapf(ErrInvalidArgument, "description is longer than %d characters", maxDescriptionLen)
+	}
+
+	switch m.Visibility {
+	case "":
+		m.Visibility = VisibilityPublic
+	case VisibilityPublic, VisibilityPrivate:
+	default:
+		return m, errors.Wrapf(ErrInvalidArgument, "unknown visibility %q", m.Visibility)
+	}
+
+	if len(m.Tags) > maxTags {
+		return m, errors.Wrapf(ErrInvalidArgument, "more than %d tags", maxTags)
+	}
+
+	tags := make([]string, 0, len(m.Tags))
+	seen := make(map[string]struct{}, len(m.Tags))
+	for _, tag := range m.Tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > maxTagLen {
+			return m, errors.Wrapf(ErrInvalidArgument, "tag %q is longer than %d characters", tag, maxTagLen)
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		tags = append(tags, tag)
+	}
+	m.Tags = tags
+
+	if m.ExpiresAt != nil {
+		if !m.ExpiresAt.After(time.Now()) {
+			return m, errors.Wrap(ErrInvalidArgument, "expires_at must be in the future")
+		}
+
+		t := m.ExpiresAt.UTC()
+		m.ExpiresAt = &t
+	}
+
+	return m, nil
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	openapi_types "github.com/oapi-codegen/runtime/types"
@@ -10,36 +11,108 @@ import (
 
 //go:generate mockgen -source=model.go -destination model_mock.go -package model MODEL
 type storePG interface {
-	CreateRoomById(ctx context.Context, id string) error
+	CreateRoomById(ctx context.Context, id string, meta RoomMeta, ownerHash []byte) error
 	DeleteRoomById(ctx context.Context, id string) error
 	RoomExists(ctx context.Context, id string) (bool, error)
+	ListRooms(ctx context.Context, filter RoomFilter) ([]RoomInfo, error)
+	RoomOwnerHash(ctx context.Context, id string) ([]byte, error)
+	RoomCreatedBy(ctx context.Context, id string) (string, error)
+	SaveChatMessage(ctx context.Context, msg ChatMessage) (ChatMessage, error)
+	SearchRooms(ctx context.Context, query string, limit int) ([]SearchHit, error)
+	SearchChat(ctx context.Context, roomID, query string, limit int) ([]SearchHit, error)
+	TouchRoom(ctx context.Context, id string) error
+	ExpiringRooms(ctx context.Context, before time.Time, limit int) ([]RoomInfo, error)
+	IdleRooms(ctx context.Context, before time.Time, exclude []string, limit int) ([]string, error)
+	ArchiveRooms(ctx context.Context, ids []string) (int64, error)
+	PurgeRooms(ctx context.Context, ids []string) (int64, error)
+	RestoreRoomById(ctx context.Context, id string, deletedAfter time.Time) error
+	DeletedRooms(ctx context.Context, before time.Time, limit int) ([]string, error)
+	CreateUser(ctx context.Context, user User, passwordHash string) (User, error)
+	UserByName(ctx context.Context, username string) (User, string, error)
+	CreateSession(ctx context.Context, tokenHash []byte, userID string, expiresAt time.Time) error
+	SessionByHash(ctx context.Context, tokenHash []byte) (User, time.Time, error)
+	DeleteSession(ctx context.Context, tokenHash []byte) error
+	DeleteExpiredSessions(ctx context.Context, userID string, before time.Time) error
+	CreateTenant(ctx context.Context, tenant Tenant, keyHash []byte) (Tenant, error)
+	UpdateTenantQuotas(ctx context.Context, name string, quotas TenantQuotas) (Tenant, error)
+	ListTenants(ctx context.Context) ([]Tenant, error)
+	TenantByKeyHash(ctx context.Context, keyHash []byte) (Tenant, error)
+	CountRooms(ctx context.Context) (int, error)
+	CountRoomsByTenant(ctx context.Context) (map[string]int, error)
+	Ping(ctx context.Context) error
 }
 
+// Store — интерфейс хранилища комнат; его реализуют все бэкенды хранения.
+// CreateRoomById проверяет квоту комнат арендатора из ctx атомарно со вставкой
+// и при исчерпании возвращает ErrQuotaExceeded.
+type Store = storePG
+
+// DefaultRestoreWindow — сколько мягко удалённая комната доступна для восстановления.
+const DefaultRestoreWindow = 7 * 24 * time.Hour
+
 type Room struct {
 	storePG
+
+	restoreWindow time.Duration
+	sessionTTL    time.Duration
+}
+
+type Option func(r *Room)
+
+// WithRestoreWindow задаёт окно восстановления удалённых комнат.
+func WithRestoreWindow(d time.Duration) Option {
+	return func(r *Room) {
+		if d > 0 {
+			r.restoreWindow = d
+		}
+	}
 }
 
-func NewModelRoom(s storePG) *Room {
-	return &Room{
-		s,
+func NewModelRoom(s storePG, opts ...Option) *Room {
+	r := &Room{
+		storePG:       s,
+		restoreWindow: DefaultRestoreWindow,
+		sessionTTL:    DefaultSessionTTL,
 	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
 }
 
-func (r *Room) CreateRoom(ctx context.Context) (string, error) {
+func (r *Room) CreateRoom(ctx context.Context, meta RoomMeta) (_ CreatedRoom, err error) {
+	ctx, span := startSpan(ctx, "CreateRoom")
+	defer func() { endSpan(span, err) }()
+
+	meta, err = meta.normalize()
+	if err != nil {
+		return CreatedRoom{}, err
+	}
+
+	token, hash, err := newToken()
+	if err != nil {
+		return CreatedRoom{}, errors.Wrap(err, "CreateRoom model err")
+	}
+
 	id := uuid.NewString()
 
-	err := r.CreateRoomById(ctx, id)
+	err = r.CreateRoomById(ctx, id, meta, hash)
 	if err != nil {
-		return "", errors.Wrap(err, "CreateRoom model err")
+		return CreatedRoom{}, errors.Wrap(err, "CreateRoom model err")
 	}
 
-	return id, nil
+	return CreatedRoom{ID: id, OwnerToken: token}, nil
 }
 
-func (r *Room) DeleteRoom(ctx context.Context, id openapi_types.UUID) error {
+func (r *Room) DeleteRoom(ctx context.Context, id openapi_types.UUID) (err error) {
+	ctx, span := startSpan(ctx, "DeleteRoom")
+	defer func() { endSpan(span, err) }()
+
 	strID := id.String()
 
-	err := r.DeleteRoomById(ctx, strID)
+	err = r.DeleteRoomById(ctx, strID)
 	if err != nil {
 		return errors.Wrap(err, "DeleteRoom model err")
 	}
@@ -47,7 +120,10 @@ func (r *Room) DeleteRoom(ctx context.Context, id openapi_types.UUID) error {
 	return nil
 }
 
-func (r *Room) RoomExistsUUID(ctx context.Context, roomID openapi_types.UUID) (bool, error) {
+func (r *Room) RoomExistsUUID(ctx context.Context, roomID openapi_types.UUID) (_ bool, err error) {
+	ctx, span := startSpan(ctx, "RoomExistsUUID")
+	defer func() { endSpan(span, err) }()
+
 	id := roomID.String()
 
 	exists, err := r.RoomExists(ctx, id)
@@ -11,6 +11,7 @@ package model
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -38,18 +39,121 @@ func (m *MockstorePG) EXPECT() *MockstorePGMockRecorder {
 	return m.recorder
 }
 
+// ArchiveRooms mocks base method.
+func (m *MockstorePG) ArchiveRooms(ctx context.Context, ids []string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ArchiveRooms", ctx, ids)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ArchiveRooms indicates an expected call of ArchiveRooms.
+func (mr *MockstorePGMockRecorder) ArchiveRooms(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ArchiveRooms", reflect.TypeOf((*MockstorePG)(nil).ArchiveRooms), ctx, ids)
+}
+
+// CountRooms mocks base method.
+func (m *MockstorePG) CountRooms(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountRooms", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountRooms indicates an expected call of CountRooms.
+func (mr *MockstorePGMockRecorder) CountRooms(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountRooms", reflect.TypeOf((*MockstorePG)(nil).CountRooms), ctx)
+}
+
+// CountRoomsByTenant mocks base method.
+func (m *MockstorePG) CountRoomsByTenant(ctx context.Context) (map[string]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountRoomsByTenant", ctx)
+	ret0, _ := ret[0].(map[string]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountRoomsByTenant indicates an expected call of CountRoomsByTenant.
+func (mr *MockstorePGMockRecorder) CountRoomsByTenant(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountRoomsByTenant", reflect.TypeOf((*MockstorePG)(nil).CountRoomsByTenant), ctx)
+}
+
 // CreateRoomById mocks base method.
-func (m *MockstorePG) CreateRoomById(ctx context.Context, id string) error {
+func (m *MockstorePG) CreateRoomById(ctx context.Context, id string, meta RoomMeta, ownerHash []byte) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateRoomById", ctx, id)
+	ret := m.ctrl.Call(m, "CreateRoomById", ctx, id, meta, ownerHash)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateRoomById indicates an expected call of CreateRoomById.
-func (mr *MockstorePGMockRecorder) CreateRoomById(ctx, id any) *gomock.Call {
+func (mr *MockstorePGMockRecorder) CreateRoomById(ctx, id, meta, ownerHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRoomById", reflect.TypeOf((*MockstorePG)(nil).CreateRoomById), ctx, id, meta, ownerHash)
+}
+
+// CreateSession mocks base method.
+func (m *MockstorePG) CreateSession(ctx context.Context, tokenHash []byte, userID string, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSession", ctx, tokenHash, userID, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSession indicates an expected call of CreateSession.
+func (mr *MockstorePGMockRecorder) CreateSession(ctx, tokenHash, userID, expiresAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockstorePG)(nil).CreateSession), ctx, tokenHash, userID, expiresAt)
+}
+
+// CreateTenant mocks base method.
+func (m *MockstorePG) CreateTenant(ctx context.Context, tenant Tenant, keyHash []byte) (Tenant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTenant", ctx, tenant, keyHash)
+	ret0, _ := ret[0].(Tenant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTenant indicates an expected call of CreateTenant.
+func (mr *MockstorePGMockRecorder) CreateTenant(ctx, tenant, keyHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTenant", reflect.TypeOf((*MockstorePG)(nil).CreateTenant), ctx, tenant, keyHash)
+}
+
+// CreateUser mocks base method.
+func (m *MockstorePG) CreateUser(ctx context.Context, user User, passwordHash string) (User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", ctx, user, passwordHash)
+	ret0, _ := ret[0].(User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockstorePGMockRecorder) CreateUser(ctx, user, passwordHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockstorePG)(nil).CreateUser), ctx, user, passwordHash)
+}
+
+// DeleteExpiredSessions mocks base method.
+func (m *MockstorePG) DeleteExpiredSessions(ctx context.Context, userID string, before time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredSessions", ctx, userID, before)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpiredSessions indicates an expected call of DeleteExpiredSessions.
+func (mr *MockstorePGMockRecorder) DeleteExpiredSessions(ctx, userID, before any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRoomById", reflect.TypeOf((*MockstorePG)(nil).CreateRoomById), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredSessions", reflect.TypeOf((*MockstorePG)(nil).DeleteExpiredSessions), ctx, userID, before)
 }
 
 // DeleteRoomById mocks base method.
@@ -66,6 +170,153 @@ func (mr *MockstorePGMockRecorder) DeleteRoomById(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRoomById", reflect.TypeOf((*MockstorePG)(nil).DeleteRoomById), ctx, id)
 }
 
+// DeleteSession mocks base method.
+func (m *MockstorePG) DeleteSession(ctx context.Context, tokenHash []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSession", ctx, tokenHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSession indicates an expected call of DeleteSession.
+func (mr *MockstorePGMockRecorder) DeleteSession(ctx, tokenHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSession", reflect.TypeOf((*MockstorePG)(nil).DeleteSession), ctx, tokenHash)
+}
+
+// DeletedRooms mocks base method.
+func (m *MockstorePG) DeletedRooms(ctx context.Context, before time.Time, limit int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletedRooms", ctx, before, limit)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeletedRooms indicates an expected call of DeletedRooms.
+func (mr *MockstorePGMockRecorder) DeletedRooms(ctx, before, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletedRooms", reflect.TypeOf((*MockstorePG)(nil).DeletedRooms), ctx, before, limit)
+}
+
+// ExpiringRooms mocks base method.
+func (m *MockstorePG) ExpiringRooms(ctx context.Context, before time.Time, limit int) ([]RoomInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpiringRooms", ctx, before, limit)
+	ret0, _ := ret[0].([]RoomInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpiringRooms indicates an expected call of ExpiringRooms.
+func (mr *MockstorePGMockRecorder) ExpiringRooms(ctx, before, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpiringRooms", reflect.TypeOf((*MockstorePG)(nil).ExpiringRooms), ctx, before, limit)
+}
+
+// IdleRooms mocks base method.
+func (m *MockstorePG) IdleRooms(ctx context.Context, before time.Time, exclude []string, limit int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IdleRooms", ctx, before, exclude, limit)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IdleRooms indicates an expected call of IdleRooms.
+func (mr *MockstorePGMockRecorder) IdleRooms(ctx, before, exclude, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IdleRooms", reflect.TypeOf((*MockstorePG)(nil).IdleRooms), ctx, before, exclude, limit)
+}
+
+// ListRooms mocks base method.
+func (m *MockstorePG) ListRooms(ctx context.Context, filter RoomFilter) ([]RoomInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRooms", ctx, filter)
+	ret0, _ := ret[0].([]RoomInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRooms indicates an expected call of ListRooms.
+func (mr *MockstorePGMockRecorder) ListRooms(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRooms", reflect.TypeOf((*MockstorePG)(nil).ListRooms), ctx, filter)
+}
+
+// ListTenants mocks base method.
+func (m *MockstorePG) ListTenants(ctx context.Context) ([]Tenant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTenants", ctx)
+	ret0, _ := ret[0].([]Tenant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTenants indicates an expected call of ListTenants.
+func (mr *MockstorePGMockRecorder) ListTenants(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTenants", reflect.TypeOf((*MockstorePG)(nil).ListTenants), ctx)
+}
+
+// Ping mocks base method.
+func (m *MockstorePG) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockstorePGMockRecorder) Ping(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockstorePG)(nil).Ping), ctx)
+}
+
+// PurgeRooms mocks base method.
+func (m *MockstorePG) PurgeRooms(ctx context.Context, ids []string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeRooms", ctx, ids)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeRooms indicates an expected call of PurgeRooms.
+func (mr *MockstorePGMockRecorder) PurgeRooms(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeRooms", reflect.TypeOf((*MockstorePG)(nil).PurgeRooms), ctx, ids)
+}
+
+// RestoreRoomById mocks base method.
+func (m *MockstorePG) RestoreRoomById(ctx context.Context, id string, deletedAfter time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreRoomById", ctx, id, deletedAfter)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreRoomById indicates an expected call of RestoreRoomById.
+func (mr *MockstorePGMockRecorder) RestoreRoomById(ctx, id, deletedAfter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreRoomById", reflect.TypeOf((*MockstorePG)(nil).RestoreRoomById), ctx, id, deletedAfter)
+}
+
+// RoomCreatedBy mocks base method.
+func (m *MockstorePG) RoomCreatedBy(ctx context.Context, id string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RoomCreatedBy", ctx, id)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RoomCreatedBy indicates an expected call of RoomCreatedBy.
+func (mr *MockstorePGMockRecorder) RoomCreatedBy(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RoomCreatedBy", reflect.TypeOf((*MockstorePG)(nil).RoomCreatedBy), ctx, id)
+}
+
 // RoomExists mocks base method.
 func (m *MockstorePG) RoomExists(ctx context.Context, id string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -80,3 +331,139 @@ func (mr *MockstorePGMockRecorder) RoomExists(ctx, id any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RoomExists", reflect.TypeOf((*MockstorePG)(nil).RoomExists), ctx, id)
 }
+
+// RoomOwnerHash mocks base method.
+func (m *MockstorePG) RoomOwnerHash(ctx context.Context, id string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RoomOwnerHash", ctx, id)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RoomOwnerHash indicates an expected call of RoomOwnerHash.
+func (mr *MockstorePGMockRecorder) RoomOwnerHash(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RoomOwnerHash", reflect.TypeOf((*MockstorePG)(nil).RoomOwnerHash), ctx, id)
+}
+
+// SaveChatMessage mocks base method.
+func (m *MockstorePG) SaveChatMessage(ctx context.Context, msg ChatMessage) (ChatMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveChatMessage", ctx, msg)
+	ret0, _ := ret[0].(ChatMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveChatMessage indicates an expected call of SaveChatMessage.
+func (mr *MockstorePGMockRecorder) SaveChatMessage(ctx, msg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveChatMessage", reflect.TypeOf((*MockstorePG)(nil).SaveChatMessage), ctx, msg)
+}
+
+// SearchChat mocks base method.
+func (m *MockstorePG) SearchChat(ctx context.Context, roomID, query string, limit int) ([]SearchHit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchChat", ctx, roomID, query, limit)
+	ret0, _ := ret[0].([]SearchHit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchChat indicates an expected call of SearchChat.
+func (mr *MockstorePGMockRecorder) SearchChat(ctx, roomID, query, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchChat", reflect.TypeOf((*MockstorePG)(nil).SearchChat), ctx, roomID, query, limit)
+}
+
+// SearchRooms mocks base method.
+func (m *MockstorePG) SearchRooms(ctx context.Context, query string, limit int) ([]SearchHit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchRooms", ctx, query, limit)
+	ret0, _ := ret[0].([]SearchHit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchRooms indicates an expected call of SearchRooms.
+func (mr *MockstorePGMockRecorder) SearchRooms(ctx, query, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchRooms", reflect.TypeOf((*MockstorePG)(nil).SearchRooms), ctx, query, limit)
+}
+
+// SessionByHash mocks base method.
+func (m *MockstorePG) SessionByHash(ctx context.Context, tokenHash []byte) (User, time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SessionByHash", ctx, tokenHash)
+	ret0, _ := ret[0].(User)
+	ret1, _ := ret[1].(time.Time)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SessionByHash indicates an expected call of SessionByHash.
+func (mr *MockstorePGMockRecorder) SessionByHash(ctx, tokenHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SessionByHash", reflect.TypeOf((*MockstorePG)(nil).SessionByHash), ctx, tokenHash)
+}
+
+// TenantByKeyHash mocks base method.
+func (m *MockstorePG) TenantByKeyHash(ctx context.Context, keyHash []byte) (Tenant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TenantByKeyHash", ctx, keyHash)
+	ret0, _ := ret[0].(Tenant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TenantByKeyHash indicates an expected call of TenantByKeyHash.
+func (mr *MockstorePGMockRecorder) TenantByKeyHash(ctx, keyHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TenantByKeyHash", reflect.TypeOf((*MockstorePG)(nil).TenantByKeyHash), ctx, keyHash)
+}
+
+// TouchRoom mocks base method.
+func (m *MockstorePG) TouchRoom(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchRoom", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchRoom indicates an expected call of TouchRoom.
+func (mr *MockstorePGMockRecorder) TouchRoom(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchRoom", reflect.TypeOf((*MockstorePG)(nil).TouchRoom), ctx, id)
+}
+
+// UpdateTenantQuotas mocks base method.
+func (m *MockstorePG) UpdateTenantQuotas(ctx context.Context, name string, quotas TenantQuotas) (Tenant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTenantQuotas", ctx, name, quotas)
+	ret0, _ := ret[0].(Tenant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTenantQuotas indicates an expected call of UpdateTenantQuotas.
+func (mr *MockstorePGMockRecorder) UpdateTenantQuotas(ctx, name, quotas any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTenantQuotas", reflect.TypeOf((*MockstorePG)(nil).UpdateTenantQuotas), ctx, name, quotas)
+}
+
+// UserByName mocks base method.
+func (m *MockstorePG) UserByName(ctx context.Context, username string) (User, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserByName", ctx, username)
+	ret0, _ := ret[0].(User)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// UserByName indicates an expected call of UserByName.
+func (mr *MockstorePGMockRecorder) UserByName(ctx, username any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserByName", reflect.TypeOf((*MockstorePG)(nil).UserByName), ctx, username)
+}
@@ -24,28 +24,55 @@ func TestRoom_CreateRoom(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockStore.
 			EXPECT().
-			CreateRoomById(ctx, gomock.Any()).
+			CreateRoomById(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 			Return(nil)
 
-		id, err := r.CreateRoom(ctx)
+		room, err := r.CreateRoom(ctx, RoomMeta{})
 		require.NoError(t, err)
-		require.NotEmpty(t, id)
+		require.NotEmpty(t, room.ID)
+		require.NotEmpty(t, room.OwnerToken)
 
-		_, parseErr := uuid.Parse(id)
+		_, parseErr := uuid.Parse(room.ID)
 		require.NoError(t, parseErr)
 	})
 
 	t.Run("store error", func(t *testing.T) {
 		mockStore.
 			EXPECT().
-			CreateRoomById(ctx, gomock.Any()).
+			CreateRoomById(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 			Return(errors.New("db failure"))
 
-		id, err := r.CreateRoom(ctx)
-		require.Empty(t, id)
+		room, err := r.CreateRoom(ctx, RoomMeta{})
+		require.Empty(t, room)
 		require.Error(t, err)
 		require.True(t, strings.Contains(err.Error(), "CreateRoom model err"))
 	})
+
+	t.Run("normalized meta", func(t *testing.T) {
+		mockStore.
+			EXPECT().
+			CreateRoomById(gomock.Any(), gomock.Any(), RoomMeta{
+				Title:      "Movie night",
+				Visibility: VisibilityPublic,
+				Tags:       []string{"movies", "anime"},
+			}, gomock.Any()).
+			Return(nil)
+
+		_, err := r.CreateRoom(ctx, RoomMeta{
+			Title: "  Movie night ",
+			Tags:  []string{"Movies", "anime", "movies", " "},
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("invalid meta", func(t *testing.T) {
+		room, err := r.CreateRoom(ctx, RoomMeta{Visibility: "secret"})
+		require.Empty(t, room)
+		require.ErrorIs(t, err, ErrInvalidArgument)
+
+		_, err = r.CreateRoom(ctx, RoomMeta{Title: strings.Repeat("a", maxTitleLen+1)})
+		require.ErrorIs(t, err, ErrInvalidArgument)
+	})
 }
 
 func TestRoom_DeleteRoom(t *testing.T) {
@@ -64,7 +91,7 @@ func TestRoom_DeleteRoom(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockStore.
 			EXPECT().
-			DeleteRoomById(ctx, rawID).
+			DeleteRoomById(gomock.Any(), rawID).
 			Return(nil)
 
 		err = r.DeleteRoom(ctx, apiID)
@@ -74,7 +101,7 @@ func TestRoom_DeleteRoom(t *testing.T) {
 	t.Run("store error", func(t *testing.T) {
 		mockStore.
 			EXPECT().
-			DeleteRoomById(ctx, rawID).
+			DeleteRoomById(gomock.Any(), rawID).
 			Return(errors.New("not found"))
 
 		err = r.DeleteRoom(ctx, apiID)
@@ -98,7 +125,7 @@ func TestRoom_RoomExistsUUID(t *testing.T) {
 	t.Run("exists true", func(t *testing.T) {
 		mockStore.
 			EXPECT().
-			RoomExists(ctx, rawID).
+			RoomExists(gomock.Any(), rawID).
 			Return(true, nil)
 
 		ok, err := r.RoomExistsUUID(ctx, apiID)
@@ -109,7 +136,7 @@ func TestRoom_RoomExistsUUID(t *testing.T) {
 	t.Run("exists false", func(t *testing.T) {
 		mockStore.
 			EXPECT().
-			RoomExists(ctx, rawID).
+			RoomExists(gomock.Any(), rawID).
 			Return(false, nil)
 
 		ok, err := r.RoomExistsUUID(ctx, apiID)
@@ -120,7 +147,7 @@ func TestRoom_RoomExistsUUID(t *testing.T) {
 	t.Run("store error", func(t *testing.T) {
 		mockStore.
 			EXPECT().
-			RoomExists(ctx, rawID).
+			RoomExists(gomock.Any(), rawID).
 			Return(false, assert.AnError)
 
 		ok, err := r.RoomExistsUUID(ctx, apiID)
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+
+	"github.com/pkg/errors"
+)
+
+const tokenBytes = 32
+
+// CreatedRoom — результат создания комнаты. OwnerToken отдаётся клиенту
+// один раз, в БД хранится только его хеш.
+type CreatedRoom struct {
+	ID         string
+	OwnerToken string
+}
+
+// newToken создаёт случайный секрет (токен владельца, токен сессии)
+// и его хеш для хранения в БД.
+func newToken() (string, []byte, error) {
+	buf := make([]byte, tokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", nil, errors.Wrap(err, "generate token")
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(buf)
+
+	return token, hashToken(token), nil
+}
+
+func hashToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
+
+// AuthorizeOwner проверяет, что token — секрет владельца комнаты.
+func (r *Room) AuthorizeOwner(ctx context.Context, roomID string, token string) (err error) {
+	ctx, span := startSpan(ctx, "AuthorizeOwner")
+	defer func() { endSpan(span, err) }()
+
+	if token == "" {
+		return errors.Wrap(ErrForbidden, "owner token required")
+	}
+
+	hash, err := r.RoomOwnerHash(ctx, roomID)
+	if err != nil {
+		return errors.Wrap(err, "AuthorizeOwner model err")
+	}
+
+	if len(hash) == 0 || subtle.ConstantTimeCompare(hash, hashToken(token)) != 1 {
+		return errors.Wrap(ErrForbidden, "invalid owner token")
+	}
+
+	return nil
+}
+
+// AuthorizeManager пускает к управлению комнатой (удаление, архив,
+// восстановление, поиск по чату) её владельца: по токену владельца или по учётной
+// записи user, создавшей комнату.
+func (r *Room) AuthorizeManager(ctx context.Context, roomID, token, user string) (err error) {
+	ctx, span := startSpan(ctx, "AuthorizeManager")
+	defer func() { endSpan(span, err) }()
+
+	if user != "" {
+		createdBy, err := r.RoomCreatedBy(ctx, roomID)
+		if err != nil {
+			return errors.Wrap(err, "AuthorizeManager model err")
+		}
+		if createdBy == user {
+			return nil
+		}
+	}
+
+	return r.AuthorizeOwner(ctx, roomID, token)
+}
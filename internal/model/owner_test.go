@@ -0,0 +1,50 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRoom_AuthorizeManager(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := NewMockstorePG(ctrl)
+	r := NewModelRoom(mockStore)
+
+	roomID := uuid.NewString()
+
+	t.Run("creator", func(t *testing.T) {
+		mockStore.EXPECT().RoomCreatedBy(gomock.Any(), roomID).Return("alice", nil)
+
+		require.NoError(t, r.AuthorizeManager(ctx, roomID, "", "alice"))
+	})
+
+	t.Run("owner token", func(t *testing.T) {
+		mockStore.EXPECT().RoomOwnerHash(gomock.Any(), roomID).Return(hashToken("secret"), nil)
+
+		require.NoError(t, r.AuthorizeManager(ctx, roomID, "secret", ""))
+	})
+
+	t.Run("other user without token", func(t *testing.T) {
+		mockStore.EXPECT().RoomCreatedBy(gomock.Any(), roomID).Return("alice", nil)
+
+		err := r.AuthorizeManager(ctx, roomID, "", "mallory")
+		require.ErrorIs(t, err, ErrForbidden)
+	})
+
+	t.Run("anonymous without token", func(t *testing.T) {
+		require.ErrorIs(t, r.AuthorizeManager(ctx, roomID, "", ""), ErrForbidden)
+	})
+
+	t.Run("wrong token", func(t *testing.T) {
+		mockStore.EXPECT().RoomOwnerHash(gomock.Any(), roomID).Return(hashToken("secret"), nil)
+
+		require.ErrorIs(t, r.AuthorizeManager(ctx, roomID, "guess", ""), ErrForbidden)
+	})
+}
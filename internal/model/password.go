@@ -0,0 +1,101 @@
+package model
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/argon2"
+)
+
+// argonParams — параметры argon2id. Они записываются в сам хеш,
+// поэтому их можно усиливать, не ломая уже сохранённые пароли.
+type argonParams struct {
+	time    uint32
+	memory  uint32
+	threads uint8
+	saltLen int
+	keyLen  uint32
+}
+
+// passwordParams — рекомендация RFC 9106 для ограниченной памяти: t=3, m=64 МиБ.
+var passwordParams = argonParams{time: 3, memory: 64 * 1024, threads: 4, saltLen: 16, keyLen: 32}
+
+// hashSlots ограничивает число одновременных вычислений argon2id: каждое
+// занимает passwordParams.memory памяти, и без ограничения поток входов
+// и регистраций исчерпал бы её.
+var hashSlots = make(chan struct{}, runtime.GOMAXPROCS(0))
+
+// hashSlotWait — сколько ждать свободного слота, прежде чем отказать.
+const hashSlotWait = 500 * time.Millisecond
+
+// acquireHashSlot занимает слот для хеширования пароля; release его освобождает.
+func acquireHashSlot(ctx context.Context) (release func(), err error) {
+	timer := time.NewTimer(hashSlotWait)
+	defer timer.Stop()
+
+	select {
+	case hashSlots <- struct{}{}:
+		return func() { <-hashSlots }, nil
+	case <-timer.C:
+		return nil, errors.Wrap(ErrRateLimited, "too many password checks in progress, retry later")
+	case <-ctx.Done():
+		return nil, errors.Wrap(ctx.Err(), "wait for password check")
+	}
+}
+
+// hashPassword возвращает хеш в формате PHC:
+// $argon2id$v=19$m=65536,t=3,p=4$<соль>$<ключ>.
+func hashPassword(password string) (string, error) {
+	p := passwordParams
+
+	salt := make([]byte, p.saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", errors.Wrap(err, "generate salt")
+	}
+
+	key := argon2.IDKey([]byte(password), salt, p.time, p.memory, p.threads, p.keyLen)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.memory, p.time, p.threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// checkPassword сравнивает пароль с хешем за постоянное время.
+func checkPassword(password, encoded string) (bool, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return false, errors.New("unsupported password hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, errors.New("unsupported argon2 version")
+	}
+
+	var p argonParams
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.time, &p.threads); err != nil {
+		return false, errors.Wrap(err, "parse argon2 params")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, errors.Wrap(err, "decode salt")
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false, errors.Wrap(err, "decode key")
+	}
+
+	got := argon2.IDKey([]byte(password), salt, p.time, p.memory, p.threads, uint32(len(want)))
+
+	return subtle.ConstantTimeCompare(got, want) == 1, nil
+}
@@ -0,0 +1,92 @@
+package model
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	SearchKindRoom    = "room"
+	SearchKindMessage = "message"
+)
+
+// HighlightStart и HighlightStop обрамляют совпадения в SearchHit.Snippet.
+// Это символы, которые не встречаются в обычном тексте, поэтому клиентский
+// слой может безопасно экранировать фрагмент и заменить их на разметку.
+const (
+	HighlightStart = "⟦"
+	HighlightStop  = "⟧"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 50
+	maxSearchQueryLen  = 200
+)
+
+// SearchParams — параметры полнотекстового поиска.
+// Если RoomID задан, поиск идёт по истории чата комнаты и доступен
+// владельцу: по OwnerToken или создателю User (в формате RoomMeta.CreatedBy).
+type SearchParams struct {
+	Query      string
+	RoomID     string
+	OwnerToken string
+	User       string
+	Limit      int
+}
+
+// SearchHit — найденная комната или сообщение чата.
+type SearchHit struct {
+	Kind      string
+	RoomID    string
+	MessageID int64
+	Title     string
+	Sender    string
+	Snippet   string
+	Rank      float32
+	CreatedAt time.Time
+}
+
+func (r *Room) Search(ctx context.Context, p SearchParams) (_ []SearchHit, err error) {
+	ctx, span := startSpan(ctx, "Search")
+	defer func() { endSpan(span, err) }()
+
+	p.Query = strings.TrimSpace(p.Query)
+	if p.Query == "" {
+		return nil, errors.Wrap(ErrInvalidArgument, "empty search query")
+	}
+	if utf8.RuneCountInString(p.Query) > maxSearchQueryLen {
+		return nil, errors.Wrapf(ErrInvalidArgument, "search query is longer than %d characters", maxSearchQueryLen)
+	}
+
+	switch {
+	case p.Limit == 0:
+		p.Limit = defaultSearchLimit
+	case p.Limit < 0 || p.Limit > maxSearchLimit:
+		return nil, errors.Wrapf(ErrInvalidArgument, "limit must be between 1 and %d", maxSearchLimit)
+	}
+
+	if p.RoomID == "" {
+		hits, err := r.SearchRooms(ctx, p.Query, p.Limit)
+		if err != nil {
+			return nil, errors.Wrap(err, "Search model err")
+		}
+
+		return hits, nil
+	}
+
+	if err := r.AuthorizeManager(ctx, p.RoomID, p.OwnerToken, p.User); err != nil {
+		return nil, err
+	}
+
+	hits, err := r.SearchChat(ctx, p.RoomID, p.Query, p.Limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "Search model err")
+	}
+
+	return hits, nil
+}
@@ -0,0 +1,134 @@
+package model
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRoom_Search(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := NewMockstorePG(ctrl)
+	r := NewModelRoom(mockStore)
+
+	roomID := uuid.NewString()
+	token := "owner-secret"
+
+	t.Run("public rooms", func(t *testing.T) {
+		hits := []SearchHit{{Kind: SearchKindRoom, RoomID: roomID, Snippet: "⟦movie⟧ night"}}
+		mockStore.
+			EXPECT().
+			SearchRooms(gomock.Any(), "movie", defaultSearchLimit).
+			Return(hits, nil)
+
+		got, err := r.Search(ctx, SearchParams{Query: " movie "})
+		require.NoError(t, err)
+		assert.Equal(t, hits, got)
+	})
+
+	t.Run("room chat for owner", func(t *testing.T) {
+		hits := []SearchHit{{Kind: SearchKindMessage, RoomID: roomID, MessageID: 7}}
+		mockStore.
+			EXPECT().
+			RoomOwnerHash(gomock.Any(), roomID).
+			Return(hashToken(token), nil)
+		mockStore.
+			EXPECT().
+			SearchChat(gomock.Any(), roomID, "hello", 5).
+			Return(hits, nil)
+
+		got, err := r.Search(ctx, SearchParams{Query: "hello", RoomID: roomID, OwnerToken: token, Limit: 5})
+		require.NoError(t, err)
+		assert.Equal(t, hits, got)
+	})
+
+	t.Run("room chat for creator without token", func(t *testing.T) {
+		hits := []SearchHit{{Kind: SearchKindMessage, RoomID: roomID, MessageID: 7}}
+		mockStore.
+			EXPECT().
+			RoomCreatedBy(gomock.Any(), roomID).
+			Return("local:alice", nil)
+		mockStore.
+			EXPECT().
+			SearchChat(gomock.Any(), roomID, "hello", defaultSearchLimit).
+			Return(hits, nil)
+
+		got, err := r.Search(ctx, SearchParams{Query: "hello", RoomID: roomID, User: "local:alice"})
+		require.NoError(t, err)
+		assert.Equal(t, hits, got)
+	})
+
+	t.Run("room chat for other user without token", func(t *testing.T) {
+		mockStore.
+			EXPECT().
+			RoomCreatedBy(gomock.Any(), roomID).
+			Return("local:alice", nil)
+
+		_, err := r.Search(ctx, SearchParams{Query: "hello", RoomID: roomID, User: "jwt:|alice"})
+		require.ErrorIs(t, err, ErrForbidden)
+	})
+
+	t.Run("room chat with wrong token", func(t *testing.T) {
+		mockStore.
+			EXPECT().
+			RoomOwnerHash(gomock.Any(), roomID).
+			Return(hashToken(token), nil)
+
+		_, err := r.Search(ctx, SearchParams{Query: "hello", RoomID: roomID, OwnerToken: "guess"})
+		require.ErrorIs(t, err, ErrForbidden)
+	})
+
+	t.Run("room chat without token", func(t *testing.T) {
+		_, err := r.Search(ctx, SearchParams{Query: "hello", RoomID: roomID})
+		require.ErrorIs(t, err, ErrForbidden)
+	})
+
+	t.Run("room without owner", func(t *testing.T) {
+		mockStore.
+			EXPECT().
+			RoomOwnerHash(gomock.Any(), roomID).
+			Return(nil, nil)
+
+		_, err := r.Search(ctx, SearchParams{Query: "hello", RoomID: roomID, OwnerToken: token})
+		require.ErrorIs(t, err, ErrForbidden)
+	})
+
+	t.Run("unknown room", func(t *testing.T) {
+		mockStore.
+			EXPECT().
+			RoomOwnerHash(gomock.Any(), roomID).
+			Return(nil, ErrNotFound)
+
+		_, err := r.Search(ctx, SearchParams{Query: "hello", RoomID: roomID, OwnerToken: token})
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		for _, p := range []SearchParams{
+			{Query: "  "},
+			{Query: strings.Repeat("a", maxSearchQueryLen+1)},
+			{Query: "a", Limit: maxSearchLimit + 1},
+		} {
+			_, err := r.Search(ctx, p)
+			assert.ErrorIs(t, err, ErrInvalidArgument)
+		}
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		mockStore.
+			EXPECT().
+			SearchRooms(gomock.Any(), "movie", defaultSearchLimit).
+			Return(nil, assert.AnError)
+
+		_, err := r.Search(ctx, SearchParams{Query: "movie"})
+		require.ErrorIs(t, err, assert.AnError)
+	})
+}
@@ -0,0 +1,120 @@
+package model
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// apiKeyPrefix помогает узнать ключ syncplay в логах и менеджерах секретов.
+const apiKeyPrefix = "spk_"
+
+var tenantNameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{1,63}$`)
+
+// Tenant — арендатор: продукт со своими комнатами, API-ключом и квотами.
+type Tenant struct {
+	ID        string
+	Name      string
+	Quotas    TenantQuotas
+	CreatedAt time.Time
+}
+
+// TenantQuotas — ограничения арендатора; 0 снимает ограничение.
+// MaxPeers считает участников во всех комнатах арендатора.
+type TenantQuotas struct {
+	MaxRooms       int
+	MaxPeers       int
+	MessagesPerMin int
+}
+
+func (q TenantQuotas) validate() error {
+	if q.MaxRooms < 0 || q.MaxPeers < 0 || q.MessagesPerMin < 0 {
+		return errors.Wrap(ErrInvalidArgument, "quotas must not be negative")
+	}
+
+	return nil
+}
+
+type tenantKey struct{}
+
+// WithTenant ограничивает запросы к хранилищу комнатами арендатора t.
+// Пустой ID — арендатор по умолчанию, которому принадлежат комнаты,
+// созданные без API-ключа.
+func WithTenant(ctx context.Context, t Tenant) context.Context {
+	return context.WithValue(ctx, tenantKey{}, t)
+}
+
+// TenantFrom возвращает арендатора запроса. ok = false — запрос не
+// ограничен арендатором: так работают janitor и команды CLI.
+func TenantFrom(ctx context.Context) (Tenant, bool) {
+	t, ok := ctx.Value(tenantKey{}).(Tenant)
+
+	return t, ok
+}
+
+// CreateTenant создаёт арендатора и возвращает его API-ключ; в БД
+// хранится только хеш, поэтому ключ показывается один раз.
+func (r *Room) CreateTenant(ctx context.Context, name string, q TenantQuotas) (_ Tenant, _ string, err error) {
+	ctx, span := startSpan(ctx, "CreateTenant")
+	defer func() { endSpan(span, err) }()
+
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !tenantNameRe.MatchString(name) {
+		return Tenant{}, "", errors.Wrap(ErrInvalidArgument,
+			"tenant name must be 2 to 64 characters: latin letters, digits, '.', '_' or '-'")
+	}
+	if err = q.validate(); err != nil {
+		return Tenant{}, "", err
+	}
+
+	token, _, err := newToken()
+	if err != nil {
+		return Tenant{}, "", errors.Wrap(err, "CreateTenant model err")
+	}
+	key := apiKeyPrefix + token
+
+	t, err := r.storePG.CreateTenant(ctx, Tenant{ID: uuid.NewString(), Name: name, Quotas: q}, hashToken(key))
+	if err != nil {
+		return Tenant{}, "", errors.Wrap(err, "CreateTenant model err")
+	}
+
+	return t, key, nil
+}
+
+// UpdateTenantQuotas меняет квоты арендатора; работающий сервер
+// подхватывает их при следующем запросе с ключом арендатора.
+func (r *Room) UpdateTenantQuotas(ctx context.Context, name string, q TenantQuotas) (_ Tenant, err error) {
+	ctx, span := startSpan(ctx, "UpdateTenantQuotas")
+	defer func() { endSpan(span, err) }()
+
+	if err = q.validate(); err != nil {
+		return Tenant{}, err
+	}
+
+	t, err := r.storePG.UpdateTenantQuotas(ctx, strings.ToLower(strings.TrimSpace(name)), q)
+	if err != nil {
+		return Tenant{}, errors.Wrap(err, "UpdateTenantQuotas model err")
+	}
+
+	return t, nil
+}
+
+// TenantByKey находит арендатора по API-ключу.
+func (r *Room) TenantByKey(ctx context.Context, key string) (_ Tenant, err error) {
+	ctx, span := startSpan(ctx, "TenantByKey")
+	defer func() { endSpan(span, err) }()
+
+	t, err := r.TenantByKeyHash(ctx, hashToken(key))
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return Tenant{}, errors.Wrap(ErrUnauthorized, "unknown api key")
+	case err != nil:
+		return Tenant{}, errors.Wrap(err, "TenantByKey model err")
+	}
+
+	return t, nil
+}
@@ -0,0 +1,116 @@
+package model
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRoom_CreateTenant(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := NewMockstorePG(ctrl)
+	r := NewModelRoom(mockStore)
+
+	t.Run("success", func(t *testing.T) {
+		var keyHash []byte
+		mockStore.
+			EXPECT().
+			CreateTenant(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, tenant Tenant, hash []byte) (Tenant, error) {
+				assert.Equal(t, "acme", tenant.Name)
+				assert.NotEmpty(t, tenant.ID)
+				assert.Equal(t, TenantQuotas{MaxRooms: 10}, tenant.Quotas)
+				keyHash = hash
+				return tenant, nil
+			})
+
+		tenant, key, err := r.CreateTenant(ctx, " Acme ", TenantQuotas{MaxRooms: 10})
+		require.NoError(t, err)
+		assert.Equal(t, "acme", tenant.Name)
+		assert.True(t, strings.HasPrefix(key, apiKeyPrefix))
+		// В хранилище уходит только хеш ключа
+		assert.Equal(t, hashToken(key), keyHash)
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		_, _, err := r.CreateTenant(ctx, "a", TenantQuotas{})
+		require.ErrorIs(t, err, ErrInvalidArgument)
+
+		_, _, err = r.CreateTenant(ctx, "acme", TenantQuotas{MaxPeers: -1})
+		require.ErrorIs(t, err, ErrInvalidArgument)
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		mockStore.
+			EXPECT().
+			CreateTenant(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(Tenant{}, errors.Wrap(ErrConflict, "tenant already exists"))
+
+		_, _, err := r.CreateTenant(ctx, "acme", TenantQuotas{})
+		require.ErrorIs(t, err, ErrConflict)
+	})
+}
+
+func TestRoom_TenantByKey(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := NewMockstorePG(ctrl)
+	r := NewModelRoom(mockStore)
+
+	t.Run("success", func(t *testing.T) {
+		mockStore.EXPECT().TenantByKeyHash(gomock.Any(), hashToken("spk_key")).Return(Tenant{ID: "id", Name: "acme"}, nil)
+
+		tenant, err := r.TenantByKey(ctx, "spk_key")
+		require.NoError(t, err)
+		assert.Equal(t, "acme", tenant.Name)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		mockStore.EXPECT().TenantByKeyHash(gomock.Any(), gomock.Any()).Return(Tenant{}, errors.Wrap(ErrNotFound, "tenant not found"))
+
+		_, err := r.TenantByKey(ctx, "spk_other")
+		require.ErrorIs(t, err, ErrUnauthorized)
+	})
+}
+
+func TestRoom_CreateRoomQuota(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := NewMockstorePG(ctrl)
+	r := NewModelRoom(mockStore)
+
+	ctx := WithTenant(context.Background(), Tenant{ID: "id", Name: "acme", Quotas: TenantQuotas{MaxRooms: 2}})
+
+	t.Run("under quota", func(t *testing.T) {
+		mockStore.EXPECT().CreateRoomById(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+		_, err := r.CreateRoom(ctx, RoomMeta{})
+		require.NoError(t, err)
+	})
+
+	t.Run("quota exceeded", func(t *testing.T) {
+		mockStore.EXPECT().CreateRoomById(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(errors.Wrap(ErrQuotaExceeded, "tenant room limit of 2 reached"))
+
+		_, err := r.CreateRoom(ctx, RoomMeta{})
+		require.ErrorIs(t, err, ErrQuotaExceeded)
+	})
+
+	t.Run("no quota", func(t *testing.T) {
+		mockStore.EXPECT().CreateRoomById(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+		_, err := r.CreateRoom(WithTenant(context.Background(), Tenant{}), RoomMeta{})
+		require.NoError(t, err)
+	})
+}
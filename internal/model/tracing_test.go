@@ -0,0 +1,59 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRoom_Spans(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := NewMockstorePG(ctrl)
+	r := NewModelRoom(mockStore)
+
+	var apiID openapi_types.UUID
+	require.NoError(t, apiID.Scan(uuid.NewString()))
+
+	// Хранилище получает контекст со span модели
+	mockStore.
+		EXPECT().
+		RoomExists(gomock.Any(), apiID.String()).
+		DoAndReturn(func(ctx context.Context, _ string) (bool, error) {
+			assert.True(t, trace.SpanFromContext(ctx).SpanContext().IsValid())
+			return true, nil
+		})
+	mockStore.
+		EXPECT().
+		TouchRoom(gomock.Any(), apiID.String()).
+		Return(errors.New("db failure"))
+
+	_, err := r.RoomExistsUUID(context.Background(), apiID)
+	require.NoError(t, err)
+	require.Error(t, r.TouchRoomUUID(context.Background(), apiID))
+
+	ended := spans.Ended()
+	require.Len(t, ended, 2)
+
+	assert.Equal(t, "model.Room.RoomExistsUUID", ended[0].Name())
+	assert.Equal(t, codes.Unset, ended[0].Status().Code)
+
+	assert.Equal(t, "model.Room.TouchRoomUUID", ended[1].Name())
+	assert.Equal(t, codes.Error, ended[1].Status().Code)
+	assert.Len(t, ended[1].Events(), 1, "ошибка записывается событием")
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/vpbuyanov/syncplay/internal/gen"
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+// sessionCookie — cookie с токеном сессии для браузерных клиентов.
+const sessionCookie = "syncplay_session"
+
+func (s *Server) Register(ctx echo.Context) error {
+	if !s.accounts {
+		return errors.Wrap(model.ErrNotFound, "accounts are disabled")
+	}
+
+	var body gen.RegisterJSONRequestBody
+	if err := ctx.Bind(&body); err != nil {
+		return errors.Wrap(model.ErrInvalidArgument, "invalid request body")
+	}
+
+	user, err := s.m.Register(ctx.Request().Context(), body.Username, body.Password)
+	if err != nil {
+		return err
+	}
+
+	res, err := userResponse(user)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusCreated, res)
+}
+
+func (s *Server) Login(ctx echo.Context) error {
+	if !s.accounts {
+		return errors.Wrap(model.ErrNotFound, "accounts are disabled")
+	}
+
+	var body gen.LoginJSONRequestBody
+	if err := ctx.Bind(&body); err != nil {
+		return errors.Wrap(model.ErrInvalidArgument, "invalid request body")
+	}
+
+	session, err := s.m.Login(ctx.Request().Context(), body.Username, body.Password)
+	if err != nil {
+		return err
+	}
+
+	user, err := userResponse(session.User)
+	if err != nil {
+		return err
+	}
+
+	ctx.SetCookie(newSessionCookie(ctx, session.Token, session.ExpiresAt))
+
+	return ctx.JSON(http.StatusOK, gen.Session{
+		Token:     session.Token,
+		ExpiresAt: session.ExpiresAt,
+		User:      user,
+	})
+}
+
+// Logout завершает сессию из Authorization или cookie и стирает cookie.
+func (s *Server) Logout(ctx echo.Context) error {
+	if !s.accounts {
+		return errors.Wrap(model.ErrNotFound, "accounts are disabled")
+	}
+
+	if token := s.requestToken(ctx); token != "" {
+		if err := s.m.Logout(ctx.Request().Context(), token); err != nil {
+			return err
+		}
+	}
+
+	cookie := newSessionCookie(ctx, "", time.Unix(0, 0))
+	cookie.MaxAge = -1
+	ctx.SetCookie(cookie)
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// newSessionCookie недоступна скриптам и не уходит на сторонние сайты
+// в POST-запросах; Secure ставится, если клиент пришёл по HTTPS.
+func newSessionCookie(ctx echo.Context, token string, expiresAt time.Time) *http.Cookie {
+	return &http.Cookie{
+		Name:     sessionCookie,
+		Value:    token,
+		Path:     "/",
+		Expires:  expiresAt,
+		HttpOnly: true,
+		Secure:   ctx.Scheme() == "https",
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+func userResponse(user model.User) (gen.User, error) {
+	uid, err := uuid.Parse(user.ID)
+	if err != nil {
+		return gen.User{}, errors.Wrap(err, "can't return uuid")
+	}
+
+	return gen.User{
+		Id:        uid,
+		Username:  user.Username,
+		CreatedAt: user.CreatedAt,
+	}, nil
+}
@@ -0,0 +1,192 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/vpbuyanov/syncplay/internal/config"
+	"github.com/vpbuyanov/syncplay/internal/gen"
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+func TestServer_Accounts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockModel := NewMockmodelRoom(ctrl)
+	srv, err := NewServer(&config.Config{Auth: config.Auth{Accounts: true}}, mockModel)
+	require.NoError(t, err)
+
+	alice := model.User{ID: uuid.NewString(), Username: "alice", CreatedAt: time.Now().UTC()}
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	do := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		srv.e.ServeHTTP(rec, req)
+		return rec
+	}
+	post := func(path, body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		return req
+	}
+
+	t.Run("register", func(t *testing.T) {
+		mockModel.EXPECT().Register(gomock.Any(), "alice", "password1").Return(alice, nil)
+
+		rec := do(post("/api/v1/auth/register", `{"username":"alice","password":"password1"}`))
+		require.Equal(t, http.StatusCreated, rec.Code)
+
+		var res gen.User
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		assert.Equal(t, alice.ID, res.Id.String())
+		assert.Equal(t, "alice", res.Username)
+	})
+
+	t.Run("register conflict", func(t *testing.T) {
+		mockModel.EXPECT().Register(gomock.Any(), "alice", "password1").Return(model.User{}, errors.Wrap(model.ErrConflict, "taken"))
+
+		rec := do(post("/api/v1/auth/register", `{"username":"alice","password":"password1"}`))
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("login sets cookie", func(t *testing.T) {
+		mockModel.EXPECT().Login(gomock.Any(), "alice", "password1").
+			Return(model.Session{Token: "session-token", User: alice, ExpiresAt: expiresAt}, nil)
+
+		rec := do(post("/api/v1/auth/login", `{"username":"alice","password":"password1"}`))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var res gen.Session
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		assert.Equal(t, "session-token", res.Token)
+		assert.Equal(t, "alice", res.User.Username)
+
+		cookies := rec.Result().Cookies()
+		require.Len(t, cookies, 1)
+		assert.Equal(t, sessionCookie, cookies[0].Name)
+		assert.Equal(t, "session-token", cookies[0].Value)
+		assert.True(t, cookies[0].HttpOnly)
+		assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+	})
+
+	t.Run("login wrong password", func(t *testing.T) {
+		mockModel.EXPECT().Login(gomock.Any(), "alice", "nope").
+			Return(model.Session{}, errors.Wrap(model.ErrUnauthorized, "invalid username or password"))
+
+		rec := do(post("/api/v1/auth/login", `{"username":"alice","password":"nope"}`))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("my rooms by cookie", func(t *testing.T) {
+		mockModel.EXPECT().SessionUser(gomock.Any(), "session-token").Return(alice, expiresAt, nil)
+		mockModel.EXPECT().ListRooms(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ any, p model.ListRoomsParams) (model.RoomPage, error) {
+				assert.Equal(t, "local:"+alice.ID, p.CreatedBy)
+				return model.RoomPage{}, nil
+			})
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/rooms?mine=true", nil)
+		req.AddCookie(&http.Cookie{Name: sessionCookie, Value: "session-token"})
+		assert.Equal(t, http.StatusOK, do(req).Code)
+	})
+
+	t.Run("my rooms anonymously", func(t *testing.T) {
+		rec := do(httptest.NewRequest(http.MethodGet, "/api/v1/rooms?mine=true", nil))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("created by session user", func(t *testing.T) {
+		mockModel.EXPECT().SessionUser(gomock.Any(), "session-token").Return(alice, expiresAt, nil)
+		mockModel.EXPECT().CreateRoom(gomock.Any(), model.RoomMeta{CreatedBy: "local:" + alice.ID}).
+			Return(model.CreatedRoom{ID: uuid.NewString(), OwnerToken: "owner"}, nil)
+
+		req := post("/api/v1/rooms", `{}`)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer session-token")
+		assert.Equal(t, http.StatusOK, do(req).Code)
+	})
+
+	t.Run("expired session", func(t *testing.T) {
+		mockModel.EXPECT().SessionUser(gomock.Any(), "stale").
+			Return(model.User{}, time.Time{}, errors.Wrap(model.ErrUnauthorized, "session expired"))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/rooms", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer stale")
+		rec := do(req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Header().Get(echo.HeaderWWWAuthenticate), "invalid_token")
+	})
+
+	t.Run("logout", func(t *testing.T) {
+		mockModel.EXPECT().Logout(gomock.Any(), "session-token").Return(nil)
+
+		req := post("/api/v1/auth/logout", "")
+		req.AddCookie(&http.Cookie{Name: sessionCookie, Value: "session-token"})
+		rec := do(req)
+		require.Equal(t, http.StatusNoContent, rec.Code)
+
+		cookies := rec.Result().Cookies()
+		require.Len(t, cookies, 1)
+		assert.Empty(t, cookies[0].Value)
+		assert.Negative(t, cookies[0].MaxAge)
+	})
+}
+
+func TestServer_AccountsDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	srv, err := NewServer(&config.Config{}, NewMockmodelRoom(ctrl))
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", strings.NewReader(`{"username":"alice","password":"password1"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	srv.e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestServer_AccountsWithJWT(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockModel := NewMockmodelRoom(ctrl)
+	srv, err := NewServer(&config.Config{Auth: config.Auth{Secret: testSecret, Accounts: true}}, mockModel)
+	require.NoError(t, err)
+
+	// sub из JWT совпадает с id встроенной учётной записи
+	alice := model.User{ID: uuid.NewString(), Username: "alice", CreatedAt: time.Now().UTC()}
+	mockModel.EXPECT().SessionUser(gomock.Any(), "session-token").Return(alice, time.Now().Add(time.Hour), nil)
+
+	var owners []string
+	mockModel.EXPECT().ListRooms(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ any, p model.ListRoomsParams) (model.RoomPage, error) {
+			owners = append(owners, p.CreatedBy)
+			return model.RoomPage{}, nil
+		}).
+		Times(2)
+
+	for _, token := range []string{"session-token", testToken(t, alice.ID)} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/rooms?mine=true", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		rec := httptest.NewRecorder()
+		srv.e.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	require.Len(t, owners, 2)
+	assert.Equal(t, "local:"+alice.ID, owners[0])
+	assert.Equal(t, "jwt:|"+alice.ID, owners[1])
+}
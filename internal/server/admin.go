@@ -0,0 +1,328 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+// closeKicked — код закрытия WS: участника отключил администратор.
+const closeKicked = 4002
+
+// maxAnnouncementLen — максимальная длина объявления в байтах.
+const maxAnnouncementLen = 2000
+
+type sessionInfo struct {
+	RoomID    string    `json:"room_id"`
+	Peers     int       `json:"peers"`
+	StartedAt time.Time `json:"started_at"`
+	// ConnectedAt — время подключения участников, от ранних к поздним
+	ConnectedAt []time.Time `json:"connected_at"`
+}
+
+type peerInfo struct {
+	ID          string    `json:"id"`
+	User        string    `json:"user,omitempty"`
+	Tenant      string    `json:"tenant,omitempty"`
+	RemoteIP    string    `json:"remote_ip"`
+	ConnectedAt time.Time `json:"connected_at"`
+	// PendingWrites — сообщения, которые сервер сейчас пишет участнику:
+	// текущая запись и ждущие её окончания. Очереди исходящих нет, поэтому
+	// обычно это 0 или 1; больше — клиент не успевает читать
+	PendingWrites int `json:"pending_writes"`
+}
+
+type sessionDetail struct {
+	sessionInfo
+	PeerList []peerInfo `json:"peer_list"`
+}
+
+type announcementPayload struct {
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type announceRequest struct {
+	// RoomID — комната объявления; пусто — все активные комнаты
+	RoomID string `json:"room_id"`
+	Text   string `json:"text"`
+}
+
+// dashboardPaths — маршруты дашборда, открытые для Basic-авторизации.
+var dashboardPaths = map[string]bool{
+	"/admin/dashboard":        true,
+	"/admin/dashboard/events": true,
+}
+
+// adminHandler — API /admin на листенере server.metrics_addr: живые
+// сессии комнат, отключение участников, закрытие комнат, объявления
+// и HTML-дашборд.
+func (s *Server) adminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/sessions", s.adminListSessions)
+	mux.HandleFunc("GET /admin/sessions/{room}", s.adminGetSession)
+	mux.HandleFunc("DELETE /admin/sessions/{room}", s.adminCloseSession)
+	mux.HandleFunc("DELETE /admin/sessions/{room}/peers/{peer}", s.adminDisconnectPeer)
+	mux.HandleFunc("POST /admin/announcements", s.adminAnnounce)
+	mux.HandleFunc("GET "+adminTenantsPath, s.handleAdminTenants)
+	mux.HandleFunc("GET /admin/dashboard", s.adminDashboard)
+	mux.HandleFunc("GET /admin/dashboard/events", s.adminDashboardEvents)
+
+	return s.adminAuth(mux)
+}
+
+// adminAuth пускает в /admin по токену server.admin_token или по
+// клиентскому сертификату, проверенному при mTLS. Браузеру для дашборда
+// токен можно передать паролем Basic-авторизации, имя не проверяется.
+// Basic принимается только на GET-маршрутах дашборда: браузер подставляет
+// его и в межсайтовые запросы, и тогда чужая страница смогла бы, например,
+// разослать объявление.
+func (s *Server) adminAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if s.adminToken == "" {
+			writeProblem(w, http.StatusForbidden, model.CodeForbidden,
+				"admin API requires server.admin_token or server.tls.client_ca")
+			return
+		}
+
+		basic := r.Method == http.MethodGet && dashboardPaths[r.URL.Path]
+
+		scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+		if !strings.EqualFold(scheme, "Bearer") {
+			token = ""
+			if basic {
+				_, token, _ = r.BasicAuth()
+			}
+		}
+
+		if subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), []byte(s.adminToken)) != 1 {
+			w.Header().Add("WWW-Authenticate", "Bearer")
+			if basic {
+				w.Header().Add("WWW-Authenticate", `Basic realm="syncplay admin"`)
+			}
+			writeProblem(w, http.StatusUnauthorized, model.CodeUnauthorized, "invalid admin token")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) adminListSessions(w http.ResponseWriter, _ *http.Request) {
+	roomsMu.Lock()
+	sessions := make(map[openapi_types.UUID]*roomSession, len(rooms))
+	for id, sess := range rooms {
+		sessions[id] = sess
+	}
+	roomsMu.Unlock()
+
+	res := make([]sessionInfo, 0, len(sessions))
+	for id, sess := range sessions {
+		info, _ := describeSession(id, sess)
+		if info.Peers > 0 {
+			res = append(res, info)
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].StartedAt.Before(res[j].StartedAt) })
+
+	writeJSON(w, http.StatusOK, map[string]any{"sessions": res})
+}
+
+func (s *Server) adminGetSession(w http.ResponseWriter, r *http.Request) {
+	id, sess, ok := lookupSession(w, r)
+	if !ok {
+		return
+	}
+
+	info, peers := describeSession(id, sess)
+	writeJSON(w, http.StatusOK, sessionDetail{sessionInfo: info, PeerList: peers})
+}
+
+func (s *Server) adminCloseSession(w http.ResponseWriter, r *http.Request) {
+	id, _, ok := lookupSession(w, r)
+	if !ok {
+		return
+	}
+
+	reason := r.URL.Query().Get("reason")
+	if reason == "" {
+		reason = "closed by administrator"
+	}
+
+	s.CloseRoom(id.String(), reason)
+	slog.InfoContext(r.Context(), "admin closed room", "room_id", id, "reason", reason)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) adminDisconnectPeer(w http.ResponseWriter, r *http.Request) {
+	id, sess, ok := lookupSession(w, r)
+	if !ok {
+		return
+	}
+
+	peerID := r.PathValue("peer")
+
+	sess.Session.Lock()
+	p := sess.Peers[peerID]
+	sess.Session.Unlock()
+
+	if p == nil {
+		writeProblem(w, http.StatusNotFound, model.CodeNotFound, "peer not found")
+		return
+	}
+
+	// Цикл чтения участника завершится и разошлёт peer-left
+	p.close(closeKicked, "disconnected by administrator")
+	slog.InfoContext(r.Context(), "admin disconnected peer", "room_id", id, "peer_id", peerID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) adminAnnounce(w http.ResponseWriter, r *http.Request) {
+	var req announceRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil {
+		writeProblem(w, http.StatusBadRequest, model.CodeValidation, "body must be {\"text\": \"...\", \"room_id\": \"...\"}")
+		return
+	}
+
+	req.Text = strings.TrimSpace(req.Text)
+	if req.Text == "" || len(req.Text) > maxAnnouncementLen {
+		writeProblem(w, http.StatusBadRequest, model.CodeValidation, "text must be 1 to 2000 bytes")
+		return
+	}
+
+	var targets []*roomSession
+	roomsMu.Lock()
+	if req.RoomID == "" {
+		for _, sess := range rooms {
+			targets = append(targets, sess)
+		}
+	} else if id, err := uuid.Parse(req.RoomID); err == nil && rooms[id] != nil {
+		targets = append(targets, rooms[id])
+	}
+	roomsMu.Unlock()
+
+	if req.RoomID != "" && len(targets) == 0 {
+		writeProblem(w, http.StatusNotFound, model.CodeNotFound, "no active session in room")
+		return
+	}
+
+	payload, err := json.Marshal(announcementPayload{Text: req.Text, CreatedAt: time.Now().UTC()})
+	if err != nil {
+		writeProblem(w, http.StatusInternalServerError, model.CodeInternal, "something wrong")
+		return
+	}
+
+	// Участникам пишем параллельно: медленный клиент задерживает ответ
+	// не дольше writeWait и не тормозит доставку остальным
+	var (
+		wg        sync.WaitGroup
+		delivered atomic.Int32
+	)
+	for _, sess := range targets {
+		for _, p := range sess.snapshot() {
+			wg.Go(func() {
+				if err := p.writeJSON(message{Type: "announcement", Payload: payload}); err != nil {
+					slog.ErrorContext(r.Context(), "failed to send 'announcement'", "err", err)
+					return
+				}
+				delivered.Add(1)
+			})
+		}
+	}
+	wg.Wait()
+
+	slog.InfoContext(r.Context(), "admin announcement sent", "room_id", req.RoomID, "rooms", len(targets), "peers", delivered.Load())
+
+	writeJSON(w, http.StatusOK, map[string]int{"rooms": len(targets), "peers": int(delivered.Load())})
+}
+
+// lookupSession находит активную сессию комнаты из пути; если её нет,
+// отвечает сам.
+func lookupSession(w http.ResponseWriter, r *http.Request) (openapi_types.UUID, *roomSession, bool) {
+	id, err := uuid.Parse(r.PathValue("room"))
+	if err != nil {
+		writeProblem(w, http.StatusBadRequest, model.CodeValidation, "room id must be a uuid")
+		return id, nil, false
+	}
+
+	roomsMu.Lock()
+	sess := rooms[id]
+	roomsMu.Unlock()
+
+	if sess == nil {
+		writeProblem(w, http.StatusNotFound, model.CodeNotFound, "no active session in room")
+		return id, nil, false
+	}
+
+	return id, sess, true
+}
+
+// describeSession снимает состояние сессии и её участников.
+func describeSession(id openapi_types.UUID, sess *roomSession) (sessionInfo, []peerInfo) {
+	sess.Session.Lock()
+	peers := make([]peerInfo, 0, len(sess.Peers))
+	for peerID, p := range sess.Peers {
+		peers = append(peers, peerInfo{
+			ID:            peerID,
+			User:          p.user,
+			Tenant:        p.tenant,
+			RemoteIP:      p.remoteIP,
+			ConnectedAt:   p.connectedAt,
+			PendingWrites: int(p.pending.Load()),
+		})
+	}
+	startedAt := sess.startedAt
+	sess.Session.Unlock()
+
+	sort.Slice(peers, func(i, j int) bool { return peers[i].ConnectedAt.Before(peers[j].ConnectedAt) })
+
+	info := sessionInfo{
+		RoomID:      id.String(),
+		Peers:       len(peers),
+		StartedAt:   startedAt,
+		ConnectedAt: make([]time.Time, 0, len(peers)),
+	}
+	for _, p := range peers {
+		info.ConnectedAt = append(info.ConnectedAt, p.ConnectedAt)
+	}
+
+	return info, peers
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("failed to write admin response", "err", err)
+	}
+}
+
+// writeProblem отвечает ошибкой в том же формате RFC 7807, что и основной API.
+func writeProblem(w http.ResponseWriter, status int, code, detail string) {
+	w.Header().Set("Content-Type", mimeProblemJSON)
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(newProblem(status, code, detail)); err != nil {
+		slog.Error("failed to write admin response", "err", err)
+	}
+}
@@ -0,0 +1,224 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const testAdminToken = "admin-token-0123456789"
+
+func TestServer_AdminAuth(t *testing.T) {
+	do := func(srv *Server, token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/admin/sessions", nil)
+		if token != "" {
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		srv.adminHandler().ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// Без токена и mTLS админка закрыта
+	assert.Equal(t, http.StatusForbidden, do(&Server{}, ""))
+
+	srv := &Server{adminToken: testAdminToken}
+	assert.Equal(t, http.StatusUnauthorized, do(srv, ""))
+	assert.Equal(t, http.StatusUnauthorized, do(srv, "wrong"))
+	assert.Equal(t, http.StatusOK, do(srv, testAdminToken))
+
+	t.Run("basic only for dashboard", func(t *testing.T) {
+		clearRooms()
+
+		basic := func(method, path, body string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(method, path, strings.NewReader(body))
+			req.SetBasicAuth("admin", testAdminToken)
+			req.Header.Set(echo.HeaderContentType, "text/plain")
+			rec := httptest.NewRecorder()
+			srv.adminHandler().ServeHTTP(rec, req)
+			return rec
+		}
+
+		assert.Equal(t, http.StatusOK, basic(http.MethodGet, "/admin/dashboard", "").Code)
+
+		// Межсайтовая форма с сохранённым в браузере Basic не проходит
+		rec := basic(http.MethodPost, "/admin/announcements", `{"text":"pwned"}`)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.NotContains(t, rec.Header().Values("WWW-Authenticate"), `Basic realm="syncplay admin"`)
+		assert.Equal(t, http.StatusUnauthorized, basic(http.MethodGet, "/admin/sessions", "").Code)
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/announcements", strings.NewReader(`{"text":"hi"}`))
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+testAdminToken)
+		rec = httptest.NewRecorder()
+		srv.adminHandler().ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
+
+func TestServer_AdminSessions(t *testing.T) {
+	clearRooms()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockModel := NewMockmodelRoom(ctrl)
+	mockModel.EXPECT().TouchRoomUUID(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockModel.EXPECT().RoomExistsUUID(gomock.Any(), gomock.Any()).Return(true, nil).AnyTimes()
+	srv := &Server{m: mockModel, adminToken: testAdminToken}
+
+	e := echo.New()
+	e.GET("/ws/:roomID", func(c echo.Context) error {
+		return srv.ConnectRoomWS(c, uuid.MustParse(c.Param("roomID")))
+	})
+
+	ts := httptest.NewServer(e)
+	defer ts.Close()
+
+	roomID := uuid.New()
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws/" + roomID.String()
+
+	admin := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+testAdminToken)
+		rec := httptest.NewRecorder()
+		srv.adminHandler().ServeHTTP(rec, req)
+		return rec
+	}
+
+	peer1, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	require.NoError(t, err)
+	defer peer1.Close()
+
+	var w1 message
+	require.NoError(t, readJSONWithTimeout(t, peer1, &w1))
+	require.NoError(t, readJSONWithTimeout(t, peer1, &message{})) // existing-peers
+
+	peer2, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	require.NoError(t, err)
+	defer peer2.Close()
+
+	var w2 message
+	require.NoError(t, readJSONWithTimeout(t, peer2, &w2))
+	require.NoError(t, readJSONWithTimeout(t, peer2, &message{})) // existing-peers
+	require.NoError(t, readJSONWithTimeout(t, peer1, &message{})) // new-peer
+
+	t.Run("list", func(t *testing.T) {
+		rec := admin(http.MethodGet, "/admin/sessions", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var res struct {
+			Sessions []sessionInfo `json:"sessions"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		require.Len(t, res.Sessions, 1)
+		assert.Equal(t, roomID.String(), res.Sessions[0].RoomID)
+		assert.Equal(t, 2, res.Sessions[0].Peers)
+		assert.Len(t, res.Sessions[0].ConnectedAt, 2)
+		assert.False(t, res.Sessions[0].StartedAt.IsZero())
+	})
+
+	t.Run("inspect", func(t *testing.T) {
+		rec := admin(http.MethodGet, "/admin/sessions/"+roomID.String(), "")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var res sessionDetail
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		require.Len(t, res.PeerList, 2)
+		assert.Equal(t, w1.ID, res.PeerList[0].ID)
+		assert.Equal(t, w2.ID, res.PeerList[1].ID)
+		assert.Equal(t, "127.0.0.1", res.PeerList[0].RemoteIP)
+		assert.Zero(t, res.PeerList[0].PendingWrites)
+
+		assert.Equal(t, http.StatusNotFound, admin(http.MethodGet, "/admin/sessions/"+uuid.NewString(), "").Code)
+		assert.Equal(t, http.StatusBadRequest, admin(http.MethodGet, "/admin/sessions/not-a-uuid", "").Code)
+	})
+
+	t.Run("announce", func(t *testing.T) {
+		rec := admin(http.MethodPost, "/admin/announcements", `{"text":"maintenance at 10:00"}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"rooms":1,"peers":2}`, rec.Body.String())
+
+		for _, conn := range []*websocket.Conn{peer1, peer2} {
+			var msg message
+			require.NoError(t, readJSONWithTimeout(t, conn, &msg))
+			assert.Equal(t, "announcement", msg.Type)
+
+			var p announcementPayload
+			require.NoError(t, json.Unmarshal(msg.Payload, &p))
+			assert.Equal(t, "maintenance at 10:00", p.Text)
+		}
+
+		assert.Equal(t, http.StatusBadRequest, admin(http.MethodPost, "/admin/announcements", `{"text":" "}`).Code)
+		assert.Equal(t, http.StatusNotFound,
+			admin(http.MethodPost, "/admin/announcements", `{"text":"hi","room_id":"`+uuid.NewString()+`"}`).Code)
+	})
+
+	t.Run("announce with stalled peer", func(t *testing.T) {
+		roomsMu.Lock()
+		sess := rooms[roomID]
+		roomsMu.Unlock()
+		sess.Session.Lock()
+		stalled := sess.Peers[w1.ID]
+		sess.Session.Unlock()
+
+		// Запись первому участнику зависла: объявление всё равно доходит до второго
+		stalled.writeMu.Lock()
+
+		done := make(chan *httptest.ResponseRecorder, 1)
+		go func() { done <- admin(http.MethodPost, "/admin/announcements", `{"text":"second"}`) }()
+
+		var msg message
+		require.NoError(t, readJSONWithTimeout(t, peer2, &msg))
+		assert.Equal(t, "announcement", msg.Type)
+
+		require.Eventually(t, func() bool { return stalled.pending.Load() == 1 }, time.Second, 10*time.Millisecond)
+		rec := admin(http.MethodGet, "/admin/sessions/"+roomID.String(), "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		var res sessionDetail
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		assert.Equal(t, 1, res.PeerList[0].PendingWrites)
+
+		stalled.writeMu.Unlock()
+
+		rec = <-done
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"rooms":1,"peers":2}`, rec.Body.String())
+		require.NoError(t, readJSONWithTimeout(t, peer1, &msg))
+		assert.Equal(t, "announcement", msg.Type)
+	})
+
+	t.Run("disconnect peer", func(t *testing.T) {
+		require.Equal(t, http.StatusNoContent, admin(http.MethodDelete, "/admin/sessions/"+roomID.String()+"/peers/"+w1.ID, "").Code)
+
+		err := readJSONWithTimeout(t, peer1, &message{})
+		require.True(t, websocket.IsCloseError(err, closeKicked), "unexpected error: %v", err)
+
+		var left message
+		require.NoError(t, readJSONWithTimeout(t, peer2, &left))
+		assert.Equal(t, "peer-left", left.Type)
+		assert.Equal(t, w1.ID, left.ID)
+
+		assert.Equal(t, http.StatusNotFound, admin(http.MethodDelete, "/admin/sessions/"+roomID.String()+"/peers/"+w1.ID, "").Code)
+	})
+
+	t.Run("close room", func(t *testing.T) {
+		require.Equal(t, http.StatusNoContent, admin(http.MethodDelete, "/admin/sessions/"+roomID.String()+"?reason=abuse", "").Code)
+
+		var closed message
+		require.NoError(t, readJSONWithTimeout(t, peer2, &closed))
+		assert.Equal(t, "room-closed", closed.Type)
+		assert.JSONEq(t, `{"reason":"abuse"}`, string(closed.Payload))
+
+		assert.Equal(t, http.StatusNotFound, admin(http.MethodGet, "/admin/sessions/"+roomID.String(), "").Code)
+	})
+}
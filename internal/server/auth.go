@@ -0,0 +1,149 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/vpbuyanov/syncplay/internal/auth"
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+const (
+	claimsKey = "auth.claims"
+
+	// wsTokenProtocol — подпротокол, за которым браузерный клиент передаёт
+	// токен: new WebSocket(url, ["bearer", token]). Сервер выбирает
+	// "bearer" и не возвращает токен в ответе.
+	wsTokenProtocol = "bearer"
+	// wsTokenParam — запасной способ передать токен в WS-апгрейде.
+	wsTokenParam = "access_token"
+)
+
+// authenticate определяет пользователя по JWT или сессии встроенной
+// учётной записи и кладёт утверждения в контекст echo. Без токена запрос
+// пропускается, если авторизация не обязательна; неверный токен
+// отклоняется всегда. Пробы, /metrics, /api/v1/info и /api/v1/auth/*
+// доступны без токена.
+func (s *Server) authenticate(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if isProbe(c.Path()) || c.Path() == metricsPath || c.Path() == "/api/v1/info" ||
+			strings.HasPrefix(c.Path(), "/api/v1/auth/") {
+			return next(c)
+		}
+
+		token := s.requestToken(c)
+		if token == "" {
+			if s.authRequired {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, "Bearer")
+				return errors.Wrap(model.ErrUnauthorized, "missing bearer token")
+			}
+			return next(c)
+		}
+
+		claims, err := s.identify(c.Request().Context(), token)
+		if errors.Is(err, model.ErrUnauthorized) {
+			slog.DebugContext(c.Request().Context(), "token rejected", "err", err)
+			c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer error="invalid_token"`)
+			return errors.Wrap(model.ErrUnauthorized, "invalid bearer token")
+		}
+		if err != nil {
+			return err
+		}
+
+		c.Set(claimsKey, claims)
+		trace.SpanFromContext(c.Request().Context()).SetAttributes(attribute.String("enduser.id", claims.Subject))
+		withLogAttrs(c, "user", claims.Subject)
+
+		return next(c)
+	}
+}
+
+// identify проверяет токен: JWT отличается от токена сессии точками.
+// Пользователь сессии превращается в утверждения с его id в sub
+// и признаком Local.
+func (s *Server) identify(ctx context.Context, token string) (*auth.Claims, error) {
+	if s.auth != nil && strings.Count(token, ".") == 2 {
+		claims, err := s.auth.Verify(token)
+		if err != nil {
+			return nil, errors.Wrap(model.ErrUnauthorized, err.Error())
+		}
+		return claims, nil
+	}
+
+	if !s.accounts {
+		return nil, errors.Wrap(model.ErrUnauthorized, "unsupported token")
+	}
+
+	user, expiresAt, err := s.m.SessionUser(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   user.ID,
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+		Name:  user.Username,
+		Local: true,
+	}, nil
+}
+
+// requestToken берёт токен из запроса, а при включённых учётных
+// записях — ещё и из cookie сессии.
+func (s *Server) requestToken(c echo.Context) string {
+	if token := bearerToken(c); token != "" {
+		return token
+	}
+
+	if !s.accounts {
+		return ""
+	}
+
+	cookie, err := c.Cookie(sessionCookie)
+	if err != nil {
+		return ""
+	}
+
+	return cookie.Value
+}
+
+// bearerToken достаёт токен из Authorization. Браузер не может задать
+// заголовки WebSocket, поэтому для апгрейда токен принимается ещё из
+// Sec-WebSocket-Protocol и параметра access_token.
+func bearerToken(c echo.Context) string {
+	req := c.Request()
+
+	if scheme, token, ok := strings.Cut(req.Header.Get(echo.HeaderAuthorization), " "); ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+
+	if !strings.HasPrefix(c.Path(), "/api/v1/ws/") && !strings.HasPrefix(c.Path(), "/api/v2/ws/") {
+		return ""
+	}
+
+	protocols := websocket.Subprotocols(req)
+	for i, p := range protocols {
+		if p == wsTokenProtocol && i+1 < len(protocols) {
+			return protocols[i+1]
+		}
+	}
+
+	return c.QueryParam(wsTokenParam)
+}
+
+// claimsFrom возвращает утверждения проверенного токена или nil,
+// если запрос пришёл без него.
+func claimsFrom(c echo.Context) *auth.Claims {
+	claims, _ := c.Get(claimsKey).(*auth.Claims)
+
+	return claims
+}
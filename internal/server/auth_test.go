@@ -0,0 +1,156 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/vpbuyanov/syncplay/internal/auth"
+	"github.com/vpbuyanov/syncplay/internal/config"
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func testToken(t *testing.T, sub string) string {
+	t.Helper()
+
+	tok, err := jwt.NewWithClaims(jwt.SigningMethodHS256, auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   sub,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}).SignedString([]byte(testSecret))
+	require.NoError(t, err)
+
+	return tok
+}
+
+func TestServer_Auth(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockModel := NewMockmodelRoom(ctrl)
+	mockModel.EXPECT().ListRooms(gomock.Any(), gomock.Any()).Return(model.RoomPage{}, nil)
+
+	srv, err := NewServer(&config.Config{Auth: config.Auth{Secret: testSecret, Required: true}}, mockModel)
+	require.NoError(t, err)
+
+	do := func(path, authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if authorization != "" {
+			req.Header.Set(echo.HeaderAuthorization, authorization)
+		}
+		rec := httptest.NewRecorder()
+		srv.e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Без токена — 401 с кодом unauthorized
+	rec := do("/api/v1/rooms", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"code":"unauthorized"`)
+	assert.Equal(t, "Bearer", rec.Header().Get(echo.HeaderWWWAuthenticate))
+
+	// Неверный токен
+	rec = do("/api/v1/rooms", "Bearer "+testToken(t, "alice")+"x")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Header().Get(echo.HeaderWWWAuthenticate), "invalid_token")
+
+	// Действительный токен
+	assert.Equal(t, http.StatusOK, do("/api/v1/rooms", "Bearer "+testToken(t, "alice")).Code)
+
+	// Пробы и информация о сервере доступны без токена
+	assert.Equal(t, http.StatusOK, do("/healthz", "").Code)
+	assert.Equal(t, http.StatusOK, do("/api/v1/info", "").Code)
+}
+
+func TestBearerToken(t *testing.T) {
+	e := echo.New()
+
+	newCtx := func(path string, header http.Header) echo.Context {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header = header
+		c := e.NewContext(req, httptest.NewRecorder())
+		c.SetPath(strings.SplitN(path, "?", 2)[0])
+		return c
+	}
+
+	c := newCtx("/api/v1/rooms", http.Header{echo.HeaderAuthorization: {"bearer abc"}})
+	assert.Equal(t, "abc", bearerToken(c))
+
+	// Подпротокол и параметр принимаются только при апгрейде
+	c = newCtx("/api/v1/rooms?access_token=abc", http.Header{})
+	assert.Empty(t, bearerToken(c))
+
+	c = newCtx("/api/v1/ws/:id", http.Header{"Sec-Websocket-Protocol": {"bearer, abc"}})
+	assert.Equal(t, "abc", bearerToken(c))
+
+	c = newCtx("/api/v1/ws/:id?access_token=abc", http.Header{})
+	assert.Equal(t, "abc", bearerToken(c))
+}
+
+func TestConnectRoomWS_AuthIdentity(t *testing.T) {
+	clearRooms()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockModel := NewMockmodelRoom(ctrl)
+	mockModel.EXPECT().RoomExistsUUID(gomock.Any(), gomock.Any()).Return(true, nil).Times(2)
+	mockModel.EXPECT().TouchRoomUUID(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	srv, err := NewServer(&config.Config{Auth: config.Auth{Secret: testSecret}}, mockModel)
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(srv.e)
+	defer ts.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/api/v1/ws/" + uuid.NewString()
+
+	// Токен в подпротоколе: сервер выбирает "bearer", а не сам токен
+	dialer := websocket.Dialer{Subprotocols: []string{wsTokenProtocol, testToken(t, "alice")}}
+	alice, resp, err := dialer.Dial(wsURL, nil)
+	require.NoError(t, err)
+	defer alice.Close()
+	assert.Equal(t, wsTokenProtocol, resp.Header.Get("Sec-WebSocket-Protocol"))
+
+	var msg message
+	require.NoError(t, readJSONWithTimeout(t, alice, &msg))
+	assert.Equal(t, "welcome", msg.Type)
+	assert.Equal(t, "alice", msg.User)
+	aliceID := msg.ID
+	require.NoError(t, readJSONWithTimeout(t, alice, &msg)) // existing-peers
+
+	// Токен в параметре запроса
+	bob, _, err := websocket.DefaultDialer.Dial(wsURL+"?access_token="+url.QueryEscape(testToken(t, "bob")), nil)
+	require.NoError(t, err)
+	defer bob.Close()
+
+	require.NoError(t, readJSONWithTimeout(t, bob, &msg))
+	assert.Equal(t, "bob", msg.User)
+	require.NoError(t, readJSONWithTimeout(t, bob, &msg))
+	assert.Equal(t, "existing-peers", msg.Type)
+	assert.Equal(t, map[string]string{aliceID: "alice"}, msg.Users)
+
+	require.NoError(t, readJSONWithTimeout(t, alice, &msg))
+	assert.Equal(t, "new-peer", msg.Type)
+	assert.Equal(t, "bob", msg.User)
+
+	// Неверный токен отклоняется до апгрейда
+	_, resp, err = websocket.DefaultDialer.Dial(wsURL+"?access_token=bad", nil)
+	require.Error(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
@@ -0,0 +1,250 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	openapi_types "github.com/oapi-codegen/runtime/types"
+
+	"github.com/vpbuyanov/syncplay/internal/gen"
+	"github.com/vpbuyanov/syncplay/internal/logging"
+	"github.com/vpbuyanov/syncplay/internal/model"
+	"github.com/vpbuyanov/syncplay/internal/version"
+)
+
+// dashboardRefresh — как часто /admin/dashboard/events присылает новое состояние.
+const dashboardRefresh = 2 * time.Second
+
+// dashboardMaxAge — сколько снимок состояния раздаётся всем вкладкам без
+// пересчёта. Меньше dashboardRefresh, чтобы одна вкладка видела новый снимок
+// на каждом тике, а N вкладок не умножали запросы к базе.
+const dashboardMaxAge = dashboardRefresh / 2
+
+//go:embed dashboard/*.html
+var dashboardFS embed.FS
+
+var dashboardTmpl = template.Must(template.New("").Funcs(template.FuncMap{
+	"ago": func(t time.Time) string { return time.Since(t).Round(time.Second).String() },
+	"ms":  func(v float64) string { return fmt.Sprintf("%.1f ms", v) },
+}).ParseFS(dashboardFS, "dashboard/*.html"))
+
+type dashboardRoom struct {
+	sessionInfo
+	PeerList       []peerInfo
+	MessagesPerMin int
+}
+
+type dashboardView struct {
+	Version        string
+	GeneratedAt    time.Time
+	Uptime         time.Duration
+	State          gen.ServerState
+	Checks         []gen.Check
+	Rooms          []dashboardRoom
+	Peers          int
+	MessagesPerMin int
+	// Collecting — последние ошибки из лога собираются, см. SetRecentErrors
+	Collecting bool
+	Errors     []logging.ErrorEntry
+}
+
+// dashboardCache — общий для всех вкладок снимок дашборда.
+// Нулевое значение готово к работе.
+type dashboardCache struct {
+	mu   sync.Mutex
+	view dashboardView
+	at   time.Time
+}
+
+// SetRecentErrors подключает к дашборду последние ошибки из лога.
+// Вызывается до Listen.
+func (s *Server) SetRecentErrors(errs *logging.RecentErrors) {
+	s.errs = errs
+}
+
+func (s *Server) adminDashboard(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	if err := dashboardTmpl.ExecuteTemplate(&buf, "dashboard.html", s.dashboardSnapshot(r.Context())); err != nil {
+		slog.ErrorContext(r.Context(), "failed to render dashboard", "err", err)
+		writeProblem(w, http.StatusInternalServerError, model.CodeInternal, "something wrong")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(buf.Bytes())
+}
+
+// adminDashboardEvents — поток SSE: событие stats с готовым HTML блока
+// состояния, которым страница заменяет старый.
+func (s *Server) adminDashboardEvents(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	ticker := time.NewTicker(dashboardRefresh)
+	defer ticker.Stop()
+
+	for {
+		var buf bytes.Buffer
+		if err := dashboardTmpl.ExecuteTemplate(&buf, "stats", s.dashboardSnapshot(r.Context())); err != nil {
+			slog.ErrorContext(r.Context(), "failed to render dashboard", "err", err)
+			return
+		}
+
+		if err := writeEvent(w, "stats", buf.String()); err != nil {
+			return
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// writeEvent пишет событие SSE; многострочные данные разбиваются
+// на строки data, браузер склеит их обратно.
+func writeEvent(w http.ResponseWriter, event, data string) error {
+	var b strings.Builder
+	b.WriteString("event: " + event + "\n")
+	for line := range strings.SplitSeq(strings.ReplaceAll(data, "\r", ""), "\n") {
+		b.WriteString("data: " + line + "\n")
+	}
+	b.WriteString("\n")
+
+	_, err := w.Write([]byte(b.String()))
+	return err
+}
+
+// dashboardSnapshot возвращает снимок не старше dashboardMaxAge. Пересчёт
+// идёт под блокировкой: вкладки, пришедшие во время него, ждут его результат.
+func (s *Server) dashboardSnapshot(ctx context.Context) dashboardView {
+	s.dashboard.mu.Lock()
+	defer s.dashboard.mu.Unlock()
+
+	if now := time.Now(); s.dashboard.at.IsZero() || now.Sub(s.dashboard.at) >= dashboardMaxAge {
+		// Снимок общий, поэтому его не обрывает закрытие вкладки, начавшей пересчёт
+		s.dashboard.view = s.dashboardView(context.WithoutCancel(ctx))
+		s.dashboard.at = now
+	}
+
+	return s.dashboard.view
+}
+
+func (s *Server) dashboardView(ctx context.Context) dashboardView {
+	now := time.Now()
+	checks := s.runChecks(ctx)
+
+	view := dashboardView{
+		Version:        version.Version,
+		GeneratedAt:    now,
+		State:          s.state(checks),
+		Checks:         checks,
+		MessagesPerMin: s.messages.perMinute(now),
+	}
+	if !s.startedAt.IsZero() {
+		view.Uptime = now.Sub(s.startedAt).Round(time.Second)
+	}
+	if s.errs != nil {
+		view.Collecting = true
+		view.Errors = s.errs.List()
+	}
+
+	roomsMu.Lock()
+	sessions := make(map[openapi_types.UUID]*roomSession, len(rooms))
+	for id, sess := range rooms {
+		sessions[id] = sess
+	}
+	roomsMu.Unlock()
+
+	for id, sess := range sessions {
+		info, peers := describeSession(id, sess)
+		if info.Peers == 0 {
+			continue
+		}
+
+		view.Peers += info.Peers
+		view.Rooms = append(view.Rooms, dashboardRoom{
+			sessionInfo:    info,
+			PeerList:       peers,
+			MessagesPerMin: sess.messages.perMinute(now),
+		})
+	}
+
+	sort.Slice(view.Rooms, func(i, j int) bool { return view.Rooms[i].StartedAt.Before(view.Rooms[j].StartedAt) })
+
+	return view
+}
+
+// countMessage учитывает пересланное сообщение в метриках и темпе комнаты.
+func (s *Server) countMessage(sess *roomSession, typ string) {
+	wsMessages.WithLabelValues(typ).Inc()
+
+	now := time.Now()
+	s.messages.add(now)
+	sess.messages.add(now)
+}
+
+// rateMeter считает события за последнюю минуту по секундным корзинам.
+// Нулевое значение готово к работе.
+type rateMeter struct {
+	mu      sync.Mutex
+	buckets [60]int
+	// last — секунда (unix) последнего обновления корзин
+	last int64
+}
+
+func (m *rateMeter) add(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sec := now.Unix()
+	m.advance(sec)
+	m.buckets[sec%int64(len(m.buckets))]++
+}
+
+func (m *rateMeter) perMinute(now time.Time) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.advance(now.Unix())
+
+	var sum int
+	for _, n := range m.buckets {
+		sum += n
+	}
+
+	return sum
+}
+
+// advance обнуляет корзины секунд, прошедших после last.
+func (m *rateMeter) advance(sec int64) {
+	if sec <= m.last {
+		return
+	}
+
+	size := int64(len(m.buckets))
+	if sec-m.last >= size {
+		m.buckets = [60]int{}
+	} else {
+		for t := m.last + 1; t <= sec; t++ {
+			m.buckets[t%size] = 0
+		}
+	}
+	m.last = sec
+}
@@ -0,0 +1,194 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vpbuyanov/syncplay/internal/config"
+	"github.com/vpbuyanov/syncplay/internal/logging"
+)
+
+func TestRateMeter(t *testing.T) {
+	var m rateMeter
+	start := time.Unix(1_000_000, 0)
+
+	m.add(start)
+	m.add(start)
+	m.add(start.Add(30 * time.Second))
+	assert.Equal(t, 3, m.perMinute(start.Add(30*time.Second)))
+
+	// Через минуту первые события выпадают из окна
+	assert.Equal(t, 1, m.perMinute(start.Add(60*time.Second)))
+	assert.Equal(t, 0, m.perMinute(start.Add(5*time.Minute)))
+}
+
+func TestServer_AdminDashboard(t *testing.T) {
+	clearRooms()
+
+	errs := logging.NewRecentErrors(10)
+	l, err := logging.New(&strings.Builder{}, config.Log{}, slog.LevelInfo, logging.WithRecentErrors(errs))
+	require.NoError(t, err)
+	l.Error("failed to forward signal", "err", "<broken pipe>")
+
+	srv := &Server{adminToken: testAdminToken, startedAt: time.Now()}
+	srv.AddCheck("storage", func(context.Context) error { return errors.New("connection refused") })
+	srv.SetRecentErrors(errs)
+
+	now := time.Now()
+	roomID := uuid.New()
+	sess := &roomSession{
+		Peers:     map[string]*peer{"p1": {remoteIP: "10.0.0.1", connectedAt: now}},
+		startedAt: now,
+	}
+	sess.messages.add(now)
+	sess.messages.add(now)
+
+	roomsMu.Lock()
+	rooms[roomID] = sess
+	roomsMu.Unlock()
+	defer clearRooms()
+
+	t.Run("page", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/admin/dashboard", nil)
+		req.SetBasicAuth("admin", testAdminToken)
+		rec := httptest.NewRecorder()
+		srv.adminHandler().ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Header().Get(echo.HeaderContentType), "text/html")
+
+		body := rec.Body.String()
+		assert.Contains(t, body, `new EventSource("/admin/dashboard/events")`)
+		assert.Contains(t, body, "connection refused")
+		assert.Contains(t, body, roomID.String())
+		assert.Contains(t, body, "10.0.0.1")
+		assert.Contains(t, body, "<td>2</td>")
+		// Текст ошибок экранируется
+		assert.Contains(t, body, "err=&lt;broken pipe&gt;")
+	})
+
+	t.Run("basic auth", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/admin/dashboard", nil)
+		req.SetBasicAuth("admin", "wrong")
+		rec := httptest.NewRecorder()
+		srv.adminHandler().ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Header().Values("WWW-Authenticate"), `Basic realm="syncplay admin"`)
+	})
+
+	t.Run("events", func(t *testing.T) {
+		ts := httptest.NewServer(srv.adminHandler())
+		defer ts.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/admin/dashboard/events", nil)
+		require.NoError(t, err)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+testAdminToken)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get(echo.HeaderContentType))
+
+		// Первое событие приходит сразу, не дожидаясь dashboardRefresh
+		sc := bufio.NewScanner(resp.Body)
+		require.True(t, sc.Scan())
+		assert.Equal(t, "event: stats", sc.Text())
+
+		var data []string
+		for sc.Scan() && sc.Text() != "" {
+			require.True(t, strings.HasPrefix(sc.Text(), "data: "), "unexpected line %q", sc.Text())
+			data = append(data, strings.TrimPrefix(sc.Text(), "data: "))
+		}
+		assert.Contains(t, strings.Join(data, "\n"), "messages/min")
+	})
+}
+
+func TestServer_AdminDashboardSharedSnapshot(t *testing.T) {
+	clearRooms()
+
+	var calls atomic.Int32
+	srv := &Server{adminToken: testAdminToken}
+	srv.AddCheck("storage", func(context.Context) error {
+		calls.Add(1)
+		return nil
+	})
+
+	ts := httptest.NewServer(srv.adminHandler())
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Несколько открытых вкладок получают один и тот же снимок
+	for range 5 {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/admin/dashboard/events", nil)
+		require.NoError(t, err)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+testAdminToken)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		sc := bufio.NewScanner(resp.Body)
+		require.True(t, sc.Scan())
+		assert.Equal(t, "event: stats", sc.Text())
+	}
+
+	assert.Equal(t, int32(1), calls.Load())
+
+	// Устаревший снимок пересчитывается
+	srv.dashboard.mu.Lock()
+	srv.dashboard.at = time.Now().Add(-dashboardMaxAge)
+	srv.dashboard.mu.Unlock()
+
+	srv.dashboardSnapshot(ctx)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestServer_AdminShutdownClosesEvents(t *testing.T) {
+	srv, err := NewServer(&config.Config{
+		Server: config.Server{MetricsAddr: "127.0.0.1:0", AdminToken: testAdminToken},
+	}, nil)
+	require.NoError(t, err)
+	srv.checks = nil
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = srv.admin.Serve(ln) }()
+
+	req, err := http.NewRequest(http.MethodGet, "http://"+ln.Addr().String()+"/admin/dashboard/events", nil)
+	require.NoError(t, err)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+testAdminToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	sc := bufio.NewScanner(resp.Body)
+	require.True(t, sc.Scan())
+
+	// Открытый поток не задерживает остановку
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.NoError(t, srv.admin.Shutdown(ctx))
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/vpbuyanov/syncplay/internal/gen"
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+const mimeProblemJSON = "application/problem+json"
+
+var codeStatus = map[string]int{
+	model.CodeValidation:    http.StatusBadRequest,
+	model.CodeNotFound:      http.StatusNotFound,
+	model.CodeConflict:      http.StatusConflict,
+	model.CodeUnauthorized:  http.StatusUnauthorized,
+	model.CodeForbidden:     http.StatusForbidden,
+	model.CodeUnavailable:   http.StatusServiceUnavailable,
+	model.CodeQuotaExceeded: http.StatusForbidden,
+	model.CodeRateLimited:   http.StatusTooManyRequests,
+	model.CodeInternal:      http.StatusInternalServerError,
+}
+
+var kindByCode = map[string]error{
+	model.CodeValidation:    model.ErrInvalidArgument,
+	model.CodeNotFound:      model.ErrNotFound,
+	model.CodeConflict:      model.ErrConflict,
+	model.CodeUnauthorized:  model.ErrUnauthorized,
+	model.CodeForbidden:     model.ErrForbidden,
+	model.CodeUnavailable:   model.ErrUnavailable,
+	model.CodeQuotaExceeded: model.ErrQuotaExceeded,
+	model.CodeRateLimited:   model.ErrRateLimited,
+}
+
+// handleError — центральный обработчик ошибок echo. Ошибки отдаются
+// в формате RFC 7807 с машиночитаемым кодом; подробности внутренних
+// ошибок клиенту не раскрываются.
+func (s *Server) handleError(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	p := problemFromError(err)
+	if p.Status >= http.StatusInternalServerError {
+		slog.ErrorContext(c.Request().Context(), "request failed", "method", c.Request().Method, "path", c.Path(), "err", err)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, mimeProblemJSON)
+
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(p.Status)
+	} else {
+		err = c.JSON(p.Status, p)
+	}
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "failed to write error response", "err", err)
+	}
+}
+
+func problemFromError(err error) gen.Problem {
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		return newProblem(he.Code, codeForStatus(he.Code), fmt.Sprint(he.Message))
+	}
+
+	code := model.ErrorCode(err)
+	status := codeStatus[code]
+
+	switch code {
+	case model.CodeInternal:
+		return newProblem(status, code, "something wrong")
+	case model.CodeUnavailable:
+		return newProblem(status, code, "service temporarily unavailable")
+	}
+
+	return newProblem(status, code, domainDetail(err, kindByCode[code]))
+}
+
+func newProblem(status int, code, detail string) gen.Problem {
+	p := gen.Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Code:   gen.ProblemCode(code),
+	}
+	if detail != "" {
+		p.Detail = &detail
+	}
+
+	return p
+}
+
+// domainDetail возвращает сообщение, которым была обёрнута доменная ошибка
+// в месте её возникновения, без префиксов вышележащих слоёв.
+func domainDetail(err, kind error) string {
+	suffix := ": " + kind.Error()
+	detail := kind.Error()
+
+	for e := err; e != nil && e != kind; e = errors.Unwrap(e) {
+		if msg, ok := strings.CutSuffix(e.Error(), suffix); ok {
+			detail = msg
+		}
+	}
+
+	return detail
+}
+
+func codeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return model.CodeValidation
+	case http.StatusUnauthorized:
+		return model.CodeUnauthorized
+	case http.StatusForbidden:
+		return model.CodeForbidden
+	case http.StatusNotFound:
+		return model.CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return "method-not-allowed"
+	case http.StatusConflict:
+		return model.CodeConflict
+	case http.StatusTooManyRequests:
+		return model.CodeRateLimited
+	case http.StatusServiceUnavailable:
+		return model.CodeUnavailable
+	}
+
+	if status >= http.StatusInternalServerError {
+		return model.CodeInternal
+	}
+
+	return "bad-request"
+}
@@ -0,0 +1,128 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vpbuyanov/syncplay/internal/config"
+	"github.com/vpbuyanov/syncplay/internal/gen"
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+// handle повторяет поведение echo: ошибка handler'а уходит в центральный обработчик.
+func handle(srv *Server, c echo.Context, err error) {
+	if err != nil {
+		srv.handleError(err, c)
+	}
+}
+
+// TestProblemFromError проверяет соответствие доменных ошибок HTTP-статусам и кодам.
+func TestProblemFromError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   gen.ProblemCode
+		detail string
+	}{
+		{
+			name:   "валидация",
+			err:    errors.Wrap(errors.Wrap(model.ErrInvalidArgument, "empty search query"), "Search model err"),
+			status: http.StatusBadRequest,
+			code:   gen.ValidationFailed,
+			detail: "empty search query",
+		},
+		{
+			name:   "не найдено",
+			err:    errors.Wrap(errors.Wrap(model.ErrNotFound, "room not found"), "DeleteRoom model err"),
+			status: http.StatusNotFound,
+			code:   gen.NotFound,
+			detail: "room not found",
+		},
+		{
+			name:   "конфликт",
+			err:    errors.Wrap(model.ErrConflict, "room already exists"),
+			status: http.StatusConflict,
+			code:   gen.Conflict,
+			detail: "room already exists",
+		},
+		{
+			name:   "нет прав",
+			err:    errors.Wrap(model.ErrForbidden, "invalid owner token"),
+			status: http.StatusForbidden,
+			code:   gen.Forbidden,
+			detail: "invalid owner token",
+		},
+		{
+			name:   "квота исчерпана",
+			err:    errors.Wrap(errors.Wrap(model.ErrQuotaExceeded, "tenant room limit of 3 reached"), "CreateRoom model err"),
+			status: http.StatusForbidden,
+			code:   gen.QuotaExceeded,
+			detail: "tenant room limit of 3 reached",
+		},
+		{
+			name:   "хранилище недоступно",
+			err:    fmt.Errorf("select rooms in pg: %w: %w", model.ErrUnavailable, errors.New("dial tcp: connection refused")),
+			status: http.StatusServiceUnavailable,
+			code:   gen.Unavailable,
+			detail: "service temporarily unavailable",
+		},
+		{
+			name:   "внутренняя ошибка не раскрывается",
+			err:    errors.New("pq: password authentication failed"),
+			status: http.StatusInternalServerError,
+			code:   gen.Internal,
+			detail: "something wrong",
+		},
+		{
+			name:   "ошибка echo",
+			err:    echo.NewHTTPError(http.StatusMethodNotAllowed, "Method Not Allowed"),
+			status: http.StatusMethodNotAllowed,
+			code:   gen.MethodNotAllowed,
+			detail: "Method Not Allowed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := problemFromError(tt.err)
+
+			assert.Equal(t, "about:blank", p.Type)
+			assert.Equal(t, tt.status, p.Status)
+			assert.Equal(t, http.StatusText(tt.status), p.Title)
+			assert.Equal(t, tt.code, p.Code)
+			require.NotNil(t, p.Detail)
+			assert.Equal(t, tt.detail, *p.Detail)
+		})
+	}
+}
+
+// TestServer_ErrorHandler проверяет, что ошибки роутера и параметров тоже отдаются как problem+json.
+func TestServer_ErrorHandler(t *testing.T) {
+	srv, err := NewServer(&config.Config{}, nil)
+	require.NoError(t, err)
+
+	t.Run("неизвестный маршрут", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		srv.e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/unknown", nil))
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, mimeProblemJSON, rec.Header().Get(echo.HeaderContentType))
+		assert.JSONEq(t, `{"type":"about:blank","title":"Not Found","status":404,"code":"not-found","detail":"Not Found"}`, rec.Body.String())
+	})
+
+	t.Run("невалидный параметр пути", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		srv.e.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/api/v1/rooms/not-a-uuid", nil))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"code":"validation-failed"`)
+	})
+}
@@ -6,19 +6,13 @@ import (
 	"github.com/labstack/echo/v4"
 
 	"github.com/vpbuyanov/syncplay/internal/gen"
+	"github.com/vpbuyanov/syncplay/internal/version"
 )
 
 func (s *Server) GetInfo(ctx echo.Context) error {
 	versionInfo := gen.GetInfo{
-		Version: "0.0.1",
+		Version: version.Version,
 	}
 
-	err := ctx.JSON(http.StatusOK, versionInfo)
-	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, gen.ErrorResponse{
-			Detail: "something wrong",
-		})
-	}
-
-	return nil
+	return ctx.JSON(http.StatusOK, versionInfo)
 }
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/vpbuyanov/syncplay/internal/gen"
+	"github.com/vpbuyanov/syncplay/internal/version"
+)
+
+// checkTimeout — сколько ждать одну проверку готовности.
+const checkTimeout = 2 * time.Second
+
+// Check — проверка компонента для /readyz и /api/v1/status.
+type Check func(ctx context.Context) error
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// AddCheck добавляет проверку готовности. Вызывается до Listen.
+func (s *Server) AddCheck(name string, check Check) {
+	s.checks = append(s.checks, namedCheck{name: name, check: check})
+}
+
+// isProbe — пути проб, на которые не действует таймаут запросов:
+// у каждой проверки свой таймаут.
+func isProbe(path string) bool {
+	return path == "/healthz" || path == "/readyz" || path == "/api/v1/status"
+}
+
+func (s *Server) Healthz(ctx echo.Context) error {
+	return ctx.String(http.StatusOK, "ok")
+}
+
+func (s *Server) Readyz(ctx echo.Context) error {
+	checks := publicChecks(ctx.Request().Context(), s.runChecks(ctx.Request().Context()))
+	res := gen.Readiness{
+		Status: s.state(checks),
+		Checks: checks,
+	}
+
+	status := http.StatusOK
+	if res.Status != gen.ServerStateOk {
+		status = http.StatusServiceUnavailable
+	}
+
+	return ctx.JSON(status, res)
+}
+
+func (s *Server) GetStatus(ctx echo.Context) error {
+	checks := publicChecks(ctx.Request().Context(), s.runChecks(ctx.Request().Context()))
+
+	res := gen.ServerStatus{
+		Status:  s.state(checks),
+		Version: version.Version,
+		Checks:  checks,
+	}
+	if !s.startedAt.IsZero() {
+		res.UptimeSeconds = int(time.Since(s.startedAt).Seconds())
+	}
+
+	for _, peers := range roomPeerCounts() {
+		res.Rooms++
+		res.Peers += peers
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// runChecks выполняет проверки параллельно, каждую со своим таймаутом.
+func (s *Server) runChecks(ctx context.Context) []gen.Check {
+	res := make([]gen.Check, len(s.checks))
+
+	var wg sync.WaitGroup
+	for i, c := range s.checks {
+		wg.Go(func() {
+			checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := c.check(checkCtx)
+
+			res[i] = gen.Check{
+				Name:      c.name,
+				Status:    gen.CheckStatusOk,
+				LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				msg := err.Error()
+				res[i].Status = gen.CheckStatusFail
+				res[i].Error = &msg
+			}
+		})
+	}
+	wg.Wait()
+
+	return res
+}
+
+// publicChecks убирает из проверок текст ошибок: в нём бывают адреса из
+// DSN и сообщения драйвера. Причину пишем в лог, её же показывает
+// /admin/dashboard.
+func publicChecks(ctx context.Context, checks []gen.Check) []gen.Check {
+	for i, c := range checks {
+		if c.Error != nil {
+			slog.WarnContext(ctx, "check failed", "check", c.Name, "err", *c.Error)
+			checks[i].Error = nil
+		}
+	}
+
+	return checks
+}
+
+func (s *Server) state(checks []gen.Check) gen.ServerState {
+	if s.draining.Load() {
+		return gen.ServerStateDraining
+	}
+
+	for _, c := range checks {
+		if c.Status != gen.CheckStatusOk {
+			return gen.ServerStateFail
+		}
+	}
+
+	return gen.ServerStateOk
+}
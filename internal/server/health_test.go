@@ -0,0 +1,136 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/vpbuyanov/syncplay/internal/config"
+	"github.com/vpbuyanov/syncplay/internal/gen"
+)
+
+func TestServer_Healthz(t *testing.T) {
+	srv, err := NewServer(&config.Config{}, nil)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	rec := httptest.NewRecorder()
+	srv.e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "ok", rec.Body.String())
+}
+
+func TestServer_Readyz(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockModel := NewMockmodelRoom(ctrl)
+	srv, err := NewServer(&config.Config{}, mockModel)
+	require.NoError(t, err)
+
+	migrations := errors.New("database schema is outdated")
+	srv.AddCheck("migrations", func(context.Context) error { return migrations })
+
+	readyz := func() (int, gen.Readiness) {
+		req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+		rec := httptest.NewRecorder()
+		srv.e.ServeHTTP(rec, req)
+
+		var res gen.Readiness
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+
+		return rec.Code, res
+	}
+
+	t.Run("схема устарела", func(t *testing.T) {
+		mockModel.EXPECT().Ping(gomock.Any()).Return(nil)
+
+		code, res := readyz()
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, gen.ServerStateFail, res.Status)
+		require.Len(t, res.Checks, 2)
+		assert.Equal(t, "storage", res.Checks[0].Name)
+		assert.Equal(t, gen.CheckStatusOk, res.Checks[0].Status)
+		assert.Equal(t, gen.CheckStatusFail, res.Checks[1].Status)
+		// Текст ошибки остаётся в логах и на админ-листенере
+		assert.Nil(t, res.Checks[1].Error)
+	})
+
+	migrations = nil
+
+	t.Run("готов", func(t *testing.T) {
+		mockModel.EXPECT().Ping(gomock.Any()).Return(nil)
+
+		code, res := readyz()
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, gen.ServerStateOk, res.Status)
+	})
+
+	t.Run("хранилище недоступно", func(t *testing.T) {
+		mockModel.EXPECT().Ping(gomock.Any()).Return(errors.New("connection refused"))
+
+		code, res := readyz()
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, gen.ServerStateFail, res.Status)
+	})
+
+	t.Run("сервер останавливается", func(t *testing.T) {
+		mockModel.EXPECT().Ping(gomock.Any()).Return(nil)
+		srv.draining.Store(true)
+
+		code, res := readyz()
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, gen.ServerStateDraining, res.Status)
+	})
+}
+
+func TestServer_GetStatus(t *testing.T) {
+	clearRooms()
+	defer clearRooms()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockModel := NewMockmodelRoom(ctrl)
+	mockModel.EXPECT().Ping(gomock.Any()).Return(nil)
+
+	srv, err := NewServer(&config.Config{}, mockModel)
+	require.NoError(t, err)
+
+	// Две комнаты, в них три участника
+	roomsMu.Lock()
+	for _, peers := range []int{1, 2} {
+		sess := &roomSession{Peers: make(map[string]*peer)}
+		for range peers {
+			sess.Peers[uuid.NewString()] = &peer{}
+		}
+		rooms[uuid.New()] = sess
+	}
+	roomsMu.Unlock()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/status", nil)
+	rec := httptest.NewRecorder()
+	srv.e.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, echo.MIMEApplicationJSON, rec.Header().Get(echo.HeaderContentType))
+
+	var res gen.ServerStatus
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.Equal(t, gen.ServerStateOk, res.Status)
+	assert.Equal(t, "0.0.1", res.Version)
+	assert.Equal(t, 2, res.Rooms)
+	assert.Equal(t, 3, res.Peers)
+	require.Len(t, res.Checks, 1)
+	assert.Equal(t, "storage", res.Checks[0].Name)
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/labstack/gommon/log"
+
+	"github.com/vpbuyanov/syncplay/internal/logging"
+)
+
+// requestID берёт X-Request-ID клиента или генерирует новый и кладёт его
+// в контекст запроса: id попадает во все записи, сделанные с этим контекстом.
+func requestID() echo.MiddlewareFunc {
+	return middleware.RequestIDWithConfig(middleware.RequestIDConfig{
+		RequestIDHandler: func(c echo.Context, id string) {
+			ctx := logging.With(c.Request().Context(), "request_id", id)
+			c.SetRequest(c.Request().WithContext(ctx))
+		},
+	})
+}
+
+// accessLog пишет строку на каждый запрос, кроме проб и /metrics,
+// которые опрашиваются слишком часто. WS-сессия логируется при закрытии.
+func accessLog(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if isProbe(c.Path()) || c.Path() == metricsPath {
+			return next(c)
+		}
+
+		start := time.Now()
+
+		err := next(c)
+		if err != nil {
+			c.Error(err)
+		}
+
+		req := c.Request()
+		slog.InfoContext(req.Context(), "request",
+			"method", req.Method,
+			"path", req.URL.Path,
+			"route", c.Path(),
+			"status", c.Response().Status,
+			"duration", time.Since(start),
+			"bytes", c.Response().Size,
+			"remote_ip", c.RealIP(),
+		)
+
+		return nil
+	}
+}
+
+// echoLogger направляет внутренние логи echo в slog.
+type echoLogger struct {
+	l *slog.Logger
+}
+
+func (e echoLogger) log(level slog.Level, i ...any) {
+	e.l.Log(context.Background(), level, strings.TrimSuffix(fmt.Sprint(i...), "\n"))
+}
+
+func (e echoLogger) logf(level slog.Level, format string, args ...any) {
+	e.l.Log(context.Background(), level, fmt.Sprintf(format, args...))
+}
+
+func (e echoLogger) logj(level slog.Level, j log.JSON) {
+	attrs := make([]any, 0, 2*len(j))
+	for k, v := range j {
+		attrs = append(attrs, k, v)
+	}
+
+	e.l.Log(context.Background(), level, "echo", attrs...)
+}
+
+// Уровнем, префиксом и выводом управляет slog, эти методы ничего не меняют.
+func (echoLogger) Output() io.Writer   { return io.Discard }
+func (echoLogger) SetOutput(io.Writer) {}
+func (echoLogger) Prefix() string      { return "" }
+func (echoLogger) SetPrefix(string)    {}
+func (echoLogger) Level() log.Lvl      { return log.DEBUG }
+func (echoLogger) SetLevel(log.Lvl)    {}
+func (echoLogger) SetHeader(string)    {}
+
+func (e echoLogger) Print(i ...any)                    { e.log(slog.LevelInfo, i...) }
+func (e echoLogger) Printf(format string, args ...any) { e.logf(slog.LevelInfo, format, args...) }
+func (e echoLogger) Printj(j log.JSON)                 { e.logj(slog.LevelInfo, j) }
+func (e echoLogger) Debug(i ...any)                    { e.log(slog.LevelDebug, i...) }
+func (e echoLogger) Debugf(format string, args ...any) { e.logf(slog.LevelDebug, format, args...) }
+func (e echoLogger) Debugj(j log.JSON)                 { e.logj(slog.LevelDebug, j) }
+func (e echoLogger) Info(i ...any)                     { e.log(slog.LevelInfo, i...) }
+func (e echoLogger) Infof(format string, args ...any)  { e.logf(slog.LevelInfo, format, args...) }
+func (e echoLogger) Infoj(j log.JSON)                  { e.logj(slog.LevelInfo, j) }
+func (e echoLogger) Warn(i ...any)                     { e.log(slog.LevelWarn, i...) }
+func (e echoLogger) Warnf(format string, args ...any)  { e.logf(slog.LevelWarn, format, args...) }
+func (e echoLogger) Warnj(j log.JSON)                  { e.logj(slog.LevelWarn, j) }
+func (e echoLogger) Error(i ...any)                    { e.log(slog.LevelError, i...) }
+func (e echoLogger) Errorf(format string, args ...any) { e.logf(slog.LevelError, format, args...) }
+func (e echoLogger) Errorj(j log.JSON)                 { e.logj(slog.LevelError, j) }
+
+func (e echoLogger) Fatal(i ...any) {
+	e.log(slog.LevelError, i...)
+	os.Exit(1)
+}
+
+func (e echoLogger) Fatalf(format string, args ...any) {
+	e.logf(slog.LevelError, format, args...)
+	os.Exit(1)
+}
+
+func (e echoLogger) Fatalj(j log.JSON) {
+	e.logj(slog.LevelError, j)
+	os.Exit(1)
+}
+
+func (e echoLogger) Panic(i ...any) {
+	e.log(slog.LevelError, i...)
+	panic(fmt.Sprint(i...))
+}
+
+func (e echoLogger) Panicf(format string, args ...any) {
+	e.logf(slog.LevelError, format, args...)
+	panic(fmt.Sprintf(format, args...))
+}
+
+func (e echoLogger) Panicj(j log.JSON) {
+	e.logj(slog.LevelError, j)
+	panic(j)
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/vpbuyanov/syncplay/internal/config"
+	"github.com/vpbuyanov/syncplay/internal/logging"
+)
+
+// syncBuffer — буфер логов, в который пишут горутины сервера.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// captureLogs подменяет логгер по умолчанию на время теста.
+func captureLogs(t *testing.T) *syncBuffer {
+	t.Helper()
+
+	out := &syncBuffer{}
+	l, err := logging.New(out, config.Log{}, slog.LevelInfo)
+	require.NoError(t, err)
+
+	prev := slog.Default()
+	slog.SetDefault(l)
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	return out
+}
+
+func TestServer_RequestIDAndAccessLog(t *testing.T) {
+	logs := captureLogs(t)
+
+	srv, err := NewServer(&config.Config{}, nil)
+	require.NoError(t, err)
+
+	// Id клиента сохраняется и попадает в access log
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/info", nil)
+	req.Header.Set(echo.HeaderXRequestID, "req-42")
+	rec := httptest.NewRecorder()
+	srv.e.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "req-42", rec.Header().Get(echo.HeaderXRequestID))
+	assert.Contains(t, logs.String(), "msg=request method=GET path=/api/v1/info route=/api/v1/info status=200")
+	assert.Contains(t, logs.String(), "request_id=req-42")
+
+	// Без заголовка id генерируется
+	rec = httptest.NewRecorder()
+	srv.e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/nope", nil))
+	assert.NotEmpty(t, rec.Header().Get(echo.HeaderXRequestID))
+	assert.Contains(t, logs.String(), "status=404")
+
+	// Пробы в access log не попадают
+	rec = httptest.NewRecorder()
+	srv.e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, logs.String(), "/healthz")
+}
+
+func TestConnectRoomWS_LogsCarryRoomAndPeer(t *testing.T) {
+	clearRooms()
+	logs := captureLogs(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockModel := NewMockmodelRoom(ctrl)
+	mockModel.EXPECT().RoomExistsUUID(gomock.Any(), gomock.Any()).Return(true, nil)
+	mockModel.EXPECT().TouchRoomUUID(gomock.Any(), gomock.Any()).Return(errors.New("db failure")).AnyTimes()
+	srv := &Server{m: mockModel}
+
+	e := echo.New()
+	e.GET("/ws/:roomID", func(c echo.Context) error {
+		return srv.ConnectRoomWS(c, uuid.MustParse(c.Param("roomID")))
+	})
+
+	ts := httptest.NewServer(e)
+	defer ts.Close()
+
+	roomID := uuid.NewString()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/ws/"+roomID, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	var welcome message
+	require.NoError(t, readJSONWithTimeout(t, conn, &welcome))
+
+	require.Eventually(t, func() bool {
+		return strings.Contains(logs.String(), "failed to touch room")
+	}, 3*time.Second, 10*time.Millisecond)
+	assert.Contains(t, logs.String(), "room_id="+roomID+" peer_id="+welcome.ID)
+}
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/vpbuyanov/syncplay/internal/metrics"
+)
+
+var (
+	httpRequests = promauto.With(metrics.Registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route and status.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = promauto.With(metrics.Registry).NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	wsConnections = promauto.With(metrics.Registry).NewGauge(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Name:      "ws_connections",
+		Help:      "Open WebSocket connections.",
+	})
+
+	wsMessages = promauto.With(metrics.Registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "ws_messages_relayed_total",
+		Help:      "Messages received from peers and relayed, by type.",
+	}, []string{"type"})
+
+	wsDropped = promauto.With(metrics.Registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "ws_messages_dropped_total",
+		Help:      "Messages from peers that were not relayed: unknown type or recipient, or rate limit.",
+	}, []string{"type"})
+
+	originRejected = promauto.With(metrics.Registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "origin_rejected_total",
+		Help:      "Requests from origins outside the allowlist, by kind (cors, ws).",
+	}, []string{"kind"})
+
+	wsWriteErrors = promauto.With(metrics.Registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "ws_write_errors_total",
+		Help:      "Failed writes to WebSocket connections, by message type.",
+	}, []string{"type"})
+)
+
+// roomsCollector снимает число комнат и участников с реестра комнат
+// в момент сбора метрик.
+type roomsCollector struct {
+	rooms *prometheus.Desc
+	peers *prometheus.Desc
+}
+
+func newRoomsCollector() *roomsCollector {
+	return &roomsCollector{
+		rooms: prometheus.NewDesc(metrics.Namespace+"_rooms_active",
+			"Rooms with connected peers.", nil, nil),
+		peers: prometheus.NewDesc(metrics.Namespace+"_room_peers",
+			"Connected peers per active room.", nil, nil),
+	}
+}
+
+// peerBuckets — границы гистограммы участников в комнате.
+var peerBuckets = []float64{1, 2, 3, 4, 6, 8, 12, 16, 32}
+
+func (c *roomsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.rooms
+	ch <- c.peers
+}
+
+func (c *roomsCollector) Collect(ch chan<- prometheus.Metric) {
+	counts := roomPeerCounts()
+
+	buckets := make(map[float64]uint64, len(peerBuckets))
+	var sum float64
+	for _, n := range counts {
+		sum += float64(n)
+		for _, b := range peerBuckets {
+			if float64(n) <= b {
+				buckets[b]++
+			}
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.rooms, prometheus.GaugeValue, float64(len(counts)))
+	ch <- prometheus.MustNewConstHistogram(c.peers, uint64(len(counts)), sum, buckets)
+}
+
+func init() {
+	metrics.Registry.MustRegister(newRoomsCollector())
+}
+
+// observeHTTP считает запросы и их длительность по шаблону маршрута.
+// WebSocket и /metrics не учитываются: у соединений WS своя метрика.
+func observeHTTP(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		path := c.Path()
+		if path == metricsPath || strings.HasPrefix(path, "/api/v1/ws/") || strings.HasPrefix(path, "/api/v2/ws/") {
+			return next(c)
+		}
+
+		start := time.Now()
+
+		err := next(c)
+		if err != nil {
+			// Статус ответа определяется обработчиком ошибок
+			c.Error(err)
+		}
+
+		route := path
+		if route == "" {
+			route = "unmatched"
+		}
+
+		method := c.Request().Method
+		httpRequests.WithLabelValues(method, route, strconv.Itoa(c.Response().Status)).Inc()
+		httpDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+
+		return nil
+	}
+}
+
+// messageType — тип исходящего кадра для меток метрик.
+func messageType(v any) string {
+	if m, ok := v.(message); ok {
+		return m.Type
+	}
+
+	return "unknown"
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vpbuyanov/syncplay/internal/config"
+)
+
+func TestServer_Metrics(t *testing.T) {
+	httpRequests.Reset()
+
+	srv, err := NewServer(&config.Config{}, nil)
+	require.NoError(t, err)
+
+	for _, path := range []string{"/api/v1/info", "/api/v1/info", "/nope"} {
+		rec := httptest.NewRecorder()
+		srv.e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	rec := httptest.NewRecorder()
+	srv.e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	body := rec.Body.String()
+	assert.Contains(t, body, `syncplay_http_requests_total{method="GET",route="/api/v1/info",status="200"} 2`)
+	assert.Contains(t, body, `status="404"`)
+	assert.Contains(t, body, `syncplay_http_request_duration_seconds_bucket{method="GET",route="/api/v1/info"`)
+	assert.Contains(t, body, "syncplay_rooms_active")
+	assert.Contains(t, body, "go_goroutines")
+	assert.NotContains(t, body, `route="/metrics"`)
+}
+
+func TestServer_MetricsAddr(t *testing.T) {
+	srv, err := NewServer(&config.Config{Server: config.Server{MetricsAddr: "127.0.0.1:0"}}, nil)
+	require.NoError(t, err)
+
+	// На основном порту метрик нет
+	rec := httptest.NewRecorder()
+	srv.e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	srv.admin.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestRoomsCollector(t *testing.T) {
+	clearRooms()
+	defer clearRooms()
+
+	roomsMu.Lock()
+	for _, peers := range []int{1, 3, 3} {
+		sess := &roomSession{Peers: make(map[string]*peer)}
+		for range peers {
+			sess.Peers[uuid.NewString()] = &peer{}
+		}
+		rooms[uuid.New()] = sess
+	}
+	roomsMu.Unlock()
+
+	expected := `
+# HELP syncplay_room_peers Connected peers per active room.
+# TYPE syncplay_room_peers histogram
+syncplay_room_peers_bucket{le="1"} 1
+syncplay_room_peers_bucket{le="2"} 1
+syncplay_room_peers_bucket{le="3"} 3
+syncplay_room_peers_bucket{le="4"} 3
+syncplay_room_peers_bucket{le="6"} 3
+syncplay_room_peers_bucket{le="8"} 3
+syncplay_room_peers_bucket{le="12"} 3
+syncplay_room_peers_bucket{le="16"} 3
+syncplay_room_peers_bucket{le="32"} 3
+syncplay_room_peers_bucket{le="+Inf"} 3
+syncplay_room_peers_sum 7
+syncplay_room_peers_count 3
+# HELP syncplay_rooms_active Rooms with connected peers.
+# TYPE syncplay_rooms_active gauge
+syncplay_rooms_active 3
+`
+	require.NoError(t, testutil.CollectAndCompare(newRoomsCollector(), strings.NewReader(expected)))
+}
+
+func TestMessageType(t *testing.T) {
+	assert.Equal(t, "welcome", messageType(message{Type: "welcome"}))
+	assert.Equal(t, "unknown", messageType(map[string]string{"type": "welcome"}))
+}
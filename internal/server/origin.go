@@ -0,0 +1,76 @@
+package server
+
+import (
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+// originAllowed сообщает, входит ли origin в список. Шаблон "*" разрешает
+// любой источник, "https://*.example.com" — любой поддомен example.com
+// по https, но не сам example.com.
+func originAllowed(patterns []string, origin string) bool {
+	if len(patterns) == 0 || slices.Contains(patterns, "*") {
+		return true
+	}
+
+	u, err := url.Parse(strings.ToLower(origin))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return false
+	}
+
+	for _, p := range patterns {
+		scheme, host, ok := strings.Cut(strings.TrimSuffix(strings.ToLower(p), "/"), "://")
+		if !ok || scheme != u.Scheme {
+			continue
+		}
+
+		if parent, wildcard := strings.CutPrefix(host, "*."); wildcard {
+			if strings.HasSuffix(u.Host, "."+parent) {
+				return true
+			}
+			continue
+		}
+
+		if host == u.Host {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allowOrigin — проверка источника для CORS. Отказ не прерывает запрос:
+// браузер сам не отдаст ответ странице без CORS-заголовков.
+func (s *Server) allowOrigin(origin string) (bool, error) {
+	if originAllowed(s.currentTunables().AllowedOrigins, origin) {
+		return true, nil
+	}
+
+	// Отказы считает originRejected, а в лог они пишутся только на debug:
+	// Origin подделывается любым клиентом и иначе засорил бы лог
+	originRejected.WithLabelValues("cors").Inc()
+	slog.Debug("origin rejected", "kind", "cors", "origin", origin)
+
+	return false, nil
+}
+
+// checkWSOrigin отклоняет апгрейд со страниц вне списка. Запросы без Origin
+// приходят не из браузера и пропускаются, как и в gorilla/websocket.
+func (s *Server) checkWSOrigin(c echo.Context) error {
+	origin := c.Request().Header.Get(echo.HeaderOrigin)
+	if origin == "" || originAllowed(s.currentTunables().AllowedOrigins, origin) {
+		return nil
+	}
+
+	originRejected.WithLabelValues("ws").Inc()
+	slog.DebugContext(c.Request().Context(), "origin rejected", "kind", "ws", "origin", origin)
+
+	return errors.Wrap(model.ErrForbidden, "origin not allowed")
+}
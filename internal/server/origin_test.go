@@ -0,0 +1,85 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestOriginAllowed(t *testing.T) {
+	patterns := []string{"https://syncplay.example", "https://*.learnio.space", "http://localhost:5173"}
+
+	tests := map[string]bool{
+		"https://syncplay.example":         true,
+		"HTTPS://SyncPlay.Example":         true,
+		"http://syncplay.example":          false,
+		"https://evil.example":             false,
+		"https://app.learnio.space":        true,
+		"https://a.b.learnio.space":        true,
+		"https://learnio.space":            false,
+		"https://evillearnio.space":        false,
+		"https://app.learnio.space:8443":   false,
+		"http://localhost:5173":            true,
+		"http://localhost:3000":            false,
+		"null":                             false,
+		"https://syncplay.example.evil.io": false,
+	}
+
+	for origin, want := range tests {
+		assert.Equal(t, want, originAllowed(patterns, origin), origin)
+	}
+
+	assert.True(t, originAllowed(nil, "https://any.example"))
+	assert.True(t, originAllowed([]string{"*"}, "https://any.example"))
+}
+
+func TestConnectRoomWS_OriginRejected(t *testing.T) {
+	clearRooms()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockModel := NewMockmodelRoom(ctrl)
+	mockModel.EXPECT().RoomExistsUUID(gomock.Any(), gomock.Any()).Return(true, nil)
+	mockModel.EXPECT().TouchRoomUUID(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	srv := &Server{m: mockModel}
+	srv.SetTunables(Tunables{AllowedOrigins: []string{"https://*.syncplay.example"}})
+
+	e := echo.New()
+	e.HTTPErrorHandler = srv.handleError
+	e.GET("/ws/:roomID", func(c echo.Context) error {
+		return srv.ConnectRoomWS(c, uuid.MustParse(c.Param("roomID")))
+	})
+
+	ts := httptest.NewServer(e)
+	defer ts.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws/" + uuid.NewString()
+	before := testutil.ToFloat64(originRejected.WithLabelValues("ws"))
+
+	// Чужая страница получает 403 до апгрейда, обращения к модели нет
+	_, resp, err := websocket.DefaultDialer.Dial(wsURL, http.Header{"Origin": {"https://evil.example"}})
+	require.Error(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Equal(t, before+1, testutil.ToFloat64(originRejected.WithLabelValues("ws")))
+
+	// Поддомен из списка подключается
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, http.Header{"Origin": {"https://app.syncplay.example"}})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	var welcome message
+	require.NoError(t, readJSONWithTimeout(t, conn, &welcome))
+	assert.Equal(t, "welcome", welcome.Type)
+}
@@ -0,0 +1,210 @@
+package server
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+
+	"github.com/vpbuyanov/syncplay/internal/config"
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+const (
+	// limiterSweepInterval — как часто из limiterSet удаляются полные корзины.
+	limiterSweepInterval = time.Minute
+	// rateLimitNotice — как часто участнику напоминают о превышении лимита.
+	rateLimitNotice = time.Second
+)
+
+// limiterSet — token bucket на каждый ключ (IP, участник, комната).
+// Нулевое значение ничего не ограничивает.
+type limiterSet struct {
+	mu      sync.Mutex
+	enabled bool
+	limit   rate.Limit
+	burst   int
+	buckets map[string]*rate.Limiter
+	swept   time.Time
+}
+
+// set меняет лимит, в том числе у уже созданных корзин.
+func (l *limiterSet) set(r float64, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.enabled = r > 0
+	l.limit = rate.Limit(r)
+	l.burst = burst
+
+	for _, b := range l.buckets {
+		b.SetLimit(l.limit)
+		b.SetBurst(l.burst)
+	}
+}
+
+// allow расходует токен ключа. Если токенов нет, возвращает, через
+// сколько он появится.
+func (l *limiterSet) allow(key string) (bool, time.Duration) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.enabled {
+		return true, 0
+	}
+
+	if now.Sub(l.swept) > limiterSweepInterval {
+		l.sweep(now)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		if l.buckets == nil {
+			l.buckets = make(map[string]*rate.Limiter)
+		}
+		b = rate.NewLimiter(l.limit, l.burst)
+		l.buckets[key] = b
+	}
+
+	r := b.ReserveN(now, 1)
+	if !r.OK() {
+		return false, time.Second
+	}
+	if delay := r.DelayFrom(now); delay > 0 {
+		r.CancelAt(now)
+		return false, delay
+	}
+
+	return true, 0
+}
+
+func (l *limiterSet) forget(key string) {
+	l.mu.Lock()
+	delete(l.buckets, key)
+	l.mu.Unlock()
+}
+
+// sweep удаляет полностью восстановившиеся корзины: для них новая
+// корзина ничем не отличается от старой.
+func (l *limiterSet) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		if b.TokensAt(now) >= float64(l.burst) {
+			delete(l.buckets, key)
+		}
+	}
+	l.swept = now
+}
+
+// rateLimiter объединяет лимиты сервера; нулевое значение ничего не ограничивает.
+type rateLimiter struct {
+	http       limiterSet
+	createRoom limiterSet
+	auth       limiterSet
+	peer       limiterSet
+	room       limiterSet
+
+	wsMu    sync.Mutex
+	wsPerIP map[string]int
+}
+
+func (r *rateLimiter) apply(cfg config.RateLimit) {
+	r.http.set(cfg.HTTPRate, cfg.HTTPBurst)
+	r.createRoom.set(cfg.CreateRoomRate, cfg.CreateRoomBurst)
+	r.auth.set(cfg.AuthRate, cfg.AuthBurst)
+	r.peer.set(cfg.PeerRate, cfg.PeerBurst)
+	r.room.set(cfg.RoomRate, cfg.RoomBurst)
+}
+
+// acquireWS занимает слот WebSocket-соединения для ip; max = 0 — без ограничения.
+// Занятый слот освобождается releaseWS.
+func (r *rateLimiter) acquireWS(ip string, max int) bool {
+	r.wsMu.Lock()
+	defer r.wsMu.Unlock()
+
+	if max > 0 && r.wsPerIP[ip] >= max {
+		return false
+	}
+
+	if r.wsPerIP == nil {
+		r.wsPerIP = make(map[string]int)
+	}
+	r.wsPerIP[ip]++
+
+	return true
+}
+
+func (r *rateLimiter) releaseWS(ip string) {
+	r.wsMu.Lock()
+	defer r.wsMu.Unlock()
+
+	if r.wsPerIP[ip]--; r.wsPerIP[ip] <= 0 {
+		delete(r.wsPerIP, ip)
+	}
+}
+
+// rateLimit ограничивает запросы с одного IP; создание комнат, вход
+// и регистрация ограничены дополнительно. Пробы и /metrics не ограничиваются.
+func (s *Server) rateLimit(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		path := c.Path()
+		if isProbe(path) || path == metricsPath {
+			return next(c)
+		}
+
+		ip := c.RealIP()
+		if ok, wait := s.limits.http.allow(ip); !ok {
+			return tooManyRequests(c, wait, "too many requests")
+		}
+
+		if c.Request().Method == http.MethodPost && path == "/api/v1/rooms" {
+			if ok, wait := s.limits.createRoom.allow(ip); !ok {
+				return tooManyRequests(c, wait, "too many rooms created")
+			}
+		}
+
+		if c.Request().Method == http.MethodPost && (path == "/api/v1/auth/login" || path == "/api/v1/auth/register") {
+			if ok, wait := s.limits.auth.allow(ip); !ok {
+				return tooManyRequests(c, wait, "too many sign-in attempts")
+			}
+		}
+
+		return next(c)
+	}
+}
+
+func tooManyRequests(c echo.Context, wait time.Duration, msg string) error {
+	c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+
+	return errors.Wrap(model.ErrRateLimited, msg)
+}
+
+// ipExtractor определяет адрес клиента. X-Forwarded-For учитывается только
+// от доверенных прокси, иначе клиент мог бы подставить любой адрес и обойти лимиты.
+func ipExtractor(trusted []string) (echo.IPExtractor, error) {
+	if len(trusted) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+
+	opts := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, cidr := range trusted {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "trusted proxy %q", cidr)
+		}
+		opts = append(opts, echo.TrustIPRange(ipNet))
+	}
+
+	return echo.ExtractIPFromXFFHeader(opts...), nil
+}
@@ -0,0 +1,182 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/vpbuyanov/syncplay/internal/config"
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+func TestLimiterSet(t *testing.T) {
+	var l limiterSet
+
+	// Нулевое значение ничего не ограничивает
+	for range 10 {
+		ok, _ := l.allow("a")
+		require.True(t, ok)
+	}
+
+	l.set(1, 2)
+	for range 2 {
+		ok, _ := l.allow("a")
+		require.True(t, ok)
+	}
+	ok, wait := l.allow("a")
+	assert.False(t, ok)
+	assert.Positive(t, wait)
+
+	// У другого ключа своя корзина
+	ok, _ = l.allow("b")
+	assert.True(t, ok)
+
+	// Новый лимит применяется к существующим корзинам
+	l.set(0, 0)
+	ok, _ = l.allow("a")
+	assert.True(t, ok)
+
+	// Восстановившиеся корзины удаляются
+	l.set(1000, 1)
+	_, _ = l.allow("c")
+	l.sweep(time.Now().Add(time.Second))
+	assert.Empty(t, l.buckets)
+}
+
+func TestRateLimiter_WS(t *testing.T) {
+	var r rateLimiter
+
+	require.True(t, r.acquireWS("1.1.1.1", 2))
+	require.True(t, r.acquireWS("1.1.1.1", 2))
+	assert.False(t, r.acquireWS("1.1.1.1", 2))
+	assert.True(t, r.acquireWS("2.2.2.2", 2))
+
+	r.releaseWS("1.1.1.1")
+	assert.True(t, r.acquireWS("1.1.1.1", 2))
+
+	// 0 — без ограничения
+	assert.True(t, r.acquireWS("1.1.1.1", 0))
+}
+
+func TestServer_RateLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockModel := NewMockmodelRoom(ctrl)
+	mockModel.EXPECT().CreateRoom(gomock.Any(), gomock.Any()).Return(model.CreatedRoom{ID: uuid.NewString()}, nil)
+
+	srv, err := NewServer(&config.Config{
+		RateLimit: config.RateLimit{
+			HTTPRate: 0.01, HTTPBurst: 3,
+			CreateRoomRate: 0.01, CreateRoomBurst: 1,
+			AuthRate: 0.01, AuthBurst: 1,
+		},
+	}, mockModel)
+	require.NoError(t, err)
+
+	do := func(method, path, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader("{}"))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.RemoteAddr = ip + ":1234"
+		rec := httptest.NewRecorder()
+		srv.e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Создание комнат ограничено сильнее остальных запросов
+	assert.Equal(t, http.StatusOK, do(http.MethodPost, "/api/v1/rooms", "10.0.0.1").Code)
+	rec := do(http.MethodPost, "/api/v1/rooms", "10.0.0.1")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"code":"rate-limited"`)
+	assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+
+	// Общий лимит по IP исчерпан третьим запросом
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/api/v1/info", "10.0.0.1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, do(http.MethodGet, "/api/v1/info", "10.0.0.1").Code)
+
+	// Вход и регистрация — общий отдельный лимит
+	assert.Equal(t, http.StatusNotFound, do(http.MethodPost, "/api/v1/auth/login", "10.0.0.3").Code)
+	assert.Equal(t, http.StatusTooManyRequests, do(http.MethodPost, "/api/v1/auth/register", "10.0.0.3").Code)
+
+	// Другой IP и пробы не ограничены
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/api/v1/info", "10.0.0.2").Code)
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/healthz", "10.0.0.1").Code)
+}
+
+func TestIPExtractor(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "172.18.0.2:4321"
+	req.Header.Set(echo.HeaderXForwardedFor, "203.0.113.7")
+
+	// Без доверенных прокси заголовок игнорируется
+	extract, err := ipExtractor(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "172.18.0.2", extract(req))
+
+	extract, err = ipExtractor([]string{"172.16.0.0/12"})
+	require.NoError(t, err)
+	assert.Equal(t, "203.0.113.7", extract(req))
+
+	// Заголовок от недоверенного адреса не учитывается
+	req.RemoteAddr = "198.51.100.1:4321"
+	assert.Equal(t, "198.51.100.1", extract(req))
+
+	_, err = ipExtractor([]string{"nope"})
+	require.Error(t, err)
+}
+
+func TestConnectRoomWS_RateLimited(t *testing.T) {
+	clearRooms()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockModel := NewMockmodelRoom(ctrl)
+	mockModel.EXPECT().RoomExistsUUID(gomock.Any(), gomock.Any()).Return(true, nil)
+	mockModel.EXPECT().TouchRoomUUID(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	srv := &Server{m: mockModel}
+	srv.SetTunables(Tunables{RateLimit: config.RateLimit{PeerRate: 0.01, PeerBurst: 1, WSPerIP: 1}})
+
+	e := echo.New()
+	e.HTTPErrorHandler = srv.handleError
+	e.GET("/ws/:roomID", func(c echo.Context) error {
+		return srv.ConnectRoomWS(c, uuid.MustParse(c.Param("roomID")))
+	})
+
+	ts := httptest.NewServer(e)
+	defer ts.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws/" + uuid.NewString()
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	var msg message
+	require.NoError(t, readJSONWithTimeout(t, conn, &msg)) // welcome
+	require.NoError(t, readJSONWithTimeout(t, conn, &msg)) // existing-peers
+
+	// Второе соединение с того же IP отклоняется до апгрейда
+	_, resp, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	require.Error(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+
+	// Первое сообщение укладывается в лимит, второе — нет
+	for range 2 {
+		require.NoError(t, conn.WriteJSON(message{Type: "signal", To: "nobody"}))
+	}
+
+	require.NoError(t, readJSONWithTimeout(t, conn, &msg))
+	assert.Equal(t, "error", msg.Type)
+	assert.JSONEq(t, `{"code":"rate-limited","detail":"too many messages, slow down"}`, string(msg.Payload))
+}
@@ -1,50 +1,232 @@
 package server
 
 import (
-	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	openapi_types "github.com/oapi-codegen/runtime/types"
+	"github.com/pkg/errors"
 
 	"github.com/vpbuyanov/syncplay/internal/gen"
+	"github.com/vpbuyanov/syncplay/internal/model"
 )
 
 func (s *Server) CreateRoom(ctx echo.Context) error {
-	id, err := s.m.CreateRoom(ctx.Request().Context())
+	if s.draining.Load() {
+		return errors.Wrap(model.ErrUnavailable, "server is shutting down")
+	}
+
+	var body gen.CreateRoomJSONRequestBody
+	if err := ctx.Bind(&body); err != nil {
+		return errors.Wrap(model.ErrInvalidArgument, "invalid request body")
+	}
+
+	meta, err := roomMetaFromParams(body)
 	if err != nil {
-		slog.Error("Msg Err", "err", err)
+		return err
+	}
+	if claims := claimsFrom(ctx); claims != nil {
+		meta.CreatedBy = claims.Owner()
+	}
 
-		return ctx.JSON(http.StatusInternalServerError, gen.ErrorResponse{
-			Detail: "something wrong",
-		})
+	room, err := s.m.CreateRoom(ctx.Request().Context(), meta)
+	if err != nil {
+		return err
 	}
 
 	uid := uuid.UUID{}
-	err = uid.Scan(id)
+	err = uid.Scan(room.ID)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, gen.ErrorResponse{
-			Detail: "can't return uuid",
-		})
+		return errors.Wrap(err, "can't return uuid")
 	}
 
 	res := gen.CreateRoom{
-		RoomId: uid,
+		RoomId:     uid,
+		OwnerToken: room.OwnerToken,
 	}
 
 	return ctx.JSON(http.StatusOK, res)
 }
 
-func (s *Server) DeleteRoom(ctx echo.Context, id openapi_types.UUID) error {
-	err := s.m.DeleteRoom(ctx.Request().Context(), id)
+func (s *Server) DeleteRoom(ctx echo.Context, id openapi_types.UUID, params gen.DeleteRoomParams) error {
+	if err := s.authorizeManager(ctx, id, params.XRoomToken); err != nil {
+		return err
+	}
+
+	var err error
+	if params.Purge != nil && *params.Purge {
+		err = s.m.PurgeRoom(ctx.Request().Context(), id)
+	} else {
+		err = s.m.DeleteRoom(ctx.Request().Context(), id)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.CloseRoom(id.String(), "deleted")
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func (s *Server) ArchiveRoom(ctx echo.Context, id openapi_types.UUID, params gen.ArchiveRoomParams) error {
+	if err := s.authorizeManager(ctx, id, params.XRoomToken); err != nil {
+		return err
+	}
+
+	err := s.m.ArchiveRoom(ctx.Request().Context(), id)
 	if err != nil {
-		slog.Error("Msg Err", "err", err)
+		return err
+	}
 
-		return ctx.JSON(http.StatusInternalServerError, gen.ErrorResponse{
-			Detail: "something wrong",
-		})
+	s.CloseRoom(id.String(), "archived")
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func (s *Server) RestoreRoom(ctx echo.Context, id openapi_types.UUID, params gen.RestoreRoomParams) error {
+	if err := s.authorizeManager(ctx, id, params.XRoomToken); err != nil {
+		return err
+	}
+
+	err := s.m.RestoreRoom(ctx.Request().Context(), id)
+	if err != nil {
+		return err
 	}
 
 	return ctx.NoContent(http.StatusNoContent)
 }
+
+// authorizeManager пускает к удалению, архиву и восстановлению комнаты
+// только её владельца: по X-Room-Token или по учётной записи создателя.
+func (s *Server) authorizeManager(ctx echo.Context, id openapi_types.UUID, token *string) error {
+	var tok, user string
+	if token != nil {
+		tok = *token
+	}
+	if claims := claimsFrom(ctx); claims != nil {
+		user = claims.Owner()
+	}
+
+	return s.m.AuthorizeManager(ctx.Request().Context(), id.String(), tok, user)
+}
+
+func (s *Server) ListRooms(ctx echo.Context, params gen.ListRoomsParams) error {
+	// Заполненность берём из живых WS-сессий, метаданные — из БД
+	peers := roomPeerCounts()
+
+	p := model.ListRoomsParams{
+		CreatedAfter:   params.CreatedAfter,
+		HasActivePeers: params.HasActivePeers,
+		ActiveIDs:      make([]uuid.UUID, 0, len(peers)),
+	}
+	if params.Limit != nil {
+		p.Limit = *params.Limit
+	}
+	if params.Cursor != nil {
+		p.Cursor = *params.Cursor
+	}
+	if params.Visibility != nil {
+		p.Visibility = string(*params.Visibility)
+	}
+	if params.Tag != nil {
+		p.Tag = *params.Tag
+	}
+	if params.Sort != nil {
+		p.Sort = string(*params.Sort)
+	}
+	if params.Mine != nil && *params.Mine {
+		claims := claimsFrom(ctx)
+		if claims == nil {
+			return errors.Wrap(model.ErrUnauthorized, "mine requires authentication")
+		}
+		p.CreatedBy = claims.Owner()
+	}
+
+	// ID комнаты — ключ для входа в неё, поэтому чужие приватные комнаты
+	// не показываем; владелец видит свои с mine=true
+	switch {
+	case p.CreatedBy != "":
+	case p.Visibility == model.VisibilityPrivate:
+		return errors.Wrap(model.ErrForbidden, "private rooms are listed only with mine=true")
+	default:
+		p.Visibility = model.VisibilityPublic
+	}
+	for id := range peers {
+		p.ActiveIDs = append(p.ActiveIDs, id)
+	}
+
+	page, err := s.m.ListRooms(ctx.Request().Context(), p)
+	if err != nil {
+		return err
+	}
+
+	res := gen.RoomList{
+		Items: make([]gen.Room, 0, len(page.Items)),
+	}
+	if page.NextCursor != "" {
+		res.NextCursor = &page.NextCursor
+	}
+
+	for _, r := range page.Items {
+		uid, err := uuid.Parse(r.ID)
+		if err != nil {
+			return errors.Wrap(err, "can't return uuid")
+		}
+
+		tags := r.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+
+		room := gen.Room{
+			RoomId:      uid,
+			Title:       r.Title,
+			Description: r.Description,
+			Visibility:  gen.RoomVisibility(r.Visibility),
+			Tags:        tags,
+			CreatedAt:   r.CreatedAt,
+			ExpiresAt:   r.ExpiresAt,
+			Peers:       peers[uid],
+		}
+		if r.CreatedBy != "" {
+			room.CreatedBy = &r.CreatedBy
+		}
+
+		res.Items = append(res.Items, room)
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
+
+func roomMetaFromParams(p gen.RoomParams) (model.RoomMeta, error) {
+	var meta model.RoomMeta
+	if p.Title != nil {
+		meta.Title = *p.Title
+	}
+	if p.Description != nil {
+		meta.Description = *p.Description
+	}
+	if p.Visibility != nil {
+		meta.Visibility = string(*p.Visibility)
+	}
+	if p.Tags != nil {
+		meta.Tags = *p.Tags
+	}
+
+	switch {
+	case p.Ttl != nil && p.ExpiresAt != nil:
+		return meta, errors.Wrap(model.ErrInvalidArgument, "specify either ttl or expires_at, not both")
+	case p.Ttl != nil:
+		if *p.Ttl < 1 {
+			return meta, errors.Wrap(model.ErrInvalidArgument, "ttl must be a positive number of seconds")
+		}
+		expiresAt := time.Now().Add(time.Duration(*p.Ttl) * time.Second)
+		meta.ExpiresAt = &expiresAt
+	case p.ExpiresAt != nil:
+		meta.ExpiresAt = p.ExpiresAt
+	}
+
+	return meta, nil
+}
@@ -1,17 +1,26 @@
 package server
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 
 	openapi_types "github.com/oapi-codegen/runtime/types"
+
+	"github.com/vpbuyanov/syncplay/internal/auth"
+	"github.com/vpbuyanov/syncplay/internal/gen"
+	"github.com/vpbuyanov/syncplay/internal/model"
 )
 
 // TestServer_CreateRoom проверяет handler CreateRoom на успех и на ошибку модели.
@@ -29,18 +38,17 @@ func TestServer_CreateRoom(t *testing.T) {
 	t.Run("успешное создание", func(t *testing.T) {
 		mockModel.
 			EXPECT().
-			CreateRoom(gomock.Any()).
-			Return(id.String(), nil)
+			CreateRoom(gomock.Any(), gomock.Any()).
+			Return(model.CreatedRoom{ID: id.String(), OwnerToken: "secret"}, nil)
 
 		req := httptest.NewRequest(http.MethodPost, "/api/v1/rooms", nil)
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
 
-		err := srv.CreateRoom(c)
-		assert.NoError(t, err)
+		handle(srv, c, srv.CreateRoom(c))
 
 		assert.Equal(t, http.StatusOK, rec.Code)
-		expectedBody := `{"room_id": "` + id.String() + `"}`
+		expectedBody := `{"room_id": "` + id.String() + `", "owner_token": "secret"}`
 		assert.JSONEq(t, expectedBody, rec.Body.String())
 		assert.Equal(t, echo.MIMEApplicationJSON, rec.Header().Get(echo.HeaderContentType))
 	})
@@ -48,19 +56,18 @@ func TestServer_CreateRoom(t *testing.T) {
 	t.Run("ошибка бизнес‑логики", func(t *testing.T) {
 		mockModel.
 			EXPECT().
-			CreateRoom(gomock.Any()).
-			Return("", errors.New("db failure"))
+			CreateRoom(gomock.Any(), gomock.Any()).
+			Return(model.CreatedRoom{}, errors.New("db failure"))
 
 		req := httptest.NewRequest(http.MethodPost, "/api/v1/rooms", nil)
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
 
-		err := srv.CreateRoom(c)
-		assert.NoError(t, err)
+		handle(srv, c, srv.CreateRoom(c))
 
 		assert.Equal(t, http.StatusInternalServerError, rec.Code)
-		assert.JSONEq(t, `{"detail":"something wrong"}`, rec.Body.String())
-		assert.Equal(t, echo.MIMEApplicationJSON, rec.Header().Get(echo.HeaderContentType))
+		assert.JSONEq(t, `{"type":"about:blank","title":"Internal Server Error","status":500,"code":"internal","detail":"something wrong"}`, rec.Body.String())
+		assert.Equal(t, mimeProblemJSON, rec.Header().Get(echo.HeaderContentType))
 	})
 }
 
@@ -82,6 +89,9 @@ func TestServer_DeleteRoom(t *testing.T) {
 	err = apiID.Scan(idStr)
 	assert.NoError(t, err)
 
+	token := "owner-secret"
+	mockModel.EXPECT().AuthorizeManager(gomock.Any(), idStr, token, "").Return(nil).AnyTimes()
+
 	t.Run("успешное удаление", func(t *testing.T) {
 		mockModel.
 			EXPECT().
@@ -94,8 +104,7 @@ func TestServer_DeleteRoom(t *testing.T) {
 		c.SetParamNames("id")
 		c.SetParamValues(idStr)
 
-		err := srv.DeleteRoom(c, apiID)
-		assert.NoError(t, err)
+		handle(srv, c, srv.DeleteRoom(c, apiID, gen.DeleteRoomParams{XRoomToken: &token}))
 
 		assert.Equal(t, http.StatusNoContent, rec.Code)
 		assert.Empty(t, rec.Body.String())
@@ -113,11 +122,293 @@ func TestServer_DeleteRoom(t *testing.T) {
 		c.SetParamNames("id")
 		c.SetParamValues(idStr)
 
-		err := srv.DeleteRoom(c, apiID)
-		assert.NoError(t, err)
+		handle(srv, c, srv.DeleteRoom(c, apiID, gen.DeleteRoomParams{XRoomToken: &token}))
 
 		assert.Equal(t, http.StatusInternalServerError, rec.Code)
-		assert.JSONEq(t, `{"detail":"something wrong"}`, rec.Body.String())
-		assert.Equal(t, echo.MIMEApplicationJSON, rec.Header().Get(echo.HeaderContentType))
+		assert.JSONEq(t, `{"type":"about:blank","title":"Internal Server Error","status":500,"code":"internal","detail":"something wrong"}`, rec.Body.String())
+		assert.Equal(t, mimeProblemJSON, rec.Header().Get(echo.HeaderContentType))
+	})
+}
+
+// TestServer_RoomLifecycle проверяет окончательное удаление, архивирование и восстановление.
+func TestServer_RoomLifecycle(t *testing.T) {
+	clearRooms()
+
+	e := echo.New()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockModel := NewMockmodelRoom(ctrl)
+	srv := &Server{m: mockModel}
+
+	id := uuid.New()
+	purge := true
+	token := "owner-secret"
+
+	t.Run("без прав владельца", func(t *testing.T) {
+		// Ни PurgeRoom, ни ArchiveRoom, ни RestoreRoom модели не вызываются
+		mockModel.EXPECT().AuthorizeManager(gomock.Any(), id.String(), "", "").
+			Return(errors.Join(model.ErrForbidden, errors.New("owner token required"))).Times(3)
+
+		for _, call := range []func(c echo.Context) error{
+			func(c echo.Context) error { return srv.DeleteRoom(c, id, gen.DeleteRoomParams{Purge: &purge}) },
+			func(c echo.Context) error { return srv.ArchiveRoom(c, id, gen.ArchiveRoomParams{}) },
+			func(c echo.Context) error { return srv.RestoreRoom(c, id, gen.RestoreRoomParams{}) },
+		} {
+			rec := httptest.NewRecorder()
+			c := e.NewContext(httptest.NewRequest(http.MethodPost, "/", nil), rec)
+			handle(srv, c, call(c))
+			assert.Equal(t, http.StatusForbidden, rec.Code)
+		}
+	})
+
+	t.Run("создатель комнаты", func(t *testing.T) {
+		mockModel.EXPECT().AuthorizeManager(gomock.Any(), id.String(), "", "jwt:|alice").Return(nil)
+		mockModel.EXPECT().ArchiveRoom(gomock.Any(), id).Return(nil)
+
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodPost, "/", nil), rec)
+		c.Set(claimsKey, &auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "alice"}})
+		handle(srv, c, srv.ArchiveRoom(c, id, gen.ArchiveRoomParams{}))
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+
+	mockModel.EXPECT().AuthorizeManager(gomock.Any(), id.String(), token, "").Return(nil).AnyTimes()
+
+	t.Run("окончательное удаление", func(t *testing.T) {
+		mockModel.EXPECT().PurgeRoom(gomock.Any(), id).Return(nil)
+
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodDelete, "/", nil), rec)
+		handle(srv, c, srv.DeleteRoom(c, id, gen.DeleteRoomParams{Purge: &purge, XRoomToken: &token}))
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+
+	t.Run("удаление несуществующей комнаты", func(t *testing.T) {
+		mockModel.EXPECT().DeleteRoom(gomock.Any(), id).Return(errors.Join(model.ErrNotFound, errors.New("no delete room")))
+
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodDelete, "/", nil), rec)
+		handle(srv, c, srv.DeleteRoom(c, id, gen.DeleteRoomParams{XRoomToken: &token}))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("архивирование", func(t *testing.T) {
+		mockModel.EXPECT().ArchiveRoom(gomock.Any(), id).Return(nil)
+
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodPost, "/", nil), rec)
+		handle(srv, c, srv.ArchiveRoom(c, id, gen.ArchiveRoomParams{XRoomToken: &token}))
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+
+	t.Run("восстановление", func(t *testing.T) {
+		mockModel.EXPECT().RestoreRoom(gomock.Any(), id).Return(nil)
+
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodPost, "/", nil), rec)
+		handle(srv, c, srv.RestoreRoom(c, id, gen.RestoreRoomParams{XRoomToken: &token}))
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+
+	t.Run("восстановление за пределами окна", func(t *testing.T) {
+		mockModel.EXPECT().RestoreRoom(gomock.Any(), id).Return(model.ErrNotFound)
+
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodPost, "/", nil), rec)
+		handle(srv, c, srv.RestoreRoom(c, id, gen.RestoreRoomParams{XRoomToken: &token}))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+// TestServer_CreateRoom_Meta проверяет передачу метаданных из тела запроса и ошибку валидации.
+func TestServer_CreateRoom_Meta(t *testing.T) {
+	e := echo.New()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockModel := NewMockmodelRoom(ctrl)
+	srv := &Server{m: mockModel}
+
+	t.Run("метаданные из тела", func(t *testing.T) {
+		id := uuid.New()
+		mockModel.
+			EXPECT().
+			CreateRoom(gomock.Any(), model.RoomMeta{
+				Title:      "Movie night",
+				Visibility: model.VisibilityPrivate,
+				Tags:       []string{"movies"},
+			}).
+			Return(model.CreatedRoom{ID: id.String()}, nil)
+
+		body := `{"title":"Movie night","visibility":"private","tags":["movies"]}`
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/rooms", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		c := e.NewContext(req, rec)
+		handle(srv, c, srv.CreateRoom(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("ошибка валидации", func(t *testing.T) {
+		mockModel.
+			EXPECT().
+			CreateRoom(gomock.Any(), gomock.Any()).
+			Return(model.CreatedRoom{}, errors.Join(model.ErrInvalidArgument, errors.New("unknown visibility")))
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/rooms", strings.NewReader(`{"visibility":"secret"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		c := e.NewContext(req, rec)
+		handle(srv, c, srv.CreateRoom(c))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("ttl переводится в expires_at", func(t *testing.T) {
+		start := time.Now()
+		mockModel.
+			EXPECT().
+			CreateRoom(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, meta model.RoomMeta) (model.CreatedRoom, error) {
+				require.NotNil(t, meta.ExpiresAt)
+				assert.WithinDuration(t, start.Add(time.Hour), *meta.ExpiresAt, 5*time.Second)
+				return model.CreatedRoom{ID: uuid.NewString()}, nil
+			})
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/rooms", strings.NewReader(`{"ttl":3600}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		c := e.NewContext(req, rec)
+		handle(srv, c, srv.CreateRoom(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("ttl и expires_at одновременно", func(t *testing.T) {
+		body := `{"ttl":60,"expires_at":"2030-01-01T00:00:00Z"}`
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/rooms", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		c := e.NewContext(req, rec)
+		handle(srv, c, srv.CreateRoom(c))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+// TestServer_ListRooms проверяет выдачу списка комнат с заполненностью из активных сессий.
+func TestServer_ListRooms(t *testing.T) {
+	clearRooms()
+	defer clearRooms()
+
+	e := echo.New()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockModel := NewMockmodelRoom(ctrl)
+	srv := &Server{m: mockModel}
+
+	busy := uuid.New()
+	idle := uuid.New()
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	roomsMu.Lock()
+	rooms[busy] = &roomSession{Peers: map[string]*peer{"p1": {}, "p2": {}}}
+	roomsMu.Unlock()
+
+	t.Run("успешный список", func(t *testing.T) {
+		limit := 2
+		sort := gen.Title
+
+		mockModel.
+			EXPECT().
+			ListRooms(gomock.Any(), model.ListRoomsParams{
+				Limit:      2,
+				Visibility: model.VisibilityPublic,
+				Sort:       model.SortTitleAsc,
+				ActiveIDs:  []uuid.UUID{busy},
+			}).
+			Return(model.RoomPage{
+				Items: []model.RoomInfo{
+					{ID: busy.String(), RoomMeta: model.RoomMeta{Title: "a", Visibility: "public", Tags: []string{"x"}}, CreatedAt: createdAt},
+					{ID: idle.String(), RoomMeta: model.RoomMeta{Title: "b", Visibility: "private"}, CreatedAt: createdAt},
+				},
+				NextCursor: "next",
+			}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/rooms?limit=2&sort=title", nil)
+		rec := httptest.NewRecorder()
+
+		c := e.NewContext(req, rec)
+		handle(srv, c, srv.ListRooms(c, gen.ListRoomsParams{Limit: &limit, Sort: &sort}))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{
+			"items": [
+				{"room_id":"`+busy.String()+`","title":"a","description":"","visibility":"public","tags":["x"],"created_at":"2025-01-02T03:04:05Z","peers":2},
+				{"room_id":"`+idle.String()+`","title":"b","description":"","visibility":"private","tags":[],"created_at":"2025-01-02T03:04:05Z","peers":0}
+			],
+			"next_cursor": "next"
+		}`, rec.Body.String())
+	})
+
+	t.Run("приватные только владельцу", func(t *testing.T) {
+		private := gen.Private
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/rooms?visibility=private", nil)
+		rec := httptest.NewRecorder()
+
+		c := e.NewContext(req, rec)
+		handle(srv, c, srv.ListRooms(c, gen.ListRoomsParams{Visibility: &private}))
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+
+		// С mine=true владелец видит свои приватные комнаты
+		mine := true
+		mockModel.
+			EXPECT().
+			ListRooms(gomock.Any(), model.ListRoomsParams{
+				Visibility: model.VisibilityPrivate,
+				CreatedBy:  "jwt:|alice",
+				ActiveIDs:  []uuid.UUID{busy},
+			}).
+			Return(model.RoomPage{}, nil)
+
+		req = httptest.NewRequest(http.MethodGet, "/api/v1/rooms?visibility=private&mine=true", nil)
+		rec = httptest.NewRecorder()
+
+		c = e.NewContext(req, rec)
+		c.Set(claimsKey, &auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "alice"}})
+		handle(srv, c, srv.ListRooms(c, gen.ListRoomsParams{Visibility: &private, Mine: &mine}))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("невалидные параметры", func(t *testing.T) {
+		mockModel.
+			EXPECT().
+			ListRooms(gomock.Any(), gomock.Any()).
+			Return(model.RoomPage{}, errors.Join(model.ErrInvalidArgument, errors.New("malformed cursor")))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/rooms?cursor=bad", nil)
+		rec := httptest.NewRecorder()
+
+		c := e.NewContext(req, rec)
+		handle(srv, c, srv.ListRooms(c, gen.ListRoomsParams{}))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("ошибка модели", func(t *testing.T) {
+		mockModel.
+			EXPECT().
+			ListRooms(gomock.Any(), gomock.Any()).
+			Return(model.RoomPage{}, errors.New("db failure"))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/rooms", nil)
+		rec := httptest.NewRecorder()
+
+		c := e.NewContext(req, rec)
+		handle(srv, c, srv.ListRooms(c, gen.ListRoomsParams{}))
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.JSONEq(t, `{"type":"about:blank","title":"Internal Server Error","status":500,"code":"internal","detail":"something wrong"}`, rec.Body.String())
 	})
 }
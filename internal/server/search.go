@@ -0,0 +1,79 @@
+package server
+
+import (
+	"html"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/vpbuyanov/syncplay/internal/gen"
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+var highlightReplacer = strings.NewReplacer(
+	model.HighlightStart, "<mark>",
+	model.HighlightStop, "</mark>",
+)
+
+func (s *Server) Search(ctx echo.Context, params gen.SearchParams) error {
+	p := model.SearchParams{
+		Query: params.Q,
+	}
+	if params.RoomId != nil {
+		p.RoomID = params.RoomId.String()
+	}
+	if params.Limit != nil {
+		p.Limit = *params.Limit
+	}
+	if params.XRoomToken != nil {
+		p.OwnerToken = *params.XRoomToken
+	}
+	if claims := claimsFrom(ctx); claims != nil {
+		p.User = claims.Owner()
+	}
+
+	hits, err := s.m.Search(ctx.Request().Context(), p)
+	if err != nil {
+		return err
+	}
+
+	res := gen.SearchResult{
+		Items: make([]gen.SearchHit, 0, len(hits)),
+	}
+
+	for _, h := range hits {
+		uid, err := uuid.Parse(h.RoomID)
+		if err != nil {
+			return errors.Wrap(err, "can't return uuid")
+		}
+
+		item := gen.SearchHit{
+			Kind:      gen.SearchHitKind(h.Kind),
+			RoomId:    uid,
+			Snippet:   highlightSnippet(h.Snippet),
+			Rank:      h.Rank,
+			CreatedAt: h.CreatedAt,
+		}
+
+		switch h.Kind {
+		case model.SearchKindRoom:
+			item.Title = &h.Title
+		case model.SearchKindMessage:
+			item.MessageId = &h.MessageID
+			item.Sender = &h.Sender
+		}
+
+		res.Items = append(res.Items, item)
+	}
+
+	return ctx.JSON(http.StatusOK, res)
+}
+
+// highlightSnippet экранирует пользовательский текст и заменяет маркеры
+// совпадений на <mark>, чтобы фрагмент можно было безопасно вставить в HTML.
+func highlightSnippet(s string) string {
+	return highlightReplacer.Replace(html.EscapeString(s))
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/vpbuyanov/syncplay/internal/auth"
+	"github.com/vpbuyanov/syncplay/internal/gen"
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+// TestServer_Search проверяет handler Search: подсветку, авторизацию владельца и ошибки.
+func TestServer_Search(t *testing.T) {
+	e := echo.New()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockModel := NewMockmodelRoom(ctrl)
+	srv := &Server{m: mockModel}
+
+	roomID := uuid.New()
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("публичные комнаты", func(t *testing.T) {
+		mockModel.
+			EXPECT().
+			Search(gomock.Any(), model.SearchParams{Query: "movie"}).
+			Return([]model.SearchHit{{
+				Kind:      model.SearchKindRoom,
+				RoomID:    roomID.String(),
+				Title:     "<b>Movie</b>",
+				Snippet:   "<b>⟦Movie⟧</b>",
+				Rank:      0.5,
+				CreatedAt: createdAt,
+			}}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/search?q=movie", nil)
+		rec := httptest.NewRecorder()
+
+		c := e.NewContext(req, rec)
+		handle(srv, c, srv.Search(c, gen.SearchParams{Q: "movie"}))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"items":[{
+			"kind":"room",
+			"room_id":"`+roomID.String()+`",
+			"title":"<b>Movie</b>",
+			"snippet":"&lt;b&gt;<mark>Movie</mark>&lt;/b&gt;",
+			"rank":0.5,
+			"created_at":"2025-01-02T03:04:05Z"
+		}]}`, rec.Body.String())
+	})
+
+	t.Run("чат комнаты", func(t *testing.T) {
+		token := "secret"
+		mockModel.
+			EXPECT().
+			Search(gomock.Any(), model.SearchParams{Query: "hi", RoomID: roomID.String(), OwnerToken: token}).
+			Return([]model.SearchHit{{
+				Kind:      model.SearchKindMessage,
+				RoomID:    roomID.String(),
+				MessageID: 3,
+				Sender:    "peer",
+				Snippet:   "⟦hi⟧",
+				CreatedAt: createdAt,
+			}}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/search?q=hi", nil)
+		rec := httptest.NewRecorder()
+
+		c := e.NewContext(req, rec)
+		handle(srv, c, srv.Search(c, gen.SearchParams{Q: "hi", RoomId: &roomID, XRoomToken: &token}))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"items":[{
+			"kind":"message",
+			"room_id":"`+roomID.String()+`",
+			"message_id":3,
+			"sender":"peer",
+			"snippet":"<mark>hi</mark>",
+			"rank":0,
+			"created_at":"2025-01-02T03:04:05Z"
+		}]}`, rec.Body.String())
+	})
+
+	t.Run("чат комнаты создателю без токена", func(t *testing.T) {
+		mockModel.
+			EXPECT().
+			Search(gomock.Any(), model.SearchParams{Query: "hi", RoomID: roomID.String(), User: "jwt:|alice"}).
+			Return(nil, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/search?q=hi", nil)
+		rec := httptest.NewRecorder()
+
+		c := e.NewContext(req, rec)
+		c.Set(claimsKey, &auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "alice"}})
+		handle(srv, c, srv.Search(c, gen.SearchParams{Q: "hi", RoomId: &roomID}))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"items":[]}`, rec.Body.String())
+	})
+
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{name: "невалидный запрос", err: errors.Wrap(model.ErrInvalidArgument, "empty"), code: http.StatusBadRequest},
+		{name: "не владелец", err: errors.Wrap(model.ErrForbidden, "token"), code: http.StatusForbidden},
+		{name: "нет комнаты", err: errors.Wrap(model.ErrNotFound, "room"), code: http.StatusNotFound},
+		{name: "ошибка модели", err: errors.New("db failure"), code: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockModel.
+				EXPECT().
+				Search(gomock.Any(), gomock.Any()).
+				Return(nil, tt.err)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/search?q=x", nil)
+			rec := httptest.NewRecorder()
+
+			c := e.NewContext(req, rec)
+			handle(srv, c, srv.Search(c, gen.SearchParams{Q: "x"}))
+			assert.Equal(t, tt.code, rec.Code)
+		})
+	}
+}
@@ -2,71 +2,266 @@ package server
 
 import (
 	"context"
+	"log/slog"
+	"net"
 	"net/http"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	openapi_types "github.com/oapi-codegen/runtime/types"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
 
+	"github.com/vpbuyanov/syncplay/internal/auth"
 	"github.com/vpbuyanov/syncplay/internal/config"
 	"github.com/vpbuyanov/syncplay/internal/gen"
+	"github.com/vpbuyanov/syncplay/internal/logging"
+	"github.com/vpbuyanov/syncplay/internal/metrics"
+	"github.com/vpbuyanov/syncplay/internal/model"
 )
 
 //go:generate mockgen -source=server.go -destination server_mock.go -package server SERVER
 type modelRoom interface {
-	CreateRoom(ctx context.Context) (string, error)
+	CreateRoom(ctx context.Context, meta model.RoomMeta) (model.CreatedRoom, error)
 	DeleteRoom(ctx context.Context, id openapi_types.UUID) error
+	PurgeRoom(ctx context.Context, id openapi_types.UUID) error
+	ArchiveRoom(ctx context.Context, id openapi_types.UUID) error
+	RestoreRoom(ctx context.Context, id openapi_types.UUID) error
 	RoomExistsUUID(ctx context.Context, roomID openapi_types.UUID) (bool, error)
+	ListRooms(ctx context.Context, p model.ListRoomsParams) (model.RoomPage, error)
+	AuthorizeManager(ctx context.Context, roomID, token, user string) error
+	Search(ctx context.Context, p model.SearchParams) ([]model.SearchHit, error)
+	SaveChatMessage(ctx context.Context, roomID, sender, text string) (model.ChatMessage, error)
+	TouchRoomUUID(ctx context.Context, roomID openapi_types.UUID) error
+	Ping(ctx context.Context) error
+	Register(ctx context.Context, username, password string) (model.User, error)
+	Login(ctx context.Context, username, password string) (model.Session, error)
+	Logout(ctx context.Context, token string) error
+	SessionUser(ctx context.Context, token string) (model.User, time.Time, error)
+	TenantByKey(ctx context.Context, key string) (model.Tenant, error)
+	ListTenants(ctx context.Context) ([]model.Tenant, error)
+	CountRoomsByTenant(ctx context.Context) (map[string]int, error)
 }
 
 type Server struct {
 	e *echo.Echo
 	m modelRoom
+
+	tunables atomic.Pointer[Tunables]
+	limits   rateLimiter
+
+	// tenants считает использование и квоты арендаторов
+	tenants        tenantTracker
+	tenantRequired bool
+
+	// draining — сервер останавливается и не принимает новые комнаты и подключения
+	draining       atomic.Bool
+	wsConns        sync.WaitGroup
+	reconnectDelay time.Duration
+
+	checks    []namedCheck
+	startedAt time.Time
+
+	// admin — отдельный листенер для /metrics и /admin, если задан server.metrics_addr
+	admin      *http.Server
+	adminToken string
+	// messages — темп сообщений по всем комнатам, errs — последние ошибки
+	// из лога; их показывает /admin/dashboard
+	messages  rateMeter
+	errs      *logging.RecentErrors
+	dashboard dashboardCache
+	// redirect перенаправляет HTTP на HTTPS, если задан server.tls.redirect_addr
+	redirect *http.Server
+
+	// auth проверяет JWT; nil, если проверка выключена
+	auth         *auth.Verifier
+	authRequired bool
+	// accounts — включены встроенные учётные записи и сессии
+	accounts bool
+
+	// watchers следят за файлами (сертификат, JWKS) до вызова stopWatchers
+	watchers     []func()
+	stopWatchers context.CancelFunc
+}
+
+const (
+	metricsPath = "/metrics"
+	serviceName = "syncplay"
+)
+
+// Tunables — настройки, которые можно менять на работающем сервере.
+// AllowedOrigins действует и на CORS, и на WebSocket; пустой список
+// или "*" в нём разрешает любые источники,
+// RoomMaxPeers = 0 снимает ограничение на размер комнаты.
+type Tunables struct {
+	AllowedOrigins []string
+	RoomMaxPeers   int
+	RateLimit      config.RateLimit
+}
+
+// TunablesFrom берёт настраиваемую на лету часть конфига.
+func TunablesFrom(cfg *config.Config) Tunables {
+	return Tunables{
+		AllowedOrigins: slices.Clone(cfg.Server.AllowedOrigins),
+		RoomMaxPeers:   cfg.Server.RoomMaxPeers,
+		RateLimit:      cfg.RateLimit,
+	}
+}
+
+// SetTunables атомарно применяет новые настройки; уже открытые
+// соединения не разрываются, новые лимиты действуют и на них.
+func (s *Server) SetTunables(t Tunables) {
+	s.tunables.Store(&t)
+	s.limits.apply(t.RateLimit)
+}
+
+func (s *Server) currentTunables() Tunables {
+	if t := s.tunables.Load(); t != nil {
+		return *t
+	}
+
+	return Tunables{}
 }
 
-func NewServer(cfg config.Server, m modelRoom) (*Server, error) {
+func NewServer(conf *config.Config, m modelRoom) (*Server, error) {
+	cfg := conf.Server
+
 	server := &Server{
-		e: echo.New(),
-		m: m,
+		e:              echo.New(),
+		m:              m,
+		adminToken:     cfg.AdminToken,
+		reconnectDelay: cfg.ReconnectDelay,
+		startedAt:      time.Now(),
 	}
+	server.SetTunables(TunablesFrom(conf))
+
+	watchCtx, stopWatchers := context.WithCancel(context.Background())
+	server.stopWatchers = stopWatchers
+	server.AddCheck("storage", func(ctx context.Context) error { return server.m.Ping(ctx) })
 
 	server.e.HideBanner = true
+	server.e.HidePort = true
+	server.e.Logger = echoLogger{l: slog.Default()}
+	server.e.StdLogger = slog.NewLogLogger(slog.Default().Handler(), slog.LevelError)
+	server.e.HTTPErrorHandler = server.handleError
+
+	extractIP, err := ipExtractor(cfg.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	server.e.IPExtractor = extractIP
+
 	server.e.Pre(middleware.RemoveTrailingSlash())
 	gen.RegisterHandlers(server.e, server)
 
+	if cfg.MetricsAddr == "" {
+		server.e.GET(metricsPath, echo.WrapHandler(metrics.Handler()))
+	} else {
+		mux := http.NewServeMux()
+		mux.Handle(metricsPath, metrics.Handler())
+		mux.Handle("/admin/", server.adminHandler())
+		server.admin = &http.Server{Addr: cfg.MetricsAddr, Handler: mux, ReadHeaderTimeout: cfg.TimeOut}
+
+		// Потоки дашборда не завершаются сами: Shutdown отменяет их контекст,
+		// иначе он ждал бы их до своего таймаута
+		adminCtx, stopAdmin := context.WithCancel(context.Background())
+		server.admin.BaseContext = func(net.Listener) context.Context { return adminCtx }
+		server.admin.RegisterOnShutdown(stopAdmin)
+	}
+
+	server.e.Use(requestID())
+
+	// WS-сессии трассируются отдельно, см. ConnectRoomWS
+	server.e.Use(otelecho.Middleware(serviceName, otelecho.WithSkipper(func(c echo.Context) bool {
+		return strings.HasPrefix(c.Path(), "/api/v1/ws/") ||
+			strings.HasPrefix(c.Path(), "/api/v2/ws/") ||
+			c.Path() == metricsPath
+	})))
+
+	server.e.Use(accessLog)
+	server.e.Use(observeHTTP)
+
 	server.e.Use(middleware.RecoverWithConfig(middleware.RecoverConfig{
 		Skipper: func(c echo.Context) bool {
 			return strings.HasPrefix(c.Path(), "/api/v1/ws/") ||
 				strings.HasPrefix(c.Path(), "/api/v2/ws/")
 		},
+		LogErrorFunc: func(c echo.Context, err error, stack []byte) error {
+			slog.ErrorContext(c.Request().Context(), "panic recovered", "err", err, "stack", string(stack))
+			return err
+		},
 	}))
 
 	server.e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: []string{"*"},
+		AllowOriginFunc: server.allowOrigin,
 		Skipper: func(c echo.Context) bool {
 			return strings.HasPrefix(c.Path(), "/api/v1/ws/") ||
 				strings.HasPrefix(c.Path(), "/api/v2/ws/")
 		},
 	}))
 
+	server.e.Use(server.rateLimit)
+
+	server.tenantRequired = conf.Tenants.Required
+	server.e.Use(server.resolveTenant)
+
+	if conf.Auth.JWT() {
+		if server.auth, err = auth.NewVerifier(conf.Auth); err != nil {
+			return nil, errors.Wrap(err, "auth")
+		}
+		server.watchers = append(server.watchers, func() { server.auth.Watch(watchCtx) })
+	}
+	if conf.Auth.Enabled() {
+		server.authRequired = conf.Auth.Required
+		server.accounts = conf.Auth.Accounts
+		server.e.Use(server.authenticate)
+	}
+
 	server.e.Use(middleware.TimeoutWithConfig(middleware.TimeoutConfig{
 		Timeout: cfg.TimeOut,
 		Skipper: func(c echo.Context) bool {
 			return strings.HasPrefix(c.Path(), "/api/v1/ws/") ||
-				strings.HasPrefix(c.Path(), "/api/v2/ws/")
+				strings.HasPrefix(c.Path(), "/api/v2/ws/") ||
+				isProbe(c.Path())
 		},
 	}))
 
 	server.e.Server.Addr = cfg.String()
 
+	if err = server.setupTLS(watchCtx, cfg); err != nil {
+		return nil, errors.Wrap(err, "tls")
+	}
+
 	return server, nil
 }
 
+// Listen принимает запросы до вызова Shutdown.
 func (s *Server) Listen() error {
+	if s.admin != nil {
+		if err := serveSide(s.admin, "metrics"); err != nil {
+			return err
+		}
+	}
+
+	if s.redirect != nil {
+		if err := serveSide(s.redirect, "https redirect"); err != nil {
+			return err
+		}
+	}
+
+	for _, watch := range s.watchers {
+		go watch()
+	}
+
+	slog.Info("listening", "addr", s.e.Server.Addr, "tls", s.e.Server.TLSConfig != nil)
+
 	if err := s.e.StartServer(s.e.Server); err != nil && !errors.Is(err, http.ErrServerClosed) {
-		s.e.Logger.Fatal("start: " + err.Error())
+		return errors.Wrap(err, "start")
 	}
 
 	return nil
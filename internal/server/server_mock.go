@@ -11,8 +11,10 @@ package server
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	types "github.com/oapi-codegen/runtime/types"
+	model "github.com/vpbuyanov/syncplay/internal/model"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -39,19 +41,62 @@ func (m *MockmodelRoom) EXPECT() *MockmodelRoomMockRecorder {
 	return m.recorder
 }
 
+// ArchiveRoom mocks base method.
+func (m *MockmodelRoom) ArchiveRoom(ctx context.Context, id types.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ArchiveRoom", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ArchiveRoom indicates an expected call of ArchiveRoom.
+func (mr *MockmodelRoomMockRecorder) ArchiveRoom(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ArchiveRoom", reflect.TypeOf((*MockmodelRoom)(nil).ArchiveRoom), ctx, id)
+}
+
+// AuthorizeManager mocks base method.
+func (m *MockmodelRoom) AuthorizeManager(ctx context.Context, roomID, token, user string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthorizeManager", ctx, roomID, token, user)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AuthorizeManager indicates an expected call of AuthorizeManager.
+func (mr *MockmodelRoomMockRecorder) AuthorizeManager(ctx, roomID, token, user any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthorizeManager", reflect.TypeOf((*MockmodelRoom)(nil).AuthorizeManager), ctx, roomID, token, user)
+}
+
+// CountRoomsByTenant mocks base method.
+func (m *MockmodelRoom) CountRoomsByTenant(ctx context.Context) (map[string]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountRoomsByTenant", ctx)
+	ret0, _ := ret[0].(map[string]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountRoomsByTenant indicates an expected call of CountRoomsByTenant.
+func (mr *MockmodelRoomMockRecorder) CountRoomsByTenant(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountRoomsByTenant", reflect.TypeOf((*MockmodelRoom)(nil).CountRoomsByTenant), ctx)
+}
+
 // CreateRoom mocks base method.
-func (m *MockmodelRoom) CreateRoom(ctx context.Context) (string, error) {
+func (m *MockmodelRoom) CreateRoom(ctx context.Context, meta model.RoomMeta) (model.CreatedRoom, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateRoom", ctx)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "CreateRoom", ctx, meta)
+	ret0, _ := ret[0].(model.CreatedRoom)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateRoom indicates an expected call of CreateRoom.
-func (mr *MockmodelRoomMockRecorder) CreateRoom(ctx any) *gomock.Call {
+func (mr *MockmodelRoomMockRecorder) CreateRoom(ctx, meta any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRoom", reflect.TypeOf((*MockmodelRoom)(nil).CreateRoom), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRoom", reflect.TypeOf((*MockmodelRoom)(nil).CreateRoom), ctx, meta)
 }
 
 // DeleteRoom mocks base method.
@@ -68,6 +113,122 @@ func (mr *MockmodelRoomMockRecorder) DeleteRoom(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRoom", reflect.TypeOf((*MockmodelRoom)(nil).DeleteRoom), ctx, id)
 }
 
+// ListRooms mocks base method.
+func (m *MockmodelRoom) ListRooms(ctx context.Context, p model.ListRoomsParams) (model.RoomPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRooms", ctx, p)
+	ret0, _ := ret[0].(model.RoomPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRooms indicates an expected call of ListRooms.
+func (mr *MockmodelRoomMockRecorder) ListRooms(ctx, p any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRooms", reflect.TypeOf((*MockmodelRoom)(nil).ListRooms), ctx, p)
+}
+
+// ListTenants mocks base method.
+func (m *MockmodelRoom) ListTenants(ctx context.Context) ([]model.Tenant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTenants", ctx)
+	ret0, _ := ret[0].([]model.Tenant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTenants indicates an expected call of ListTenants.
+func (mr *MockmodelRoomMockRecorder) ListTenants(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTenants", reflect.TypeOf((*MockmodelRoom)(nil).ListTenants), ctx)
+}
+
+// Login mocks base method.
+func (m *MockmodelRoom) Login(ctx context.Context, username, password string) (model.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Login", ctx, username, password)
+	ret0, _ := ret[0].(model.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Login indicates an expected call of Login.
+func (mr *MockmodelRoomMockRecorder) Login(ctx, username, password any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockmodelRoom)(nil).Login), ctx, username, password)
+}
+
+// Logout mocks base method.
+func (m *MockmodelRoom) Logout(ctx context.Context, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Logout", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockmodelRoomMockRecorder) Logout(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockmodelRoom)(nil).Logout), ctx, token)
+}
+
+// Ping mocks base method.
+func (m *MockmodelRoom) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockmodelRoomMockRecorder) Ping(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockmodelRoom)(nil).Ping), ctx)
+}
+
+// PurgeRoom mocks base method.
+func (m *MockmodelRoom) PurgeRoom(ctx context.Context, id types.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeRoom", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeRoom indicates an expected call of PurgeRoom.
+func (mr *MockmodelRoomMockRecorder) PurgeRoom(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeRoom", reflect.TypeOf((*MockmodelRoom)(nil).PurgeRoom), ctx, id)
+}
+
+// Register mocks base method.
+func (m *MockmodelRoom) Register(ctx context.Context, username, password string) (model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Register", ctx, username, password)
+	ret0, _ := ret[0].(model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Register indicates an expected call of Register.
+func (mr *MockmodelRoomMockRecorder) Register(ctx, username, password any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockmodelRoom)(nil).Register), ctx, username, password)
+}
+
+// RestoreRoom mocks base method.
+func (m *MockmodelRoom) RestoreRoom(ctx context.Context, id types.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreRoom", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreRoom indicates an expected call of RestoreRoom.
+func (mr *MockmodelRoomMockRecorder) RestoreRoom(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreRoom", reflect.TypeOf((*MockmodelRoom)(nil).RestoreRoom), ctx, id)
+}
+
 // RoomExistsUUID mocks base method.
 func (m *MockmodelRoom) RoomExistsUUID(ctx context.Context, roomID types.UUID) (bool, error) {
 	m.ctrl.T.Helper()
@@ -82,3 +243,78 @@ func (mr *MockmodelRoomMockRecorder) RoomExistsUUID(ctx, roomID any) *gomock.Cal
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RoomExistsUUID", reflect.TypeOf((*MockmodelRoom)(nil).RoomExistsUUID), ctx, roomID)
 }
+
+// SaveChatMessage mocks base method.
+func (m *MockmodelRoom) SaveChatMessage(ctx context.Context, roomID, sender, text string) (model.ChatMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveChatMessage", ctx, roomID, sender, text)
+	ret0, _ := ret[0].(model.ChatMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveChatMessage indicates an expected call of SaveChatMessage.
+func (mr *MockmodelRoomMockRecorder) SaveChatMessage(ctx, roomID, sender, text any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveChatMessage", reflect.TypeOf((*MockmodelRoom)(nil).SaveChatMessage), ctx, roomID, sender, text)
+}
+
+// Search mocks base method.
+func (m *MockmodelRoom) Search(ctx context.Context, p model.SearchParams) ([]model.SearchHit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, p)
+	ret0, _ := ret[0].([]model.SearchHit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockmodelRoomMockRecorder) Search(ctx, p any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockmodelRoom)(nil).Search), ctx, p)
+}
+
+// SessionUser mocks base method.
+func (m *MockmodelRoom) SessionUser(ctx context.Context, token string) (model.User, time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SessionUser", ctx, token)
+	ret0, _ := ret[0].(model.User)
+	ret1, _ := ret[1].(time.Time)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SessionUser indicates an expected call of SessionUser.
+func (mr *MockmodelRoomMockRecorder) SessionUser(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SessionUser", reflect.TypeOf((*MockmodelRoom)(nil).SessionUser), ctx, token)
+}
+
+// TenantByKey mocks base method.
+func (m *MockmodelRoom) TenantByKey(ctx context.Context, key string) (model.Tenant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TenantByKey", ctx, key)
+	ret0, _ := ret[0].(model.Tenant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TenantByKey indicates an expected call of TenantByKey.
+func (mr *MockmodelRoomMockRecorder) TenantByKey(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TenantByKey", reflect.TypeOf((*MockmodelRoom)(nil).TenantByKey), ctx, key)
+}
+
+// TouchRoomUUID mocks base method.
+func (m *MockmodelRoom) TouchRoomUUID(ctx context.Context, roomID types.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchRoomUUID", ctx, roomID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchRoomUUID indicates an expected call of TouchRoomUUID.
+func (mr *MockmodelRoomMockRecorder) TouchRoomUUID(ctx, roomID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchRoomUUID", reflect.TypeOf((*MockmodelRoom)(nil).TouchRoomUUID), ctx, roomID)
+}
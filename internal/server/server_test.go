@@ -0,0 +1,32 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_AllowOrigin(t *testing.T) {
+	s := &Server{}
+
+	ok, _ := s.allowOrigin("https://example.com")
+	assert.True(t, ok, "без настроек разрешены любые источники")
+
+	s.SetTunables(Tunables{AllowedOrigins: []string{"https://syncplay.example", "https://*.learnio.space"}})
+
+	ok, _ = s.allowOrigin("https://syncplay.example")
+	assert.True(t, ok)
+	ok, _ = s.allowOrigin("https://app.learnio.space")
+	assert.True(t, ok)
+
+	before := testutil.ToFloat64(originRejected.WithLabelValues("cors"))
+	ok, _ = s.allowOrigin("https://example.com")
+	assert.False(t, ok)
+	assert.Equal(t, before+1, testutil.ToFloat64(originRejected.WithLabelValues("cors")), "отказ учитывается в метриках")
+
+	s.SetTunables(Tunables{AllowedOrigins: []string{"*"}})
+
+	ok, _ = s.allowOrigin("https://example.com")
+	assert.True(t, ok)
+}
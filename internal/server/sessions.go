@@ -0,0 +1,114 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Коды закрытия WS: комнату закрыл сервер; в комнате нет мест.
+const (
+	closeRoomClosed = 4000
+	closeRoomFull   = 4001
+)
+
+type roomExpiringPayload struct {
+	ClosesAt time.Time `json:"closes_at"`
+}
+
+type roomClosedPayload struct {
+	Reason string `json:"reason"`
+}
+
+// serverShutdownPayload — через сколько секунд клиенту стоит переподключиться.
+type serverShutdownPayload struct {
+	Reconnect  bool `json:"reconnect"`
+	RetryAfter int  `json:"retry_after"`
+}
+
+// ActiveRoomIDs возвращает комнаты, в которых сейчас есть подключённые участники.
+func (s *Server) ActiveRoomIDs() []string {
+	counts := roomPeerCounts()
+
+	res := make([]string, 0, len(counts))
+	for id := range counts {
+		res = append(res, id.String())
+	}
+
+	return res
+}
+
+// WarnRoom предупреждает участников комнаты о предстоящем закрытии.
+func (s *Server) WarnRoom(roomID string, closesAt time.Time) {
+	id, err := uuid.Parse(roomID)
+	if err != nil {
+		return
+	}
+
+	payload, err := json.Marshal(roomExpiringPayload{ClosesAt: closesAt})
+	if err != nil {
+		return
+	}
+
+	roomsMu.Lock()
+	sess := rooms[id]
+	roomsMu.Unlock()
+
+	if sess == nil {
+		return
+	}
+
+	for _, p := range sess.snapshot() {
+		if err = p.writeJSON(message{Type: "room-expiring", Payload: payload}); err != nil {
+			slog.Error("failed to send 'room-expiring'", "room_id", roomID, "err", err)
+		}
+	}
+}
+
+// CloseRoom отправляет участникам "room-closed" с причиной и отключает их.
+func (s *Server) CloseRoom(roomID string, reason string) {
+	id, err := uuid.Parse(roomID)
+	if err != nil {
+		return
+	}
+
+	payload, err := json.Marshal(roomClosedPayload{Reason: reason})
+	if err != nil {
+		return
+	}
+
+	roomsMu.Lock()
+	sess := rooms[id]
+	delete(rooms, id)
+	roomsMu.Unlock()
+
+	if sess == nil {
+		return
+	}
+
+	sess.Session.Lock()
+	sess.closed = true
+	sess.Session.Unlock()
+
+	for _, p := range sess.snapshot() {
+		if err = p.writeJSON(message{Type: "room-closed", Payload: payload}); err != nil {
+			slog.Error("failed to send 'room-closed'", "room_id", roomID, "err", err)
+		}
+		p.close(closeRoomClosed, "room closed: "+reason)
+	}
+}
+
+// snapshot возвращает текущих участников сессии.
+func (sess *roomSession) snapshot() []*peer {
+	sess.Session.Lock()
+	defer sess.Session.Unlock()
+
+	res := make([]*peer, 0, len(sess.Peers))
+	for _, p := range sess.Peers {
+		res = append(res, p)
+	}
+
+	return res
+}
@@ -0,0 +1,219 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"go.uber.org/mock/gomock"
+
+	"github.com/vpbuyanov/syncplay/internal/gen"
+)
+
+func TestServer_WarnAndCloseRoom(t *testing.T) {
+	clearRooms()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockModel := NewMockmodelRoom(ctrl)
+	mockModel.EXPECT().TouchRoomUUID(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockModel.EXPECT().RoomExistsUUID(gomock.Any(), gomock.Any()).Return(true, nil)
+	srv := &Server{m: mockModel}
+
+	e := echo.New()
+	e.GET("/ws/:roomID", func(c echo.Context) error {
+		return srv.ConnectRoomWS(c, uuid.MustParse(c.Param("roomID")))
+	})
+
+	ts := httptest.NewServer(e)
+	defer ts.Close()
+
+	roomID := uuid.New()
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws/" + roomID.String()
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer conn.Close()
+
+	var msg message
+	if err = readJSONWithTimeout(t, conn, &msg); err != nil || msg.Type != "welcome" {
+		t.Fatalf("welcome: %+v, %v", msg, err)
+	}
+	if err = readJSONWithTimeout(t, conn, &msg); err != nil || msg.Type != "existing-peers" {
+		t.Fatalf("existing-peers: %+v, %v", msg, err)
+	}
+
+	// Комната считается активной
+	if ids := srv.ActiveRoomIDs(); len(ids) != 1 || ids[0] != roomID.String() {
+		t.Fatalf("ActiveRoomIDs = %v", ids)
+	}
+
+	// Предупреждение о закрытии
+	closesAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	srv.WarnRoom(roomID.String(), closesAt)
+
+	if err = readJSONWithTimeout(t, conn, &msg); err != nil {
+		t.Fatalf("room-expiring read: %v", err)
+	}
+	if msg.Type != "room-expiring" {
+		t.Fatalf("ожидали room-expiring, получили %+v", msg)
+	}
+	var warn roomExpiringPayload
+	if err = json.Unmarshal(msg.Payload, &warn); err != nil || !warn.ClosesAt.Equal(closesAt) {
+		t.Fatalf("room-expiring payload: %s, %v", msg.Payload, err)
+	}
+
+	// Закрытие комнаты
+	srv.CloseRoom(roomID.String(), "expired")
+
+	if err = readJSONWithTimeout(t, conn, &msg); err != nil {
+		t.Fatalf("room-closed read: %v", err)
+	}
+	if msg.Type != "room-closed" {
+		t.Fatalf("ожидали room-closed, получили %+v", msg)
+	}
+	var closed roomClosedPayload
+	if err = json.Unmarshal(msg.Payload, &closed); err != nil || closed.Reason != "expired" {
+		t.Fatalf("room-closed payload: %s, %v", msg.Payload, err)
+	}
+
+	// Следом приходит close-фрейм с кодом закрытия комнаты
+	err = readJSONWithTimeout(t, conn, &msg)
+	if !websocket.IsCloseError(err, closeRoomClosed) {
+		t.Fatalf("ожидали close %d, получили %v", closeRoomClosed, err)
+	}
+
+	roomsMu.Lock()
+	_, ok := rooms[openapi_types.UUID(roomID)]
+	roomsMu.Unlock()
+	if ok {
+		t.Fatalf("комната должна быть удалена из активных")
+	}
+}
+
+func TestServer_CloseRoom_UnknownRoom(t *testing.T) {
+	clearRooms()
+
+	srv := &Server{}
+
+	// Не должно паниковать
+	srv.WarnRoom("not-a-uuid", time.Now())
+	srv.CloseRoom(uuid.NewString(), "deleted")
+
+	if ids := srv.ActiveRoomIDs(); len(ids) != 0 {
+		t.Fatalf("ActiveRoomIDs = %v", ids)
+	}
+}
+
+func TestServer_JoinClosedRoom(t *testing.T) {
+	clearRooms()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockModel := NewMockmodelRoom(ctrl)
+	mockModel.EXPECT().RoomExistsUUID(gomock.Any(), gomock.Any()).Return(true, nil)
+	srv := &Server{m: mockModel}
+
+	e := echo.New()
+	e.GET("/ws/:roomID", func(c echo.Context) error {
+		return srv.ConnectRoomWS(c, uuid.MustParse(c.Param("roomID")))
+	})
+
+	ts := httptest.NewServer(e)
+	defer ts.Close()
+
+	// Участник взял сессию из rooms, а CloseRoom успел её закрыть
+	// до того, как участник в неё добавился
+	roomID := uuid.New()
+	sess := &roomSession{Peers: make(map[string]*peer), startedAt: time.Now(), closed: true}
+	roomsMu.Lock()
+	rooms[roomID] = sess
+	roomsMu.Unlock()
+
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws/" + roomID.String()
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer conn.Close()
+
+	err = readJSONWithTimeout(t, conn, &message{})
+	if !websocket.IsCloseError(err, closeRoomClosed) {
+		t.Fatalf("ожидали close %d, получили %v", closeRoomClosed, err)
+	}
+
+	if peers := len(sess.snapshot()); peers != 0 {
+		t.Fatalf("участник не должен попасть в закрытую сессию, участников: %d", peers)
+	}
+	if ids := srv.ActiveRoomIDs(); len(ids) != 0 {
+		t.Fatalf("ActiveRoomIDs = %v", ids)
+	}
+}
+
+func TestServer_DeleteRoom_DisconnectsPeers(t *testing.T) {
+	clearRooms()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockModel := NewMockmodelRoom(ctrl)
+	mockModel.EXPECT().TouchRoomUUID(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockModel.EXPECT().RoomExistsUUID(gomock.Any(), gomock.Any()).Return(true, nil)
+	srv := &Server{m: mockModel}
+
+	e := echo.New()
+	e.GET("/ws/:roomID", func(c echo.Context) error {
+		return srv.ConnectRoomWS(c, uuid.MustParse(c.Param("roomID")))
+	})
+
+	ts := httptest.NewServer(e)
+	defer ts.Close()
+
+	roomID := uuid.New()
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws/" + roomID.String()
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer conn.Close()
+
+	var msg message
+	for _, want := range []string{"welcome", "existing-peers"} {
+		if err = readJSONWithTimeout(t, conn, &msg); err != nil || msg.Type != want {
+			t.Fatalf("%s: %+v, %v", want, msg, err)
+		}
+	}
+
+	token := "owner-secret"
+	mockModel.EXPECT().AuthorizeManager(gomock.Any(), roomID.String(), token, "").Return(nil)
+	mockModel.EXPECT().DeleteRoom(gomock.Any(), roomID).Return(nil)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/rooms/"+roomID.String(), nil)
+	if err = srv.DeleteRoom(e.NewContext(req, rec), roomID, gen.DeleteRoomParams{XRoomToken: &token}); err != nil {
+		t.Fatalf("DeleteRoom: %v", err)
+	}
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("ожидали 204, получили %d", rec.Code)
+	}
+
+	if err = readJSONWithTimeout(t, conn, &msg); err != nil || msg.Type != "room-closed" {
+		t.Fatalf("room-closed: %+v, %v", msg, err)
+	}
+	var closed roomClosedPayload
+	if err = json.Unmarshal(msg.Payload, &closed); err != nil || closed.Reason != "deleted" {
+		t.Fatalf("room-closed payload: %s, %v", msg.Payload, err)
+	}
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"math"
+
+	"github.com/gorilla/websocket"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"github.com/pkg/errors"
+)
+
+// Shutdown останавливает сервер: перестаёт принимать новые комнаты и
+// подключения, рассылает участникам "server-shutdown" с советом, когда
+// переподключиться, дожидается текущих HTTP-запросов и закрывает WS
+// с кодом 1012 (service restart). Возвращается, когда все обработчики WS
+// завершились или истёк ctx; хранилище после этого можно закрывать.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.draining.Store(true)
+
+	payload, err := json.Marshal(serverShutdownPayload{
+		Reconnect:  true,
+		RetryAfter: int(math.Ceil(s.reconnectDelay.Seconds())),
+	})
+	if err != nil {
+		return errors.Wrap(err, "marshal server-shutdown")
+	}
+
+	for _, p := range allPeers() {
+		if err = p.writeJSON(message{Type: "server-shutdown", Payload: payload}); err != nil {
+			slog.Error("failed to send 'server-shutdown'", "err", err)
+		}
+	}
+
+	httpErr := errors.Wrap(s.e.Shutdown(ctx), "shutdown http server")
+
+	if s.redirect != nil {
+		if err = s.redirect.Shutdown(ctx); err != nil && httpErr == nil {
+			httpErr = errors.Wrap(err, "shutdown https redirect")
+		}
+	}
+	if s.stopWatchers != nil {
+		s.stopWatchers()
+	}
+
+	// После остановки HTTP новых участников уже не будет
+	for _, p := range takeAllPeers() {
+		p.close(websocket.CloseServiceRestart, "server shutdown")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.wsConns.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "wait for websocket handlers")
+	}
+
+	// Метрики отдаём до последнего, чтобы видеть ход остановки
+	if s.admin != nil {
+		if err = s.admin.Shutdown(ctx); err != nil && httpErr == nil {
+			httpErr = errors.Wrap(err, "shutdown metrics listener")
+		}
+	}
+
+	return httpErr
+}
+
+// allPeers возвращает участников всех комнат.
+func allPeers() []*peer {
+	roomsMu.Lock()
+	sessions := make([]*roomSession, 0, len(rooms))
+	for _, sess := range rooms {
+		sessions = append(sessions, sess)
+	}
+	roomsMu.Unlock()
+
+	var res []*peer
+	for _, sess := range sessions {
+		res = append(res, sess.snapshot()...)
+	}
+
+	return res
+}
+
+// takeAllPeers закрывает все комнаты и возвращает их участников;
+// уходящие участники не рассылают peer-left.
+func takeAllPeers() []*peer {
+	roomsMu.Lock()
+	sessions := rooms
+	rooms = make(map[openapi_types.UUID]*roomSession)
+	roomsMu.Unlock()
+
+	var res []*peer
+	for _, sess := range sessions {
+		sess.Session.Lock()
+		sess.closed = true
+		sess.Session.Unlock()
+
+		res = append(res, sess.snapshot()...)
+	}
+
+	return res
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestServer_Shutdown(t *testing.T) {
+	clearRooms()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockModel := NewMockmodelRoom(ctrl)
+	mockModel.EXPECT().TouchRoomUUID(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockModel.EXPECT().RoomExistsUUID(gomock.Any(), gomock.Any()).Return(true, nil)
+	srv := &Server{e: echo.New(), m: mockModel, reconnectDelay: 5 * time.Second}
+
+	e := echo.New()
+	e.HTTPErrorHandler = srv.handleError
+	e.GET("/ws/:roomID", func(c echo.Context) error {
+		return srv.ConnectRoomWS(c, uuid.MustParse(c.Param("roomID")))
+	})
+
+	ts := httptest.NewServer(e)
+	defer ts.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws/" + uuid.New().String()
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	var msg message
+	require.NoError(t, readJSONWithTimeout(t, conn, &msg))
+	require.Equal(t, "welcome", msg.Type)
+	require.NoError(t, readJSONWithTimeout(t, conn, &msg))
+	require.Equal(t, "existing-peers", msg.Type)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	// Shutdown дожидается завершения обработчика WS
+	require.NoError(t, srv.Shutdown(ctx))
+
+	require.NoError(t, readJSONWithTimeout(t, conn, &msg))
+	assert.Equal(t, "server-shutdown", msg.Type)
+	assert.JSONEq(t, `{"reconnect":true,"retry_after":5}`, string(msg.Payload))
+
+	err = readJSONWithTimeout(t, conn, &msg)
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseServiceRestart), "unexpected error: %v", err)
+	assert.Empty(t, srv.ActiveRoomIDs())
+
+	// Новые подключения и комнаты не принимаются
+	_, resp, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	require.Error(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/rooms", strings.NewReader(`{}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	handle(srv, c, srv.CreateRoom(c))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
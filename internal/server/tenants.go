@@ -0,0 +1,237 @@
+package server
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+const (
+	apiKeyHeader = "X-API-Key"
+	// apiKeyParam — API-ключ в WS-апгрейде: браузер не может задать заголовок.
+	apiKeyParam = "api_key"
+
+	adminTenantsPath = "/admin/tenants"
+)
+
+// tenantUsage — счётчики арендатора с момента запуска сервера.
+type tenantUsage struct {
+	peers     int
+	messages  int64
+	throttled int64
+	// limiter — квота сообщений в минуту; nil, пока квоты нет
+	limiter *rate.Limiter
+}
+
+// tenantTracker считает участников и сообщения по арендаторам и
+// проверяет их квоты. Квоты берутся из арендатора запроса, поэтому
+// изменённые квоты действуют с новых подключений.
+type tenantTracker struct {
+	mu    sync.Mutex
+	usage map[string]*tenantUsage
+}
+
+func (tt *tenantTracker) get(id string) *tenantUsage {
+	u, ok := tt.usage[id]
+	if !ok {
+		if tt.usage == nil {
+			tt.usage = make(map[string]*tenantUsage)
+		}
+		u = &tenantUsage{}
+		tt.usage[id] = u
+	}
+
+	return u
+}
+
+// acquirePeer занимает слот участника арендатора; освобождается releasePeer.
+func (tt *tenantTracker) acquirePeer(t model.Tenant) bool {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	u := tt.get(t.ID)
+	if t.Quotas.MaxPeers > 0 && u.peers >= t.Quotas.MaxPeers {
+		return false
+	}
+	u.peers++
+
+	return true
+}
+
+func (tt *tenantTracker) releasePeer(id string) {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	tt.get(id).peers--
+}
+
+// allowMessage учитывает сообщение участника и проверяет квоту
+// сообщений в минуту; сообщения сверх квоты считаются отброшенными.
+func (tt *tenantTracker) allowMessage(t model.Tenant) bool {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	u := tt.get(t.ID)
+	u.messages++
+
+	perMin := t.Quotas.MessagesPerMin
+	if perMin == 0 {
+		u.limiter = nil
+		return true
+	}
+
+	limit := rate.Limit(float64(perMin) / time.Minute.Seconds())
+	switch {
+	case u.limiter == nil:
+		u.limiter = rate.NewLimiter(limit, perMin)
+	case u.limiter.Burst() != perMin:
+		u.limiter.SetLimit(limit)
+		u.limiter.SetBurst(perMin)
+	}
+
+	if !u.limiter.Allow() {
+		u.throttled++
+		return false
+	}
+
+	return true
+}
+
+// usageReport — использование арендатора в ответе /admin/tenants.
+type usageReport struct {
+	Rooms     int   `json:"rooms"`
+	Peers     int   `json:"peers"`
+	Messages  int64 `json:"messages"`
+	Throttled int64 `json:"throttled"`
+}
+
+// quotasReport — квоты арендатора в ответе /admin/tenants; 0 — без ограничения.
+type quotasReport struct {
+	MaxRooms       int `json:"max_rooms"`
+	MaxPeers       int `json:"max_peers"`
+	MessagesPerMin int `json:"messages_per_min"`
+}
+
+// tenantReport — строка ответа /admin/tenants. Арендатор по умолчанию
+// идёт первым с пустым id.
+type tenantReport struct {
+	ID        string       `json:"id"`
+	Name      string       `json:"name"`
+	CreatedAt *time.Time   `json:"created_at,omitempty"`
+	Quotas    quotasReport `json:"quotas"`
+	Usage     usageReport  `json:"usage"`
+}
+
+const defaultTenantName = "default"
+
+// tenantReports собирает квоты и использование всех арендаторов.
+func (s *Server) tenantReports(r *http.Request) ([]tenantReport, error) {
+	tenants, err := s.m.ListTenants(r.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	rooms, err := s.m.CountRoomsByTenant(r.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	s.tenants.mu.Lock()
+	defer s.tenants.mu.Unlock()
+
+	usage := func(id string) usageReport {
+		res := usageReport{Rooms: rooms[id]}
+		if u, ok := s.tenants.usage[id]; ok {
+			res.Peers = u.peers
+			res.Messages = u.messages
+			res.Throttled = u.throttled
+		}
+		return res
+	}
+
+	res := make([]tenantReport, 0, len(tenants)+1)
+	res = append(res, tenantReport{Name: defaultTenantName, Usage: usage("")})
+	for _, t := range tenants {
+		createdAt := t.CreatedAt
+		res = append(res, tenantReport{
+			ID:        t.ID,
+			Name:      t.Name,
+			CreatedAt: &createdAt,
+			Quotas:    quotasReport(t.Quotas),
+			Usage:     usage(t.ID),
+		})
+	}
+
+	return res, nil
+}
+
+// handleAdminTenants отдаёт квоты и использование арендаторов.
+func (s *Server) handleAdminTenants(w http.ResponseWriter, r *http.Request) {
+	reports, err := s.tenantReports(r)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to collect tenant usage", "err", err)
+		writeProblem(w, http.StatusInternalServerError, model.CodeInternal, "something wrong")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"tenants": reports})
+}
+
+// resolveTenant определяет арендатора по API-ключу и ограничивает им
+// запросы к хранилищу. Запрос без ключа относится к арендатору по
+// умолчанию, если ключ не обязателен. Пробы, /metrics, /api/v1/info
+// и /api/v1/auth/* арендатором не ограничиваются.
+func (s *Server) resolveTenant(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if isProbe(c.Path()) || c.Path() == metricsPath || c.Path() == "/api/v1/info" ||
+			strings.HasPrefix(c.Path(), "/api/v1/auth/") {
+			return next(c)
+		}
+
+		ctx := c.Request().Context()
+
+		var tenant model.Tenant
+		if key := apiKey(c); key != "" {
+			t, err := s.m.TenantByKey(ctx, key)
+			if err != nil {
+				return err
+			}
+			tenant = t
+			withLogAttrs(c, "tenant", t.Name)
+		} else if s.tenantRequired {
+			return errors.Wrap(model.ErrUnauthorized, "missing api key")
+		}
+
+		c.SetRequest(c.Request().WithContext(model.WithTenant(c.Request().Context(), tenant)))
+
+		return next(c)
+	}
+}
+
+// apiKey достаёт API-ключ из X-API-Key, а для WS-апгрейда — ещё из параметра api_key.
+func apiKey(c echo.Context) string {
+	if key := c.Request().Header.Get(apiKeyHeader); key != "" {
+		return key
+	}
+
+	if strings.HasPrefix(c.Path(), "/api/v1/ws/") || strings.HasPrefix(c.Path(), "/api/v2/ws/") {
+		return c.QueryParam(apiKeyParam)
+	}
+
+	return ""
+}
+
+// tenantFrom возвращает арендатора запроса; без него — арендатора по умолчанию.
+func tenantFrom(c echo.Context) model.Tenant {
+	t, _ := model.TenantFrom(c.Request().Context())
+
+	return t
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/vpbuyanov/syncplay/internal/certs"
+	"github.com/vpbuyanov/syncplay/internal/config"
+)
+
+// certCheckInterval — как часто проверяются файлы сертификата.
+const certCheckInterval = 10 * time.Second
+
+var tlsVersions = map[string]uint16{
+	"":    tls.VersionTLS12,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// setupTLS включает TLS на основном листенере, mTLS на админском
+// и создаёт листенер перенаправления на HTTPS. Сертификат перечитывается
+// с диска до отмены ctx.
+func (s *Server) setupTLS(ctx context.Context, cfg config.Server) error {
+	if !cfg.TLS.Enabled() {
+		return nil
+	}
+
+	reloader, err := certs.NewReloader(cfg.TLS.CertFile, cfg.TLS.KeyFile)
+	if err != nil {
+		return err
+	}
+	s.watchers = append(s.watchers, func() { reloader.Watch(ctx, certCheckInterval) })
+
+	base := &tls.Config{
+		MinVersion:     tlsVersions[cfg.TLS.MinVersion],
+		GetCertificate: reloader.GetCertificate,
+	}
+	s.e.Server.TLSConfig = base.Clone()
+
+	if s.admin != nil {
+		adminTLS := base.Clone()
+		if cfg.TLS.ClientCA != "" {
+			pool, err := loadCertPool(cfg.TLS.ClientCA)
+			if err != nil {
+				return err
+			}
+			adminTLS.ClientCAs = pool
+			adminTLS.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+		s.admin.TLSConfig = adminTLS
+	}
+
+	if cfg.TLS.RedirectAddr != "" {
+		s.redirect = &http.Server{
+			Addr:              cfg.TLS.RedirectAddr,
+			Handler:           redirectHandler(cfg.Port),
+			ReadHeaderTimeout: cfg.TimeOut,
+		}
+	}
+
+	return nil
+}
+
+func loadCertPool(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "read client ca")
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.Errorf("no certificates in client ca %q", path)
+	}
+
+	return pool, nil
+}
+
+// redirectHandler отправляет клиента на тот же путь по HTTPS на порт httpsPort.
+func redirectHandler(httpsPort int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if httpsPort != 443 {
+			host = net.JoinHostPort(host, strconv.Itoa(httpsPort))
+		}
+
+		target := "https://" + host + r.URL.RequestURI()
+		http.Redirect(w, r, target, http.StatusPermanentRedirect)
+	})
+}
+
+// serveSide запускает вспомогательный листенер (метрики, редирект) в фоне.
+func serveSide(srv *http.Server, name string) error {
+	l, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		return errors.Wrapf(err, "listen %s", name)
+	}
+	if srv.TLSConfig != nil {
+		l = tls.NewListener(l, srv.TLSConfig)
+	}
+
+	go func() {
+		if err := srv.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error(name+" listener failed", "err", err)
+		}
+	}()
+
+	return nil
+}
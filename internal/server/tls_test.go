@@ -0,0 +1,161 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vpbuyanov/syncplay/internal/config"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+// issue выпускает сертификат; parent == nil — самоподписанный CA.
+func issue(t *testing.T, tmpl *x509.Certificate, parent *testCert) testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl.SerialNumber = big.NewInt(time.Now().UnixNano())
+	tmpl.NotBefore = time.Now().Add(-time.Hour)
+	tmpl.NotAfter = time.Now().Add(time.Hour)
+
+	signer, signerKey := tmpl, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return testCert{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+func (c testCert) keyPEM(t *testing.T) []byte {
+	t.Helper()
+
+	der, err := x509.MarshalECPrivateKey(c.key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+}
+
+func freePort(t *testing.T) int {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func TestServer_TLS(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+		return path
+	}
+
+	ca := issue(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "test ca"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil)
+	srvCert := issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1)},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, &ca)
+	clientCert := issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "admin"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, &ca)
+
+	port, metricsPort := freePort(t), freePort(t)
+	srv, err := NewServer(&config.Config{Server: config.Server{
+		Host:        "127.0.0.1",
+		Port:        port,
+		MetricsAddr: fmt.Sprintf("127.0.0.1:%d", metricsPort),
+		TLS: config.TLS{
+			CertFile: write("tls.crt", srvCert.pem),
+			KeyFile:  write("tls.key", srvCert.keyPEM(t)),
+			ClientCA: write("ca.crt", ca.pem),
+		},
+	}}, nil)
+	require.NoError(t, err)
+
+	go func() { _ = srv.Listen() }()
+	defer func() { _ = srv.Shutdown(context.Background()) }()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	client := func(certs ...tls.Certificate) *http.Client {
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs:      roots,
+			Certificates: certs,
+		}}}
+	}
+
+	// Основной порт отвечает по HTTPS
+	url := fmt.Sprintf("https://127.0.0.1:%d/healthz", port)
+	require.Eventually(t, func() bool {
+		resp, err := client().Get(url)
+		if err != nil {
+			return false
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, 3*time.Second, 20*time.Millisecond)
+
+	// Админский листенер требует клиентский сертификат
+	metricsURL := fmt.Sprintf("https://127.0.0.1:%d/metrics", metricsPort)
+	_, err = client().Get(metricsURL)
+	require.Error(t, err)
+
+	pair, err := tls.X509KeyPair(clientCert.pem, clientCert.keyPEM(t))
+	require.NoError(t, err)
+
+	resp, err := client(pair).Get(metricsURL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestRedirectHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	redirectHandler(8443).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://syncplay.example:8080/api/v1/rooms?limit=5", nil))
+	assert.Equal(t, http.StatusPermanentRedirect, rec.Code)
+	assert.Equal(t, "https://syncplay.example:8443/api/v1/rooms?limit=5", rec.Header().Get("Location"))
+
+	rec = httptest.NewRecorder()
+	redirectHandler(443).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://syncplay.example/healthz", nil))
+	assert.Equal(t, "https://syncplay.example/healthz", rec.Header().Get("Location"))
+}
@@ -0,0 +1,31 @@
+package server
+
+import (
+	"github.com/labstack/echo/v4"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/vpbuyanov/syncplay/internal/server")
+
+// startSessionSpan открывает спан на всю WS-сессию. Родитель берётся из
+// заголовков апгрейда (traceparent), а контекст запроса подменяется, чтобы
+// вызовы модели внутри сессии стали дочерними спанами.
+func startSessionSpan(c echo.Context, roomID openapi_types.UUID) trace.Span {
+	req := c.Request()
+	ctx := otel.GetTextMapPropagator().Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+
+	ctx, span := tracer.Start(ctx, "ws.session",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("room.id", roomID.String()),
+			attribute.String("http.route", c.Path()),
+		),
+	)
+	c.SetRequest(req.WithContext(ctx))
+
+	return span
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/mock/gomock"
+
+	"github.com/vpbuyanov/syncplay/internal/config"
+)
+
+const (
+	testTraceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testParentSpan  = "00f067aa0ba902b7"
+	testTraceparent = "00-" + testTraceID + "-" + testParentSpan + "-01"
+)
+
+var spans = tracetest.NewSpanRecorder()
+
+func init() {
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+}
+
+// endedSpan ищет завершённый span с именем name из трейса testTraceID.
+func endedSpan(name string) sdktrace.ReadOnlySpan {
+	for _, s := range spans.Ended() {
+		if s.Name() == name && s.SpanContext().TraceID().String() == testTraceID {
+			return s
+		}
+	}
+
+	return nil
+}
+
+func TestServer_TracingPropagation(t *testing.T) {
+	srv, err := NewServer(&config.Config{}, nil)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/info", nil)
+	req.Header.Set("traceparent", testTraceparent)
+	rec := httptest.NewRecorder()
+	srv.e.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	// Span маршрута продолжает трейс клиента
+	span := endedSpan("GET /api/v1/info")
+	require.NotNil(t, span)
+	assert.Equal(t, testParentSpan, span.Parent().SpanID().String())
+	assert.True(t, span.Parent().IsRemote())
+}
+
+func TestConnectRoomWS_SessionSpan(t *testing.T) {
+	clearRooms()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockModel := NewMockmodelRoom(ctrl)
+	mockModel.EXPECT().RoomExistsUUID(gomock.Any(), gomock.Any()).Return(true, nil)
+	mockModel.EXPECT().TouchRoomUUID(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	srv := &Server{m: mockModel}
+
+	e := echo.New()
+	e.GET("/ws/:roomID", func(c echo.Context) error {
+		return srv.ConnectRoomWS(c, uuid.MustParse(c.Param("roomID")))
+	})
+
+	ts := httptest.NewServer(e)
+	defer ts.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws/" + uuid.NewString()
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, http.Header{"Traceparent": {testTraceparent}})
+	require.NoError(t, err)
+
+	var welcome message
+	require.NoError(t, readJSONWithTimeout(t, conn, &welcome))
+	require.Equal(t, "welcome", welcome.Type)
+	require.NoError(t, conn.Close())
+
+	// Span сессии завершается после выхода участника
+	var span sdktrace.ReadOnlySpan
+	require.Eventually(t, func() bool {
+		span = endedSpan("ws.session")
+		return span != nil
+	}, 3*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, testParentSpan, span.Parent().SpanID().String())
+
+	var events []string
+	for _, ev := range span.Events() {
+		events = append(events, ev.Name)
+	}
+	assert.Equal(t, []string{"join", "leave"}, events)
+
+	var peerID string
+	for _, kv := range span.Attributes() {
+		if kv.Key == "peer.id" {
+			peerID = kv.Value.AsString()
+		}
+	}
+	assert.Equal(t, welcome.ID, peerID)
+}
@@ -2,19 +2,30 @@ package server
 
 import (
 	"encoding/json"
+	"log/slog"
 	"net/http"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	"github.com/labstack/echo/v4"
 	openapi_types "github.com/oapi-codegen/runtime/types"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
-	"github.com/vpbuyanov/syncplay/internal/gen"
+	"github.com/vpbuyanov/syncplay/internal/logging"
+	"github.com/vpbuyanov/syncplay/internal/model"
 )
 
+// Origin проверяется в ConnectRoomWS до апгрейда (checkWSOrigin),
+// чтобы ответить problem+json и учесть отказ в метриках.
+// Подпротокол "bearer" выбирается, когда клиент передаёт им токен (см. bearerToken).
 var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool { return true },
+	CheckOrigin:  func(r *http.Request) bool { return true },
+	Subprotocols: []string{wsTokenProtocol},
 }
 
 var (
@@ -23,17 +34,79 @@ var (
 )
 
 type roomSession struct {
-	Peers   map[string]*websocket.Conn
+	Peers   map[string]*peer
 	Session sync.Mutex
+	// startedAt — когда в комнату вошёл первый участник сессии
+	startedAt time.Time
+	// closed — комната закрыта сервером, уходящие участники не рассылают peer-left
+	closed bool
+	// messages — темп пересылаемых сообщений для админки
+	messages rateMeter
+}
+
+// writeWait — сколько ждать записи одного сообщения участнику.
+const writeWait = 10 * time.Second
+
+// peer — WS-соединение участника. gorilla/websocket не допускает
+// конкурентную запись, поэтому все записи идут через writeJSON.
+type peer struct {
+	conn *websocket.Conn
+	// user — sub из JWT; пусто, если участник подключился без токена
+	user        string
+	tenant      string
+	remoteIP    string
+	connectedAt time.Time
+	// pending — записи, ждущие writeMu, вместе с текущей
+	pending atomic.Int32
+	writeMu sync.Mutex
+}
+
+func (p *peer) writeJSON(v any) error {
+	p.pending.Add(1)
+	defer p.pending.Add(-1)
+
+	p.writeMu.Lock()
+	defer p.writeMu.Unlock()
+
+	// Зависший клиент не держит writeMu дольше writeWait: после таймаута
+	// соединение сломано и следующие записи сразу возвращают ошибку
+	_ = p.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	err := p.conn.WriteJSON(v)
+	if err != nil {
+		wsWriteErrors.WithLabelValues(messageType(v)).Inc()
+	}
+
+	return err
+}
+
+// close отправляет close-фрейм и закрывает соединение; цикл чтения
+// участника после этого завершается и выполняет обычную очистку.
+func (p *peer) close(code int, reason string) {
+	deadline := time.Now().Add(time.Second)
+	_ = p.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), deadline)
+	_ = p.conn.Close()
 }
 
 type message struct {
-	Type    string          `json:"type"`
-	ID      string          `json:"id,omitempty"`
-	Peers   []string        `json:"peers,omitempty"`
-	From    string          `json:"from,omitempty"`
-	To      string          `json:"to,omitempty"`
-	Payload json.RawMessage `json:"payload,omitempty"`
+	Type    string            `json:"type"`
+	ID      string            `json:"id,omitempty"`
+	Peers   []string          `json:"peers,omitempty"`
+	User    string            `json:"user,omitempty"`
+	Users   map[string]string `json:"users,omitempty"`
+	From    string            `json:"from,omitempty"`
+	To      string            `json:"to,omitempty"`
+	Payload json.RawMessage   `json:"payload,omitempty"`
+}
+
+type chatPayload struct {
+	ID        int64     `json:"id,omitempty"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type errorPayload struct {
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
 }
 
 // maybeDeleteRoom безопасно удаляет комнату из глобальной карты,
@@ -56,31 +129,114 @@ func maybeDeleteRoom(roomID openapi_types.UUID, sess *roomSession) {
 	}
 }
 
+// roomFull сообщает, достигнут ли в комнате лимит участников.
+func roomFull(roomID openapi_types.UUID, maxPeers int) bool {
+	if maxPeers <= 0 {
+		return false
+	}
+
+	roomsMu.Lock()
+	sess := rooms[roomID]
+	roomsMu.Unlock()
+
+	if sess == nil {
+		return false
+	}
+
+	sess.Session.Lock()
+	defer sess.Session.Unlock()
+
+	return len(sess.Peers) >= maxPeers
+}
+
+// roomPeerCounts возвращает снимок числа подключённых участников
+// по всем активным комнатам.
+func roomPeerCounts() map[openapi_types.UUID]int {
+	roomsMu.Lock()
+	defer roomsMu.Unlock()
+
+	res := make(map[openapi_types.UUID]int, len(rooms))
+	for id, sess := range rooms {
+		sess.Session.Lock()
+		if n := len(sess.Peers); n > 0 {
+			res[id] = n
+		}
+		sess.Session.Unlock()
+	}
+
+	return res
+}
+
 func (s *Server) ConnectRoomWS(c echo.Context, roomID openapi_types.UUID) error {
+	if s.draining.Load() {
+		return errors.Wrap(model.ErrUnavailable, "server is shutting down")
+	}
+
+	span := startSessionSpan(c, roomID)
+	defer span.End()
+	withLogAttrs(c, "room_id", roomID.String())
+
+	if err := s.checkWSOrigin(c); err != nil {
+		return err
+	}
+
+	tunables := s.currentTunables()
+
+	ip := c.RealIP()
+	if !s.limits.acquireWS(ip, tunables.RateLimit.WSPerIP) {
+		return errors.Wrap(model.ErrRateLimited, "too many connections")
+	}
+	defer s.limits.releaseWS(ip)
+
 	// Проверяем, что комната существует в БД до апгрейда
 	exists, err := s.m.RoomExistsUUID(c.Request().Context(), roomID)
 	if err != nil {
-		c.Logger().Errorf("RoomExistsUUID err: %v", err)
-		return c.JSON(http.StatusInternalServerError, gen.ErrorResponse{Detail: "db error"})
+		return err
 	}
 	if !exists {
-		return c.JSON(http.StatusNotFound, gen.ErrorResponse{Detail: "room not found"})
+		return errors.Wrap(model.ErrNotFound, "room not found")
 	}
 
-	// Upgrade до WebSocket
+	maxPeers := tunables.RoomMaxPeers
+	if roomFull(roomID, maxPeers) {
+		return errors.Wrap(model.ErrConflict, "room is full")
+	}
+
+	tenant := tenantFrom(c)
+	if !s.tenants.acquirePeer(tenant) {
+		return errors.Wrapf(model.ErrQuotaExceeded, "tenant peer limit of %d reached", tenant.Quotas.MaxPeers)
+	}
+	defer s.tenants.releasePeer(tenant.ID)
+
+	// Shutdown ждёт завершения обработчиков, чтобы закрыть хранилище последним
+	s.wsConns.Add(1)
+	defer s.wsConns.Done()
+
+	// Upgrade до WebSocket; при ошибке upgrader сам отвечает клиенту
 	ws, err := upgrader.Upgrade(c.Response().Writer, c.Request(), nil)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, gen.ErrorResponse{Detail: "ws upgrade failed"})
+		slog.ErrorContext(c.Request().Context(), "ws upgrade failed", "err", err)
+		return nil
 	}
 	defer ws.Close()
 
+	wsConnections.Inc()
+	defer wsConnections.Dec()
+
 	peerID := uuid.NewString()
+	self := &peer{conn: ws, tenant: tenant.Name, remoteIP: ip, connectedAt: time.Now()}
+	if claims := claimsFrom(c); claims != nil {
+		self.user = claims.Subject
+	}
+	span.SetAttributes(attribute.String("peer.id", peerID))
+	withLogAttrs(c, "peer_id", peerID)
+	ctx := c.Request().Context()
 
 	// Получаем/создаём сессию комнаты (под глобальным локом)
 	roomsMu.Lock()
 	sess, ok := rooms[roomID]
 	if !ok {
-		sess = &roomSession{Peers: make(map[string]*websocket.Conn)}
+		sess = &roomSession{Peers: make(map[string]*peer), startedAt: time.Now()}
 		rooms[roomID] = sess
 	}
 	roomsMu.Unlock()
@@ -89,15 +245,44 @@ func (s *Server) ConnectRoomWS(c echo.Context, roomID openapi_types.UUID) error
 	sess.Session.Lock()
 
 	existing := make([]string, 0, len(sess.Peers))
-	for id := range sess.Peers {
+	users := make(map[string]string)
+	for id, pc := range sess.Peers {
 		existing = append(existing, id)
+		if pc.user != "" {
+			users[id] = pc.user
+		}
+	}
+
+	// Сервер мог начать остановку во время апгрейда
+	if s.draining.Load() {
+		sess.Session.Unlock()
+		self.close(websocket.CloseServiceRestart, "server shutdown")
+		maybeDeleteRoom(roomID, sess)
+		return nil
+	}
+
+	// CloseRoom мог закрыть сессию после того, как мы взяли её из rooms:
+	// иначе участник остался бы в сессии, которой больше нет
+	if sess.closed {
+		sess.Session.Unlock()
+		self.close(closeRoomClosed, "room closed")
+		maybeDeleteRoom(roomID, sess)
+		return nil
+	}
+
+	// Между проверкой и апгрейдом комнату могли заполнить
+	if maxPeers > 0 && len(sess.Peers) >= maxPeers {
+		sess.Session.Unlock()
+		self.close(closeRoomFull, "room is full")
+		maybeDeleteRoom(roomID, sess)
+		return nil
 	}
 
 	// Добавляем себя
-	sess.Peers[peerID] = ws
+	sess.Peers[peerID] = self
 
 	// Получатели "new-peer" (все, кроме нас)
-	recipients := make([]*websocket.Conn, 0, len(sess.Peers)-1)
+	recipients := make([]*peer, 0, len(sess.Peers)-1)
 	for id, pc := range sess.Peers {
 		if id != peerID {
 			recipients = append(recipients, pc)
@@ -106,65 +291,94 @@ func (s *Server) ConnectRoomWS(c echo.Context, roomID openapi_types.UUID) error
 
 	sess.Session.Unlock()
 
+	span.AddEvent("join", trace.WithAttributes(attribute.Int("peers.existing", len(existing))))
+	s.touchRoom(c, roomID)
+
 	// Приветствие нового
-	if err = ws.WriteJSON(message{Type: "welcome", ID: peerID}); err != nil {
+	if err = self.writeJSON(message{Type: "welcome", ID: peerID, User: self.user}); err != nil {
 		return err
 	}
-	if err = ws.WriteJSON(message{Type: "existing-peers", Peers: existing}); err != nil {
+	if err = self.writeJSON(message{Type: "existing-peers", Peers: existing, Users: users}); err != nil {
 		return err
 	}
 	for _, pc := range recipients {
-		if err = pc.WriteJSON(message{Type: "new-peer", ID: peerID}); err != nil {
-			c.Logger().Errorf("failed to send 'new-peer' (roomID=%s, newPeer=%s): %v", roomID, peerID, err)
+		if err = pc.writeJSON(message{Type: "new-peer", ID: peerID, User: self.user}); err != nil {
+			slog.ErrorContext(ctx, "failed to send 'new-peer'", "err", err)
 		}
 	}
 
 	// Основной цикл сигналинга
+	var limitNotice time.Time
 	for {
 		var msg message
 		if err = ws.ReadJSON(&msg); err != nil {
 			break
 		}
+		if !s.allowMessage(c, sess, roomID, peerID, &limitNotice) {
+			wsDropped.WithLabelValues(droppedType(msg.Type)).Inc()
+			span.AddEvent("rate-limited")
+			continue
+		}
+		if msg.Type == "chat" {
+			s.countMessage(sess, "chat")
+			span.AddEvent("chat")
+			s.relayChat(c, roomID, sess, peerID, msg.Payload)
+			continue
+		}
 		if msg.Type != "signal" || msg.To == "" {
+			// Тип приходит от клиента, в метку его не пишем
+			wsDropped.WithLabelValues("unknown").Inc()
 			continue
 		}
 
 		// Берём ссылку на получателя под локом сессии
-		var dest *websocket.Conn
+		var dest *peer
 		sess.Session.Lock()
 		dest = sess.Peers[msg.To]
 		sess.Session.Unlock()
 
+		if dest == nil {
+			wsDropped.WithLabelValues("signal").Inc()
+			continue
+		}
+
 		// Пишем уже без лока (ошибки логируем)
-		if dest != nil {
-			if err = dest.WriteJSON(message{
-				Type:    "signal",
-				From:    peerID,
-				To:      msg.To,
-				Payload: msg.Payload,
-			}); err != nil {
-				c.Logger().Errorf("failed to forward signal: roomID=%s from=%s to=%s: %v", roomID, peerID, msg.To, err)
-				break
-			}
+		s.countMessage(sess, "signal")
+		span.AddEvent("signal", trace.WithAttributes(attribute.String("peer.to", msg.To)))
+		if err = dest.writeJSON(message{
+			Type:    "signal",
+			From:    peerID,
+			To:      msg.To,
+			Payload: msg.Payload,
+		}); err != nil {
+			slog.ErrorContext(ctx, "failed to forward signal", "to", msg.To, "err", err)
+			break
 		}
 	}
 
+	s.limits.peer.forget(peerID)
+
 	// Клиент уходит: удаляем из Peers и шлём 'peer-left' остальным
-	var leftRecipients []*websocket.Conn
+	var leftRecipients []*peer
 	sess.Session.Lock()
 
 	delete(sess.Peers, peerID)
 
-	leftRecipients = make([]*websocket.Conn, 0, len(sess.Peers))
-	for _, pc := range sess.Peers {
-		leftRecipients = append(leftRecipients, pc)
+	if !sess.closed {
+		leftRecipients = make([]*peer, 0, len(sess.Peers))
+		for _, pc := range sess.Peers {
+			leftRecipients = append(leftRecipients, pc)
+		}
 	}
 
 	sess.Session.Unlock()
 
+	span.AddEvent("leave")
+	s.touchRoom(c, roomID)
+
 	for _, pc := range leftRecipients {
-		if err = pc.WriteJSON(message{Type: "peer-left", ID: peerID}); err != nil {
-			c.Logger().Errorf("failed to notify 'peer-left' (roomID=%s, leftPeer=%s): %v", roomID, peerID, err)
+		if err = pc.writeJSON(message{Type: "peer-left", ID: peerID, User: self.user}); err != nil {
+			slog.ErrorContext(ctx, "failed to notify 'peer-left'", "err", err)
 		}
 	}
 
@@ -173,3 +387,107 @@ func (s *Server) ConnectRoomWS(c echo.Context, roomID openapi_types.UUID) error
 
 	return nil
 }
+
+// allowMessage проверяет лимиты участника и комнаты и квоту сообщений
+// арендатора. О превышении участник
+// узнаёт кадром "error" с кодом rate-limited не чаще раза в rateLimitNotice,
+// чтобы ответы не умножали поток.
+func (s *Server) allowMessage(c echo.Context, sess *roomSession, roomID openapi_types.UUID, peerID string, notified *time.Time) bool {
+	ok, _ := s.limits.peer.allow(peerID)
+	if ok {
+		ok, _ = s.limits.room.allow(roomID.String())
+	}
+	if ok {
+		ok = s.tenants.allowMessage(tenantFrom(c))
+	}
+	if ok {
+		return true
+	}
+
+	if now := time.Now(); now.Sub(*notified) >= rateLimitNotice {
+		*notified = now
+		sendError(c, sess, peerID, model.CodeRateLimited, "too many messages, slow down")
+	}
+
+	return false
+}
+
+// droppedType — метка отброшенного кадра; тип приходит от клиента,
+// поэтому неизвестные типы в метку не попадают.
+func droppedType(t string) string {
+	if t == "chat" || t == "signal" {
+		return t
+	}
+
+	return "unknown"
+}
+
+// withLogAttrs добавляет атрибуты ко всем логам, сделанным с контекстом запроса.
+func withLogAttrs(c echo.Context, args ...any) {
+	c.SetRequest(c.Request().WithContext(logging.With(c.Request().Context(), args...)))
+}
+
+// relayChat сохраняет сообщение чата и рассылает его всем участникам комнаты,
+// включая отправителя — так клиент получает присвоенные id и время.
+func (s *Server) relayChat(c echo.Context, roomID openapi_types.UUID, sess *roomSession, peerID string, payload json.RawMessage) {
+	var in chatPayload
+	if err := json.Unmarshal(payload, &in); err != nil {
+		sendError(c, sess, peerID, "invalid-chat", "chat payload must be {\"text\": \"...\"}")
+		return
+	}
+
+	saved, err := s.m.SaveChatMessage(c.Request().Context(), roomID.String(), peerID, in.Text)
+	if err != nil {
+		if errors.Is(err, model.ErrInvalidArgument) {
+			sendError(c, sess, peerID, "invalid-chat", err.Error())
+			return
+		}
+
+		slog.ErrorContext(c.Request().Context(), "failed to save chat message", "err", err)
+		sendError(c, sess, peerID, "chat-unavailable", "chat message was not saved")
+
+		return
+	}
+
+	out, err := json.Marshal(chatPayload{
+		ID:        saved.ID,
+		Text:      saved.Text,
+		CreatedAt: saved.CreatedAt,
+	})
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "failed to encode chat message", "err", err)
+		return
+	}
+
+	for _, pc := range sess.snapshot() {
+		if err = pc.writeJSON(message{Type: "chat", From: peerID, Payload: out}); err != nil {
+			slog.ErrorContext(c.Request().Context(), "failed to send 'chat'", "err", err)
+		}
+	}
+}
+
+// sendError отправляет участнику кадр "error" с машиночитаемым кодом.
+func sendError(c echo.Context, sess *roomSession, peerID, code, detail string) {
+	payload, err := json.Marshal(errorPayload{Code: code, Detail: detail})
+	if err != nil {
+		return
+	}
+
+	sess.Session.Lock()
+	pc := sess.Peers[peerID]
+	sess.Session.Unlock()
+
+	if pc == nil {
+		return
+	}
+
+	if err = pc.writeJSON(message{Type: "error", Payload: payload}); err != nil {
+		slog.ErrorContext(c.Request().Context(), "failed to send 'error'", "err", err)
+	}
+}
+
+func (s *Server) touchRoom(c echo.Context, roomID openapi_types.UUID) {
+	if err := s.m.TouchRoomUUID(c.Request().Context(), roomID); err != nil {
+		slog.ErrorContext(c.Request().Context(), "failed to touch room", "err", err)
+	}
+}
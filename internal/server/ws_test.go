@@ -12,9 +12,10 @@ import (
 	"github.com/gorilla/websocket"
 	"github.com/labstack/echo/v4"
 	openapi_types "github.com/oapi-codegen/runtime/types"
+	"github.com/pkg/errors"
 	"go.uber.org/mock/gomock"
 
-	"github.com/vpbuyanov/syncplay/internal/gen"
+	"github.com/vpbuyanov/syncplay/internal/model"
 )
 
 // дедлайн на чтение, чтобы тесты не висели
@@ -38,6 +39,7 @@ func TestConnectRoomWS_TwoPeersSignalAndLeave_ModelMock(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockModel := NewMockmodelRoom(ctrl)
+	mockModel.EXPECT().TouchRoomUUID(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	srv := &Server{m: mockModel}
 
 	e := echo.New()
@@ -45,7 +47,7 @@ func TestConnectRoomWS_TwoPeersSignalAndLeave_ModelMock(t *testing.T) {
 		rid := c.Param("roomID")
 		u, err := uuid.Parse(rid)
 		if err != nil {
-			return c.JSON(http.StatusBadRequest, gen.ErrorResponse{Detail: "invalid room ID"})
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid room ID")
 		}
 		return srv.ConnectRoomWS(c, u)
 	})
@@ -161,6 +163,7 @@ func TestConnectRoomWS_SignalToRandomPeer_DoesNotPanic_ModelMock(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockModel := NewMockmodelRoom(ctrl)
+	mockModel.EXPECT().TouchRoomUUID(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	srv := &Server{m: mockModel}
 
 	e := echo.New()
@@ -168,7 +171,7 @@ func TestConnectRoomWS_SignalToRandomPeer_DoesNotPanic_ModelMock(t *testing.T) {
 		rid := c.Param("roomID")
 		u, err := uuid.Parse(rid)
 		if err != nil {
-			return c.JSON(http.StatusBadRequest, gen.ErrorResponse{Detail: "invalid room ID"})
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid room ID")
 		}
 		return srv.ConnectRoomWS(c, u)
 	})
@@ -218,14 +221,16 @@ func TestConnectRoomWS_RoomNotFound_BeforeUpgrade(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockModel := NewMockmodelRoom(ctrl)
+	mockModel.EXPECT().TouchRoomUUID(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	srv := &Server{m: mockModel}
 
 	e := echo.New()
+	e.HTTPErrorHandler = srv.handleError
 	e.GET("/ws/:roomID", func(c echo.Context) error {
 		rid := c.Param("roomID")
 		u, err := uuid.Parse(rid)
 		if err != nil {
-			return c.JSON(http.StatusBadRequest, gen.ErrorResponse{Detail: "invalid room ID"})
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid room ID")
 		}
 		return srv.ConnectRoomWS(c, u)
 	})
@@ -255,3 +260,164 @@ func TestConnectRoomWS_RoomNotFound_BeforeUpgrade(t *testing.T) {
 		t.Fatalf("unexpected status code: %d", resp.StatusCode)
 	}
 }
+
+func TestConnectRoomWS_ChatIsSavedAndBroadcast_ModelMock(t *testing.T) {
+	clearRooms()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockModel := NewMockmodelRoom(ctrl)
+	mockModel.EXPECT().TouchRoomUUID(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	srv := &Server{m: mockModel}
+
+	e := echo.New()
+	e.GET("/ws/:roomID", func(c echo.Context) error {
+		u, err := uuid.Parse(c.Param("roomID"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid room ID")
+		}
+		return srv.ConnectRoomWS(c, u)
+	})
+
+	ts := httptest.NewServer(e)
+	defer ts.Close()
+
+	roomID := uuid.New()
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws/" + roomID.String()
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	mockModel.
+		EXPECT().
+		RoomExistsUUID(gomock.Any(), roomID).
+		Return(true, nil).
+		Times(2)
+
+	peer1, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("peer1 dial failed: %v", err)
+	}
+	defer peer1.Close()
+
+	var w1, ex1 message
+	if err = readJSONWithTimeout(t, peer1, &w1); err != nil {
+		t.Fatalf("peer1 welcome read: %v", err)
+	}
+	if err = readJSONWithTimeout(t, peer1, &ex1); err != nil {
+		t.Fatalf("peer1 existing read: %v", err)
+	}
+
+	peer2, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("peer2 dial failed: %v", err)
+	}
+	defer peer2.Close()
+
+	var w2, ex2, np message
+	if err = readJSONWithTimeout(t, peer2, &w2); err != nil {
+		t.Fatalf("peer2 welcome read: %v", err)
+	}
+	if err = readJSONWithTimeout(t, peer2, &ex2); err != nil {
+		t.Fatalf("peer2 existing read: %v", err)
+	}
+	if err = readJSONWithTimeout(t, peer1, &np); err != nil {
+		t.Fatalf("peer1 new-peer read: %v", err)
+	}
+
+	mockModel.
+		EXPECT().
+		SaveChatMessage(gomock.Any(), roomID.String(), w1.ID, "hello").
+		Return(model.ChatMessage{ID: 9, RoomID: roomID.String(), Sender: w1.ID, Text: "hello", CreatedAt: createdAt}, nil)
+
+	if err = peer1.WriteJSON(message{Type: "chat", Payload: json.RawMessage(`{"text":"hello"}`)}); err != nil {
+		t.Fatalf("peer1 send chat: %v", err)
+	}
+
+	for name, conn := range map[string]*websocket.Conn{"peer1": peer1, "peer2": peer2} {
+		var got message
+		if err = readJSONWithTimeout(t, conn, &got); err != nil {
+			t.Fatalf("%s chat read: %v", name, err)
+		}
+		if got.Type != "chat" || got.From != w1.ID {
+			t.Fatalf("%s unexpected chat: %+v", name, got)
+		}
+		var payload chatPayload
+		if err = json.Unmarshal(got.Payload, &payload); err != nil {
+			t.Fatalf("%s chat payload: %v", name, err)
+		}
+		if payload.ID != 9 || payload.Text != "hello" || !payload.CreatedAt.Equal(createdAt) {
+			t.Fatalf("%s chat payload mismatch: %+v", name, payload)
+		}
+	}
+
+	// Невалидное сообщение — ошибка только отправителю
+	mockModel.
+		EXPECT().
+		SaveChatMessage(gomock.Any(), roomID.String(), w2.ID, "").
+		Return(model.ChatMessage{}, errors.Wrap(model.ErrInvalidArgument, "empty chat message"))
+
+	if err = peer2.WriteJSON(message{Type: "chat", Payload: json.RawMessage(`{"text":""}`)}); err != nil {
+		t.Fatalf("peer2 send chat: %v", err)
+	}
+
+	var errMsg message
+	if err = readJSONWithTimeout(t, peer2, &errMsg); err != nil {
+		t.Fatalf("peer2 error read: %v", err)
+	}
+	if errMsg.Type != "error" || !strings.Contains(string(errMsg.Payload), "invalid-chat") {
+		t.Fatalf("unexpected error frame: %+v", errMsg)
+	}
+}
+
+func TestConnectRoomWS_RoomFull(t *testing.T) {
+	clearRooms()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockModel := NewMockmodelRoom(ctrl)
+	mockModel.EXPECT().TouchRoomUUID(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockModel.EXPECT().RoomExistsUUID(gomock.Any(), gomock.Any()).Return(true, nil).AnyTimes()
+	srv := &Server{m: mockModel}
+	srv.SetTunables(Tunables{RoomMaxPeers: 1})
+
+	e := echo.New()
+	e.HTTPErrorHandler = srv.handleError
+	e.GET("/ws/:roomID", func(c echo.Context) error {
+		return srv.ConnectRoomWS(c, uuid.MustParse(c.Param("roomID")))
+	})
+
+	ts := httptest.NewServer(e)
+	defer ts.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws/" + uuid.New().String()
+
+	peer1, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("peer1 dial failed: %v", err)
+	}
+	defer peer1.Close()
+
+	var w1 message
+	if err = readJSONWithTimeout(t, peer1, &w1); err != nil {
+		t.Fatalf("peer1 welcome read: %v", err)
+	}
+
+	// Второй участник не помещается в комнату
+	_, resp, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err == nil {
+		t.Fatal("expected bad handshake error, got nil")
+	}
+	if resp == nil || resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected 409, got %v", resp)
+	}
+
+	// Лимит сняли на лету — подключение проходит
+	srv.SetTunables(Tunables{})
+
+	peer2, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("peer2 dial failed: %v", err)
+	}
+	defer peer2.Close()
+}
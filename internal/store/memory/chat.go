@@ -0,0 +1,27 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+func (s *Store) SaveChatMessage(ctx context.Context, msg model.ChatMessage) (model.ChatMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Как и внешний ключ в Postgres, требуем только наличие строки комнаты
+	if _, ok := s.lookup(ctx, msg.RoomID); !ok {
+		return model.ChatMessage{}, errors.Wrap(model.ErrNotFound, "room not found")
+	}
+
+	s.lastMsgID++
+	msg.ID = s.lastMsgID
+	msg.CreatedAt = s.timestamp()
+
+	s.chat[msg.RoomID] = append(s.chat[msg.RoomID], msg)
+
+	return msg, nil
+}
@@ -0,0 +1,156 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+func (s *Store) TouchRoom(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r, ok := s.lookup(ctx, id); ok {
+		r.lastActiveAt = s.timestamp()
+	}
+
+	return nil
+}
+
+func (s *Store) ExpiringRooms(ctx context.Context, before time.Time, limit int) ([]model.RoomInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var res []*room
+	for _, r := range s.rooms {
+		if r.inScope(ctx) && r.deletedAt == nil && r.archivedAt == nil &&
+			r.info.ExpiresAt != nil && !r.info.ExpiresAt.After(before) {
+			res = append(res, r)
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].info.ExpiresAt.Before(*res[j].info.ExpiresAt)
+	})
+
+	out := make([]model.RoomInfo, 0, limit)
+	for _, r := range res[:min(limit, len(res))] {
+		expiresAt := *r.info.ExpiresAt
+		out = append(out, model.RoomInfo{ID: r.info.ID, RoomMeta: model.RoomMeta{ExpiresAt: &expiresAt}})
+	}
+
+	return out, nil
+}
+
+func (s *Store) IdleRooms(ctx context.Context, before time.Time, exclude []string, limit int) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	skip := make(map[string]struct{}, len(exclude))
+	for _, id := range exclude {
+		skip[id] = struct{}{}
+	}
+
+	var res []*room
+	for id, r := range s.rooms {
+		if _, ok := skip[id]; ok {
+			continue
+		}
+		if r.inScope(ctx) && r.deletedAt == nil && r.archivedAt == nil && r.lastActiveAt.Before(before) {
+			res = append(res, r)
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].lastActiveAt.Before(res[j].lastActiveAt)
+	})
+
+	return roomIDs(res, limit), nil
+}
+
+func (s *Store) ArchiveRooms(ctx context.Context, ids []string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.timestamp()
+
+	var n int64
+	for _, id := range ids {
+		if r, ok := s.lookup(ctx, id); ok && r.archivedAt == nil && r.deletedAt == nil {
+			r.archivedAt = &now
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// PurgeRooms окончательно удаляет комнаты вместе с историей чата.
+func (s *Store) PurgeRooms(ctx context.Context, ids []string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int64
+	for _, id := range ids {
+		if _, ok := s.lookup(ctx, id); ok {
+			delete(s.chat, id)
+			delete(s.rooms, id)
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// RestoreRoomById повторяет семантику StorePG.RestoreRoomById.
+func (s *Store) RestoreRoomById(ctx context.Context, id string, deletedAfter time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.timestamp()
+
+	r, ok := s.lookup(ctx, id)
+	if !ok || r.alive(now) || (r.deletedAt != nil && r.deletedAt.Before(deletedAfter)) {
+		return errors.Wrap(model.ErrNotFound, "room not found or restore window has passed")
+	}
+
+	r.deletedAt = nil
+	r.archivedAt = nil
+	r.lastActiveAt = now
+	if r.info.ExpiresAt != nil && !r.info.ExpiresAt.After(now) {
+		r.info.ExpiresAt = nil
+	}
+
+	return nil
+}
+
+func (s *Store) DeletedRooms(ctx context.Context, before time.Time, limit int) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var res []*room
+	for _, r := range s.rooms {
+		if r.inScope(ctx) && r.deletedAt != nil && r.deletedAt.Before(before) {
+			res = append(res, r)
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].deletedAt.Before(*res[j].deletedAt)
+	})
+
+	return roomIDs(res, limit), nil
+}
+
+func roomIDs(rooms []*room, limit int) []string {
+	res := make([]string, 0, limit)
+	for _, r := range rooms[:min(limit, len(rooms))] {
+		res = append(res, r.info.ID)
+	}
+
+	return res
+}
@@ -0,0 +1,272 @@
+// Package memory — хранилище комнат в памяти процесса. Используется в режиме
+// разработки и в тестах; данные теряются при перезапуске.
+package memory
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+var _ model.Store = (*Store)(nil)
+
+type room struct {
+	info         model.RoomInfo
+	tenant       string
+	ownerHash    []byte
+	lastActiveAt time.Time
+	archivedAt   *time.Time
+	deletedAt    *time.Time
+}
+
+// inScope сообщает, видна ли комната арендатору запроса; запросы без
+// арендатора видят все комнаты.
+func (r *room) inScope(ctx context.Context) bool {
+	t, ok := model.TenantFrom(ctx)
+
+	return !ok || r.tenant == t.ID
+}
+
+// alive повторяет условие roomAlive хранилища Postgres.
+func (r *room) alive(now time.Time) bool {
+	return r.deletedAt == nil && r.archivedAt == nil &&
+		(r.info.ExpiresAt == nil || r.info.ExpiresAt.After(now))
+}
+
+type Store struct {
+	mu        sync.RWMutex
+	rooms     map[string]*room
+	chat      map[string][]model.ChatMessage
+	lastMsgID int64
+	users     map[string]*user
+	sessions  map[string]session
+	tenants   map[string]*tenant
+	now       func() time.Time
+}
+
+func New() *Store {
+	return &Store{
+		rooms:    make(map[string]*room),
+		chat:     make(map[string][]model.ChatMessage),
+		users:    make(map[string]*user),
+		sessions: make(map[string]session),
+		tenants:  make(map[string]*tenant),
+		now:      time.Now,
+	}
+}
+
+// Ping всегда успешен: хранилищу в памяти не к чему подключаться.
+func (s *Store) Ping(_ context.Context) error {
+	return nil
+}
+
+// timestamp возвращает текущее время с точностью Postgres timestamp.
+func (s *Store) timestamp() time.Time {
+	return s.now().UTC().Truncate(time.Microsecond)
+}
+
+// lookup возвращает комнату, если она есть и видна арендатору запроса.
+func (s *Store) lookup(ctx context.Context, id string) (*room, bool) {
+	r, ok := s.rooms[id]
+	if !ok || !r.inScope(ctx) {
+		return nil, false
+	}
+
+	return r, true
+}
+
+func (s *Store) CreateRoomById(ctx context.Context, id string, meta model.RoomMeta, ownerHash []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.rooms[id]; ok {
+		return errors.Wrap(model.ErrConflict, "room already exists")
+	}
+
+	now := s.timestamp()
+	meta.Tags = slices.Clone(meta.Tags)
+	if meta.Tags == nil {
+		meta.Tags = []string{}
+	}
+	if meta.ExpiresAt != nil {
+		t := meta.ExpiresAt.UTC().Truncate(time.Microsecond)
+		meta.ExpiresAt = &t
+	}
+
+	t, ok := model.TenantFrom(ctx)
+	if ok && t.ID != "" && t.Quotas.MaxRooms > 0 {
+		var n int
+		for _, r := range s.rooms {
+			if r.alive(now) && r.tenant == t.ID {
+				n++
+			}
+		}
+		if n >= t.Quotas.MaxRooms {
+			return errors.Wrapf(model.ErrQuotaExceeded, "tenant room limit of %d reached", t.Quotas.MaxRooms)
+		}
+	}
+
+	s.rooms[id] = &room{
+		info:         model.RoomInfo{ID: id, RoomMeta: meta, CreatedAt: now},
+		tenant:       t.ID,
+		ownerHash:    bytes.Clone(ownerHash),
+		lastActiveAt: now,
+	}
+
+	return nil
+}
+
+func (s *Store) DeleteRoomById(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.lookup(ctx, id)
+	if !ok || r.deletedAt != nil {
+		return errors.Wrap(model.ErrNotFound, "room not found")
+	}
+
+	now := s.timestamp()
+	r.deletedAt = &now
+
+	return nil
+}
+
+func (s *Store) RoomExists(ctx context.Context, id string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	r, ok := s.lookup(ctx, id)
+
+	return ok && r.alive(s.now()), nil
+}
+
+func (s *Store) RoomOwnerHash(ctx context.Context, id string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	r, ok := s.lookup(ctx, id)
+	if !ok {
+		return nil, errors.Wrap(model.ErrNotFound, "room not found")
+	}
+
+	return bytes.Clone(r.ownerHash), nil
+}
+
+func (s *Store) RoomCreatedBy(ctx context.Context, id string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	r, ok := s.lookup(ctx, id)
+	if !ok {
+		return "", errors.Wrap(model.ErrNotFound, "room not found")
+	}
+
+	return r.info.CreatedBy, nil
+}
+
+func (s *Store) ListRooms(ctx context.Context, f model.RoomFilter) ([]model.RoomInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	active := make(map[string]struct{}, len(f.ActiveIDs))
+	for _, id := range f.ActiveIDs {
+		active[id.String()] = struct{}{}
+	}
+
+	now := s.now()
+	byTitle, desc := sortKey(f.Sort)
+
+	res := make([]model.RoomInfo, 0, f.Limit)
+	for _, r := range s.rooms {
+		if !r.alive(now) || !r.inScope(ctx) || !matchFilter(r.info, f, active) {
+			continue
+		}
+		if f.After != nil && !after(r.info, *f.After, byTitle, desc) {
+			continue
+		}
+		res = append(res, copyInfo(r.info))
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return after(res[j], res[i], byTitle, desc)
+	})
+
+	if len(res) > f.Limit {
+		res = res[:f.Limit]
+	}
+
+	return res, nil
+}
+
+func matchFilter(r model.RoomInfo, f model.RoomFilter, active map[string]struct{}) bool {
+	if f.Visibility != "" && r.Visibility != f.Visibility {
+		return false
+	}
+	if f.CreatedAfter != nil && !r.CreatedAt.After(*f.CreatedAfter) {
+		return false
+	}
+	if f.CreatedBy != "" && r.CreatedBy != f.CreatedBy {
+		return false
+	}
+	if f.Tag != "" && !slices.Contains(r.Tags, f.Tag) {
+		return false
+	}
+	if f.HasActivePeers != nil {
+		if _, ok := active[r.ID]; ok != *f.HasActivePeers {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sortKey возвращает признак сортировки по названию и признак убывания.
+func sortKey(sort string) (bool, bool) {
+	switch sort {
+	case model.SortTitleAsc:
+		return true, false
+	case model.SortTitleDesc:
+		return true, true
+	case model.SortCreatedAsc:
+		return false, false
+	default:
+		return false, true
+	}
+}
+
+// after сообщает, идёт ли r после last в порядке сортировки по (ключ, id).
+func after(r, last model.RoomInfo, byTitle, desc bool) bool {
+	var c int
+	if byTitle {
+		c = strings.Compare(r.Title, last.Title)
+	} else {
+		c = r.CreatedAt.Compare(last.CreatedAt)
+	}
+	if c == 0 {
+		c = strings.Compare(r.ID, last.ID)
+	}
+
+	if desc {
+		return c < 0
+	}
+
+	return c > 0
+}
+
+func copyInfo(r model.RoomInfo) model.RoomInfo {
+	r.Tags = slices.Clone(r.Tags)
+	if r.ExpiresAt != nil {
+		t := *r.ExpiresAt
+		r.ExpiresAt = &t
+	}
+
+	return r
+}
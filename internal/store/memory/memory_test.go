@@ -0,0 +1,14 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/vpbuyanov/syncplay/internal/model"
+	"github.com/vpbuyanov/syncplay/internal/store/storetest"
+)
+
+func TestStore_Conformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) model.Store {
+		return New()
+	})
+}
@@ -0,0 +1,295 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+// Веса ts_rank для частей документа: A — название, B — описание.
+const (
+	weightA = 1.0
+	weightB = 0.4
+)
+
+// headlineWords — максимальная длина фрагмента, как MaxWords в ts_headline.
+const headlineWords = 30
+
+// term — слово или фраза запроса; neg — исключающий терм ("-word").
+type term struct {
+	words []string
+	neg   bool
+}
+
+// query — упрощённый аналог websearch_to_tsquery('simple'):
+// дизъюнкция (OR) конъюнкций термов.
+type query [][]term
+
+func parseQuery(q string) query {
+	var (
+		res   query
+		group []term
+	)
+
+	for _, tok := range splitQuery(q) {
+		if strings.EqualFold(tok, "or") {
+			if len(group) > 0 {
+				res = append(res, group)
+				group = nil
+			}
+			continue
+		}
+
+		neg := strings.HasPrefix(tok, "-")
+		words := tokenize(strings.TrimPrefix(tok, "-"))
+		if len(words) == 0 {
+			continue
+		}
+
+		group = append(group, term{words: words, neg: neg})
+	}
+
+	if len(group) > 0 {
+		res = append(res, group)
+	}
+
+	return res
+}
+
+// splitQuery делит запрос по пробелам, сохраняя фразы в кавычках целиком.
+func splitQuery(q string) []string {
+	var (
+		res    []string
+		cur    strings.Builder
+		quoted bool
+	)
+
+	flush := func() {
+		if cur.Len() > 0 {
+			res = append(res, cur.String())
+			cur.Reset()
+		}
+	}
+
+	for _, r := range q {
+		switch {
+		case r == '"':
+			flush()
+			quoted = !quoted
+		case unicode.IsSpace(r) && !quoted:
+			flush()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	flush()
+
+	return res
+}
+
+// tokenize разбивает текст на слова в нижнем регистре, как парсер 'simple'.
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func (q query) match(words []string) bool {
+	for _, group := range q {
+		ok := true
+		for _, t := range group {
+			if containsPhrase(words, t.words) == t.neg {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// positive возвращает множество слов запроса без исключающих термов.
+func (q query) positive() map[string]struct{} {
+	res := make(map[string]struct{})
+	for _, group := range q {
+		for _, t := range group {
+			if t.neg {
+				continue
+			}
+			for _, w := range t.words {
+				res[w] = struct{}{}
+			}
+		}
+	}
+
+	return res
+}
+
+func containsPhrase(words, phrase []string) bool {
+	for i := 0; i+len(phrase) <= len(words); i++ {
+		ok := true
+		for j, w := range phrase {
+			if words[i+j] != w {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func countWords(words []string, want map[string]struct{}) int {
+	n := 0
+	for _, w := range words {
+		if _, ok := want[w]; ok {
+			n++
+		}
+	}
+
+	return n
+}
+
+// headline выделяет в тексте слова запроса маркерами model.HighlightStart/Stop
+// и обрезает его до фрагмента вокруг первого совпадения.
+func headline(text string, want map[string]struct{}) string {
+	type span struct{ start, end int }
+
+	var spans []span
+	start := -1
+	for i, r := range text + " " {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case isWord && start < 0:
+			start = i
+		case !isWord && start >= 0:
+			spans = append(spans, span{start, i})
+			start = -1
+		}
+	}
+
+	first := -1
+	for i, sp := range spans {
+		if _, ok := want[strings.ToLower(text[sp.start:sp.end])]; ok {
+			first = i
+			break
+		}
+	}
+
+	from, to := 0, len(spans)
+	if len(spans) > headlineWords {
+		from = max(0, first-headlineWords/3)
+		to = min(len(spans), from+headlineWords)
+	}
+	if from == to {
+		return ""
+	}
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("… ")
+	}
+
+	pos := spans[from].start
+	for _, sp := range spans[from:to] {
+		b.WriteString(text[pos:sp.start])
+		word := text[sp.start:sp.end]
+		if _, ok := want[strings.ToLower(word)]; ok {
+			b.WriteString(model.HighlightStart + word + model.HighlightStop)
+		} else {
+			b.WriteString(word)
+		}
+		pos = sp.end
+	}
+
+	if to < len(spans) {
+		b.WriteString(" …")
+	}
+
+	return b.String()
+}
+
+func (s *Store) SearchRooms(ctx context.Context, q string, limit int) ([]model.SearchHit, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	parsed := parseQuery(q)
+	want := parsed.positive()
+	now := s.now()
+
+	res := make([]model.SearchHit, 0, limit)
+	for _, r := range s.rooms {
+		if r.info.Visibility != model.VisibilityPublic || !r.alive(now) || !r.inScope(ctx) {
+			continue
+		}
+
+		title, desc := tokenize(r.info.Title), tokenize(r.info.Description)
+		if !parsed.match(append(append([]string{}, title...), desc...)) {
+			continue
+		}
+
+		res = append(res, model.SearchHit{
+			Kind:      model.SearchKindRoom,
+			RoomID:    r.info.ID,
+			Title:     r.info.Title,
+			Snippet:   headline(r.info.Title+" "+r.info.Description, want),
+			Rank:      float32(weightA*float64(countWords(title, want)) + weightB*float64(countWords(desc, want))),
+			CreatedAt: r.info.CreatedAt,
+		})
+	}
+
+	return topHits(res, limit), nil
+}
+
+func (s *Store) SearchChat(ctx context.Context, roomID, q string, limit int) ([]model.SearchHit, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.lookup(ctx, roomID); !ok {
+		return []model.SearchHit{}, nil
+	}
+
+	parsed := parseQuery(q)
+	want := parsed.positive()
+
+	res := make([]model.SearchHit, 0, limit)
+	for _, m := range s.chat[roomID] {
+		words := tokenize(m.Text)
+		if !parsed.match(words) {
+			continue
+		}
+
+		res = append(res, model.SearchHit{
+			Kind:      model.SearchKindMessage,
+			RoomID:    m.RoomID,
+			MessageID: m.ID,
+			Sender:    m.Sender,
+			Snippet:   headline(m.Text, want),
+			Rank:      float32(countWords(words, want)),
+			CreatedAt: m.CreatedAt,
+		})
+	}
+
+	return topHits(res, limit), nil
+}
+
+// topHits сортирует совпадения как SQL-запросы: rank desc, created_at desc.
+func topHits(hits []model.SearchHit, limit int) []model.SearchHit {
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Rank != hits[j].Rank {
+			return hits[i].Rank > hits[j].Rank
+		}
+		return hits[i].CreatedAt.After(hits[j].CreatedAt)
+	})
+
+	return hits[:min(limit, len(hits))]
+}
@@ -0,0 +1,111 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+type tenant struct {
+	model.Tenant
+	keyHash []byte
+}
+
+func (s *Store) CreateTenant(_ context.Context, t model.Tenant, keyHash []byte) (model.Tenant, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.tenants[t.ID]; ok {
+		return model.Tenant{}, errors.Wrap(model.ErrConflict, "tenant already exists")
+	}
+	for _, other := range s.tenants {
+		if other.Name == t.Name || bytes.Equal(other.keyHash, keyHash) {
+			return model.Tenant{}, errors.Wrap(model.ErrConflict, "tenant already exists")
+		}
+	}
+
+	t.CreatedAt = s.timestamp()
+	s.tenants[t.ID] = &tenant{Tenant: t, keyHash: bytes.Clone(keyHash)}
+
+	return t, nil
+}
+
+func (s *Store) UpdateTenantQuotas(_ context.Context, name string, q model.TenantQuotas) (model.Tenant, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range s.tenants {
+		if t.Name == name {
+			t.Quotas = q
+			return t.Tenant, nil
+		}
+	}
+
+	return model.Tenant{}, errors.Wrap(model.ErrNotFound, "tenant not found")
+}
+
+func (s *Store) ListTenants(_ context.Context) ([]model.Tenant, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	res := make([]model.Tenant, 0, len(s.tenants))
+	for _, t := range s.tenants {
+		res = append(res, t.Tenant)
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+
+	return res, nil
+}
+
+func (s *Store) TenantByKeyHash(_ context.Context, keyHash []byte) (model.Tenant, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, t := range s.tenants {
+		if bytes.Equal(t.keyHash, keyHash) {
+			return t.Tenant, nil
+		}
+	}
+
+	return model.Tenant{}, errors.Wrap(model.ErrNotFound, "tenant not found")
+}
+
+// CountRooms считает живые комнаты арендатора запроса.
+func (s *Store) CountRooms(ctx context.Context) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.now()
+
+	var n int
+	for _, r := range s.rooms {
+		if r.alive(now) && r.inScope(ctx) {
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// CountRoomsByTenant считает живые комнаты по арендаторам; комнаты
+// арендатора по умолчанию лежат под пустым ключом.
+func (s *Store) CountRoomsByTenant(ctx context.Context) (map[string]int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.now()
+
+	res := make(map[string]int)
+	for _, r := range s.rooms {
+		if r.alive(now) && r.inScope(ctx) {
+			res[r.tenant]++
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,102 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+type user struct {
+	model.User
+	passwordHash string
+}
+
+type session struct {
+	userID    string
+	expiresAt time.Time
+}
+
+func (s *Store) CreateUser(_ context.Context, u model.User, passwordHash string) (model.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[u.ID]; ok {
+		return model.User{}, errors.Wrap(model.ErrConflict, "user already exists")
+	}
+	for _, other := range s.users {
+		if other.Username == u.Username {
+			return model.User{}, errors.Wrap(model.ErrConflict, "username is taken")
+		}
+	}
+
+	u.CreatedAt = s.timestamp()
+	s.users[u.ID] = &user{User: u, passwordHash: passwordHash}
+
+	return u, nil
+}
+
+func (s *Store) UserByName(_ context.Context, username string) (model.User, string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, u := range s.users {
+		if u.Username == username {
+			return u.User, u.passwordHash, nil
+		}
+	}
+
+	return model.User{}, "", errors.Wrap(model.ErrNotFound, "user not found")
+}
+
+func (s *Store) CreateSession(_ context.Context, tokenHash []byte, userID string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[userID]; !ok {
+		return errors.Wrap(model.ErrNotFound, "user not found")
+	}
+	if _, ok := s.sessions[string(tokenHash)]; ok {
+		return errors.Wrap(model.ErrConflict, "session already exists")
+	}
+
+	s.sessions[string(tokenHash)] = session{userID: userID, expiresAt: expiresAt.UTC().Truncate(time.Microsecond)}
+
+	return nil
+}
+
+func (s *Store) SessionByHash(_ context.Context, tokenHash []byte) (model.User, time.Time, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sess, ok := s.sessions[string(tokenHash)]
+	if !ok {
+		return model.User{}, time.Time{}, errors.Wrap(model.ErrNotFound, "session not found")
+	}
+
+	return s.users[sess.userID].User, sess.expiresAt, nil
+}
+
+func (s *Store) DeleteSession(_ context.Context, tokenHash []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, string(tokenHash))
+
+	return nil
+}
+
+func (s *Store) DeleteExpiredSessions(_ context.Context, userID string, before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for hash, sess := range s.sessions {
+		if sess.userID == userID && !sess.expiresAt.After(before) {
+			delete(s.sessions, hash)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package postgresql
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+func (s *StorePG) SaveChatMessage(ctx context.Context, msg model.ChatMessage) (model.ChatMessage, error) {
+	args := pgx.NamedArgs{
+		"room_id": msg.RoomID,
+		"sender":  msg.Sender,
+		"text":    msg.Text,
+	}
+
+	// Комната чужого арендатора не найдётся, и вставка не вернёт строк
+	err := s.db.QueryRow(ctx,
+		`insert into chat_messages (room_id, sender, text)
+		 select @room_id, @sender, @text
+		 where exists (select * from rooms where id = @room_id`+tenantScope(ctx, "tenant_id", args)+`)
+		 returning id, created_at`,
+		args,
+	).Scan(&msg.ID, &msg.CreatedAt)
+	if err != nil {
+		return model.ChatMessage{}, wrapErr(err, "insert chat message in pg")
+	}
+
+	return msg, nil
+}
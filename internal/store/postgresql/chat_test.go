@@ -0,0 +1,65 @@
+package postgresql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+func TestStorePG_SaveChatMessage(t *testing.T) {
+	ctx := context.Background()
+
+	msg := model.ChatMessage{RoomID: uuid.NewString(), Sender: "peer", Text: "hello"}
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	args := pgx.NamedArgs{"room_id": msg.RoomID, "sender": msg.Sender, "text": msg.Text}
+
+	type testRow struct {
+		name    string
+		setup   func(m *mocker)
+		want    model.ChatMessage
+		wantErr assert.ErrorAssertionFunc
+	}
+
+	tests := []testRow{
+		{
+			name: "success",
+			setup: func(m *mocker) {
+				m.conn.ExpectQuery(`insert into chat_messages \(room_id, sender, text\)`).
+					WithArgs(args).
+					WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(int64(42), createdAt))
+			},
+			want:    model.ChatMessage{ID: 42, RoomID: msg.RoomID, Sender: msg.Sender, Text: msg.Text, CreatedAt: createdAt},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "pg_error",
+			setup: func(m *mocker) {
+				m.conn.ExpectQuery(`insert into chat_messages \(room_id, sender, text\)`).
+					WithArgs(args).
+					WillReturnError(assert.AnError)
+			},
+			wantErr: assert.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := newMocker()
+			assert.NoError(t, err)
+
+			r := m.storePG()
+			tt.setup(m)
+
+			got, err := r.SaveChatMessage(ctx, msg)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package postgresql
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vpbuyanov/syncplay/internal/config"
+	"github.com/vpbuyanov/syncplay/internal/migrator"
+	"github.com/vpbuyanov/syncplay/internal/model"
+	"github.com/vpbuyanov/syncplay/internal/store/storetest"
+)
+
+// testDSNEnv — DSN тестовой базы. База очищается перед каждым тестом,
+// поэтому указывать рабочую базу нельзя.
+const testDSNEnv = "SYNCPLAY_TEST_POSTGRES_DSN"
+
+func TestStorePG_Conformance(t *testing.T) {
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDSNEnv)
+	}
+
+	cfg, err := pgx.ParseConfig(dsn)
+	require.NoError(t, err)
+
+	m, err := migrator.New(config.Storage{Driver: config.StoragePostgres}, config.Postgres{
+		Host:     cfg.Host,
+		User:     cfg.User,
+		Password: cfg.Password,
+		DBName:   cfg.Database,
+		Port:     int(cfg.Port),
+	})
+	require.NoError(t, err)
+	require.NoError(t, m.Up())
+	require.NoError(t, m.Close())
+
+	db, err := pgxpool.New(context.Background(), dsn)
+	require.NoError(t, err)
+	t.Cleanup(db.Close)
+
+	storetest.Run(t, func(t *testing.T) model.Store {
+		_, err := db.Exec(context.Background(), `truncate chat_messages, rooms, sessions, users, tenants`)
+		require.NoError(t, err)
+
+		return NewRepos(db)
+	})
+}
@@ -0,0 +1,39 @@
+package postgresql
+
+import (
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+// SQLSTATE, которые переводятся в доменные ошибки.
+const (
+	foreignKeyViolation = "23503"
+	uniqueViolation     = "23505"
+)
+
+// wrapErr оборачивает ошибку pgx, переводя известные случаи в доменные ошибки model.
+func wrapErr(err error, msg string) error {
+	var (
+		pgErr   *pgconn.PgError
+		connErr *pgconn.ConnectError
+	)
+
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return errors.Wrap(model.ErrNotFound, msg)
+	case errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation:
+		return errors.Wrap(model.ErrNotFound, msg)
+	case errors.As(err, &pgErr) && pgErr.Code == uniqueViolation:
+		return errors.Wrap(model.ErrConflict, msg)
+	case errors.As(err, &connErr) || pgconn.Timeout(err):
+		// Исходную ошибку сохраняем для логов
+		return fmt.Errorf("%s: %w: %w", msg, model.ErrUnavailable, err)
+	}
+
+	return errors.Wrap(err, msg)
+}
@@ -0,0 +1,51 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+func TestWrapErr(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "no_rows", err: pgx.ErrNoRows, want: model.ErrNotFound},
+		{name: "unique_violation", err: &pgconn.PgError{Code: uniqueViolation}, want: model.ErrConflict},
+		{name: "foreign_key_violation", err: &pgconn.PgError{Code: foreignKeyViolation}, want: model.ErrNotFound},
+		{name: "connect_error", err: &pgconn.ConnectError{}, want: model.ErrUnavailable},
+		{name: "other", err: assert.AnError, want: assert.AnError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := wrapErr(tt.err, "op in pg")
+			assert.ErrorIs(t, err, tt.want)
+			assert.Contains(t, err.Error(), "op in pg")
+		})
+	}
+
+	// Исходная ошибка подключения сохраняется для логов
+	connErr := &pgconn.ConnectError{}
+	assert.ErrorIs(t, wrapErr(connErr, "op in pg"), connErr)
+}
+
+func TestStorePG_CreateRoomById_Conflict(t *testing.T) {
+	m, err := newMocker()
+	assert.NoError(t, err)
+
+	r := m.storePG()
+	m.conn.ExpectExec(`insert into rooms`).
+		WithArgs(pgxmock.AnyArg()).
+		WillReturnError(&pgconn.PgError{Code: uniqueViolation})
+
+	err = r.CreateRoomById(t.Context(), "id", model.RoomMeta{}, nil)
+	assert.ErrorIs(t, err, model.ErrConflict)
+}
@@ -0,0 +1,197 @@
+package postgresql
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+func (s *StorePG) TouchRoom(ctx context.Context, id string) error {
+	args := pgx.NamedArgs{"id": id}
+
+	_, err := s.db.Exec(ctx,
+		`update rooms set last_active_at = now() where id = @id`+tenantScope(ctx, "tenant_id", args),
+		args,
+	)
+	if err != nil {
+		return wrapErr(err, "touch room in pg")
+	}
+
+	return nil
+}
+
+func (s *StorePG) ExpiringRooms(ctx context.Context, before time.Time, limit int) ([]model.RoomInfo, error) {
+	args := pgx.NamedArgs{"before": before, "limit": limit}
+
+	rows, err := s.db.Query(ctx,
+		`select id, expires_at from rooms
+		 where deleted_at is null and archived_at is null and expires_at <= @before`+tenantScope(ctx, "tenant_id", args)+`
+		 order by expires_at
+		 limit @limit`,
+		args,
+	)
+	if err != nil {
+		return nil, wrapErr(err, "select expiring rooms in pg")
+	}
+	defer rows.Close()
+
+	res := make([]model.RoomInfo, 0, limit)
+	for rows.Next() {
+		var r model.RoomInfo
+		if err = rows.Scan(&r.ID, &r.ExpiresAt); err != nil {
+			return nil, errors.Wrap(err, "scan expiring room")
+		}
+		res = append(res, r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "expiring rooms rows")
+	}
+
+	return res, nil
+}
+
+func (s *StorePG) IdleRooms(ctx context.Context, before time.Time, exclude []string, limit int) ([]string, error) {
+	// nil кодируется как NULL, и "not (id = any(NULL))" отсекло бы все комнаты
+	if exclude == nil {
+		exclude = []string{}
+	}
+
+	args := pgx.NamedArgs{"before": before, "exclude": exclude, "limit": limit}
+
+	rows, err := s.db.Query(ctx,
+		`select id from rooms
+		 where deleted_at is null and archived_at is null and last_active_at < @before
+		   and not (id = any(@exclude::text[]::uuid[]))`+tenantScope(ctx, "tenant_id", args)+`
+		 order by last_active_at
+		 limit @limit`,
+		args,
+	)
+	if err != nil {
+		return nil, wrapErr(err, "select idle rooms in pg")
+	}
+	defer rows.Close()
+
+	res := make([]string, 0, limit)
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			return nil, errors.Wrap(err, "scan idle room")
+		}
+		res = append(res, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "idle rooms rows")
+	}
+
+	return res, nil
+}
+
+func (s *StorePG) ArchiveRooms(ctx context.Context, ids []string) (int64, error) {
+	args := pgx.NamedArgs{"ids": ids}
+
+	exec, err := s.db.Exec(ctx,
+		`update rooms set archived_at = now()
+		 where id = any(@ids::text[]::uuid[]) and archived_at is null and deleted_at is null`+
+			tenantScope(ctx, "tenant_id", args),
+		args,
+	)
+	if err != nil {
+		return 0, wrapErr(err, "archive rooms in pg")
+	}
+
+	return exec.RowsAffected(), nil
+}
+
+// PurgeRooms окончательно удаляет комнаты вместе с историей чата
+// в одной транзакции.
+func (s *StorePG) PurgeRooms(ctx context.Context, ids []string) (int64, error) {
+	args := pgx.NamedArgs{"ids": ids}
+	// Чат удаляется только у комнат, видимых арендатору запроса
+	inScope := `(select id from rooms where id = any(@ids::text[]::uuid[])` + tenantScope(ctx, "tenant_id", args) + `)`
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return 0, wrapErr(err, "begin purge rooms in pg")
+	}
+	// После Commit откат ничего не делает
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	_, err = tx.Exec(ctx, `delete from chat_messages where room_id = any`+inScope, args)
+	if err != nil {
+		return 0, wrapErr(err, "purge chat messages in pg")
+	}
+
+	exec, err := tx.Exec(ctx, `delete from rooms where id = any`+inScope, args)
+	if err != nil {
+		return 0, wrapErr(err, "purge rooms in pg")
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, wrapErr(err, "commit purge rooms in pg")
+	}
+
+	return exec.RowsAffected(), nil
+}
+
+// RestoreRoomById возвращает в строй архивированную, истёкшую или мягко удалённую
+// комнату. Удалённую комнату можно восстановить, только если она удалена
+// не раньше deletedAfter. Прошедший expires_at при восстановлении сбрасывается.
+func (s *StorePG) RestoreRoomById(ctx context.Context, id string, deletedAfter time.Time) error {
+	args := pgx.NamedArgs{"id": id, "deleted_after": deletedAfter}
+
+	exec, err := s.db.Exec(ctx,
+		`update rooms
+		 set deleted_at = null, archived_at = null, last_active_at = now(),
+		     expires_at = case when expires_at <= now() then null else expires_at end
+		 where id = @id and not (`+roomAlive+`)
+		   and (deleted_at is null or deleted_at >= @deleted_after)`+tenantScope(ctx, "tenant_id", args),
+		args,
+	)
+	if err != nil {
+		return wrapErr(err, "restore room in pg")
+	}
+
+	if exec.RowsAffected() == 0 {
+		return errors.Wrap(model.ErrNotFound, "room not found or restore window has passed")
+	}
+
+	return nil
+}
+
+// DeletedRooms возвращает мягко удалённые раньше before комнаты.
+func (s *StorePG) DeletedRooms(ctx context.Context, before time.Time, limit int) ([]string, error) {
+	args := pgx.NamedArgs{"before": before, "limit": limit}
+
+	rows, err := s.db.Query(ctx,
+		`select id from rooms
+		 where deleted_at < @before`+tenantScope(ctx, "tenant_id", args)+`
+		 order by deleted_at
+		 limit @limit`,
+		args,
+	)
+	if err != nil {
+		return nil, wrapErr(err, "select deleted rooms in pg")
+	}
+	defer rows.Close()
+
+	res := make([]string, 0, limit)
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			return nil, errors.Wrap(err, "scan deleted room")
+		}
+		res = append(res, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "deleted rooms rows")
+	}
+
+	return res, nil
+}
@@ -0,0 +1,226 @@
+package postgresql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+func TestStorePG_TouchRoom(t *testing.T) {
+	ctx := context.Background()
+	id := uuid.NewString()
+
+	m, err := newMocker()
+	assert.NoError(t, err)
+
+	r := m.storePG()
+	m.conn.ExpectExec(`update rooms set last_active_at = now\(\) where id = @id`).
+		WithArgs(pgx.NamedArgs{"id": id}).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	assert.NoError(t, r.TouchRoom(ctx, id))
+	assert.NoError(t, m.conn.ExpectationsWereMet())
+}
+
+func TestStorePG_ExpiringRooms(t *testing.T) {
+	ctx := context.Background()
+	before := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	expiresAt := before.Add(-time.Minute)
+	id := uuid.NewString()
+	args := pgx.NamedArgs{"before": before, "limit": 10}
+
+	type testRow struct {
+		name    string
+		setup   func(m *mocker)
+		want    []model.RoomInfo
+		wantErr assert.ErrorAssertionFunc
+	}
+
+	tests := []testRow{
+		{
+			name: "success",
+			setup: func(m *mocker) {
+				m.conn.ExpectQuery(`select id, expires_at from rooms`).
+					WithArgs(args).
+					WillReturnRows(pgxmock.NewRows([]string{"id", "expires_at"}).AddRow(id, &expiresAt))
+			},
+			want:    []model.RoomInfo{{ID: id, RoomMeta: model.RoomMeta{ExpiresAt: &expiresAt}}},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "pg_error",
+			setup: func(m *mocker) {
+				m.conn.ExpectQuery(`select id, expires_at from rooms`).
+					WithArgs(args).
+					WillReturnError(assert.AnError)
+			},
+			wantErr: assert.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := newMocker()
+			assert.NoError(t, err)
+
+			r := m.storePG()
+			tt.setup(m)
+
+			got, err := r.ExpiringRooms(ctx, before, 10)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, m.conn.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStorePG_IdleRooms(t *testing.T) {
+	ctx := context.Background()
+	before := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	exclude := []string{uuid.NewString()}
+	id := uuid.NewString()
+
+	m, err := newMocker()
+	assert.NoError(t, err)
+
+	r := m.storePG()
+	m.conn.ExpectQuery(`select id from rooms`).
+		WithArgs(pgx.NamedArgs{"before": before, "exclude": exclude, "limit": 5}).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(id))
+
+	got, err := r.IdleRooms(ctx, before, exclude, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{id}, got)
+	assert.NoError(t, m.conn.ExpectationsWereMet())
+}
+
+func TestStorePG_RetireRooms(t *testing.T) {
+	ctx := context.Background()
+	ids := []string{uuid.NewString(), uuid.NewString()}
+	args := pgx.NamedArgs{"ids": ids}
+
+	t.Run("archive", func(t *testing.T) {
+		m, err := newMocker()
+		assert.NoError(t, err)
+
+		r := m.storePG()
+		m.conn.ExpectExec(`update rooms set archived_at = now\(\)`).
+			WithArgs(args).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+
+		n, err := r.ArchiveRooms(ctx, ids)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), n)
+		assert.NoError(t, m.conn.ExpectationsWereMet())
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		m, err := newMocker()
+		assert.NoError(t, err)
+
+		r := m.storePG()
+		m.conn.ExpectBegin()
+		m.conn.ExpectExec(`delete from chat_messages where room_id = any`).
+			WithArgs(args).
+			WillReturnResult(pgxmock.NewResult("DELETE", 5))
+		m.conn.ExpectExec(`delete from rooms where id = any`).
+			WithArgs(args).
+			WillReturnResult(pgxmock.NewResult("DELETE", 2))
+		m.conn.ExpectCommit()
+
+		n, err := r.PurgeRooms(ctx, ids)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), n)
+		assert.NoError(t, m.conn.ExpectationsWereMet())
+	})
+
+	t.Run("purge_rollback", func(t *testing.T) {
+		m, err := newMocker()
+		assert.NoError(t, err)
+
+		r := m.storePG()
+		m.conn.ExpectBegin()
+		m.conn.ExpectExec(`delete from chat_messages where room_id = any`).
+			WithArgs(args).
+			WillReturnResult(pgxmock.NewResult("DELETE", 5))
+		m.conn.ExpectExec(`delete from rooms where id = any`).
+			WithArgs(args).
+			WillReturnError(assert.AnError)
+		m.conn.ExpectRollback()
+
+		_, err = r.PurgeRooms(ctx, ids)
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, m.conn.ExpectationsWereMet())
+	})
+
+	t.Run("pg_error", func(t *testing.T) {
+		m, err := newMocker()
+		assert.NoError(t, err)
+
+		r := m.storePG()
+		m.conn.ExpectExec(`update rooms set archived_at = now\(\)`).
+			WithArgs(args).
+			WillReturnError(assert.AnError)
+
+		_, err = r.ArchiveRooms(ctx, ids)
+		assert.Error(t, err)
+	})
+}
+
+func TestStorePG_RestoreRoomById(t *testing.T) {
+	ctx := context.Background()
+	id := uuid.NewString()
+	deletedAfter := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	args := pgx.NamedArgs{"id": id, "deleted_after": deletedAfter}
+
+	t.Run("success", func(t *testing.T) {
+		m, err := newMocker()
+		assert.NoError(t, err)
+
+		r := m.storePG()
+		m.conn.ExpectExec(`update rooms\s+set deleted_at = null, archived_at = null`).
+			WithArgs(args).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		assert.NoError(t, r.RestoreRoomById(ctx, id, deletedAfter))
+		assert.NoError(t, m.conn.ExpectationsWereMet())
+	})
+
+	t.Run("not_found", func(t *testing.T) {
+		m, err := newMocker()
+		assert.NoError(t, err)
+
+		r := m.storePG()
+		m.conn.ExpectExec(`update rooms\s+set deleted_at = null, archived_at = null`).
+			WithArgs(args).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+		assert.ErrorIs(t, r.RestoreRoomById(ctx, id, deletedAfter), model.ErrNotFound)
+	})
+}
+
+func TestStorePG_DeletedRooms(t *testing.T) {
+	ctx := context.Background()
+	before := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	id := uuid.NewString()
+
+	m, err := newMocker()
+	assert.NoError(t, err)
+
+	r := m.storePG()
+	m.conn.ExpectQuery(`select id from rooms\s+where deleted_at < @before`).
+		WithArgs(pgx.NamedArgs{"before": before, "limit": 5}).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(id))
+
+	got, err := r.DeletedRooms(ctx, before, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{id}, got)
+	assert.NoError(t, m.conn.ExpectationsWereMet())
+}
@@ -0,0 +1,145 @@
+package postgresql
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/vpbuyanov/syncplay/internal/metrics"
+)
+
+var queryDuration = promauto.With(metrics.Registry).NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: metrics.Namespace,
+	Subsystem: "db",
+	Name:      "query_duration_seconds",
+	Help:      "Postgres query latency by statement kind.",
+	Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+}, []string{"op"})
+
+// instrumented засекает время запросов к базе, включая запросы в транзакциях.
+type instrumented struct {
+	repository
+}
+
+func (r instrumented) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	defer observeQuery(sql, time.Now())
+	return r.repository.Exec(ctx, sql, args...)
+}
+
+func (r instrumented) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	defer observeQuery(sql, time.Now())
+	return r.repository.Query(ctx, sql, args...)
+}
+
+func (r instrumented) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	defer observeQuery(sql, time.Now())
+	return r.repository.QueryRow(ctx, sql, args...)
+}
+
+func (r instrumented) Begin(ctx context.Context) (pgx.Tx, error) {
+	tx, err := r.repository.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return instrumentedTx{tx}, nil
+}
+
+type instrumentedTx struct {
+	pgx.Tx
+}
+
+func (tx instrumentedTx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	defer observeQuery(sql, time.Now())
+	return tx.Tx.Exec(ctx, sql, args...)
+}
+
+func (tx instrumentedTx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	defer observeQuery(sql, time.Now())
+	return tx.Tx.Query(ctx, sql, args...)
+}
+
+func (tx instrumentedTx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	defer observeQuery(sql, time.Now())
+	return tx.Tx.QueryRow(ctx, sql, args...)
+}
+
+func observeQuery(sql string, start time.Time) {
+	queryDuration.WithLabelValues(queryOp(sql)).Observe(time.Since(start).Seconds())
+}
+
+// queryOp — вид запроса по первому слову, чтобы не плодить метки.
+func queryOp(sql string) string {
+	word, _, _ := strings.Cut(strings.TrimSpace(sql), " ")
+	word = strings.ToLower(strings.TrimSpace(word))
+
+	switch word {
+	case "select", "insert", "update", "delete", "with":
+		return word
+	}
+
+	return "other"
+}
+
+// PoolCollector отдаёт статистику пула соединений pgx.
+type PoolCollector struct {
+	pool  *pgxpool.Pool
+	stats []poolStat
+}
+
+type poolStat struct {
+	desc  *prometheus.Desc
+	kind  prometheus.ValueType
+	value func(s *pgxpool.Stat) float64
+}
+
+func NewPoolCollector(pool *pgxpool.Pool) *PoolCollector {
+	stat := func(name, help string, kind prometheus.ValueType, value func(s *pgxpool.Stat) float64) poolStat {
+		return poolStat{
+			desc:  prometheus.NewDesc(metrics.Namespace+"_pgxpool_"+name, help, nil, nil),
+			kind:  kind,
+			value: value,
+		}
+	}
+
+	return &PoolCollector{
+		pool: pool,
+		stats: []poolStat{
+			stat("acquired_conns", "Connections currently in use.", prometheus.GaugeValue,
+				func(s *pgxpool.Stat) float64 { return float64(s.AcquiredConns()) }),
+			stat("idle_conns", "Idle connections.", prometheus.GaugeValue,
+				func(s *pgxpool.Stat) float64 { return float64(s.IdleConns()) }),
+			stat("total_conns", "All open connections.", prometheus.GaugeValue,
+				func(s *pgxpool.Stat) float64 { return float64(s.TotalConns()) }),
+			stat("max_conns", "Maximum size of the pool.", prometheus.GaugeValue,
+				func(s *pgxpool.Stat) float64 { return float64(s.MaxConns()) }),
+			stat("acquire_total", "Successful connection acquires.", prometheus.CounterValue,
+				func(s *pgxpool.Stat) float64 { return float64(s.AcquireCount()) }),
+			stat("empty_acquire_total", "Acquires that had to wait for a connection.", prometheus.CounterValue,
+				func(s *pgxpool.Stat) float64 { return float64(s.EmptyAcquireCount()) }),
+			stat("canceled_acquire_total", "Acquires canceled by context.", prometheus.CounterValue,
+				func(s *pgxpool.Stat) float64 { return float64(s.CanceledAcquireCount()) }),
+			stat("acquire_duration_seconds_total", "Total time spent acquiring connections.", prometheus.CounterValue,
+				func(s *pgxpool.Stat) float64 { return s.AcquireDuration().Seconds() }),
+		},
+	}
+}
+
+func (c *PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, s := range c.stats {
+		ch <- s.desc
+	}
+}
+
+func (c *PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+	for _, s := range c.stats {
+		ch <- prometheus.MustNewConstMetric(s.desc, s.kind, s.value(stat))
+	}
+}
@@ -0,0 +1,58 @@
+package postgresql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstrumented(t *testing.T) {
+	queryDuration.Reset()
+
+	m, err := newMocker()
+	require.NoError(t, err)
+
+	r := &StorePG{instrumented{m.conn}}
+
+	m.conn.ExpectExec(`update rooms`).WithArgs(pgxmock.AnyArg()).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	require.NoError(t, r.TouchRoom(context.Background(), uuid.NewString()))
+
+	// Запросы в транзакции тоже учитываются
+	m.conn.ExpectBegin()
+	m.conn.ExpectExec(`delete from chat_messages`).WithArgs(pgxmock.AnyArg()).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	m.conn.ExpectExec(`delete from rooms`).WithArgs(pgxmock.AnyArg()).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	m.conn.ExpectCommit()
+	_, err = r.PurgeRooms(context.Background(), []string{uuid.NewString()})
+	require.NoError(t, err)
+
+	assert.NoError(t, m.conn.ExpectationsWereMet())
+	assert.Equal(t, 2, testutil.CollectAndCount(queryDuration))
+}
+
+func TestQueryOp(t *testing.T) {
+	tests := map[string]string{
+		"select 1":                             "select",
+		"\n\t\tINSERT into rooms (id)":         "insert",
+		"with t as (select 1) select * from t": "with",
+		"vacuum":                               "other",
+	}
+
+	for sql, want := range tests {
+		assert.Equal(t, want, queryOp(sql), sql)
+	}
+}
+
+func TestPoolCollector(t *testing.T) {
+	// Пул подключается лениво, статистика доступна без базы
+	pool, err := pgxpool.New(context.Background(), "postgres://user@127.0.0.1:1/db")
+	require.NoError(t, err)
+	defer pool.Close()
+
+	assert.Equal(t, 8, testutil.CollectAndCount(NewPoolCollector(pool)))
+}
@@ -2,36 +2,116 @@ package postgresql
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/pkg/errors"
+
+	"github.com/vpbuyanov/syncplay/internal/model"
 )
 
-type repository interface {
+// execer выполняет запрос и в пуле, и в транзакции.
+type execer interface {
 	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+type repository interface {
+	execer
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
 	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Ping(ctx context.Context) error
 }
 
+// roomAlive — условие "комната не удалена, не архивирована и не истекла".
+const roomAlive = `deleted_at is null and archived_at is null and (expires_at is null or expires_at > now())`
+
 type StorePG struct {
 	db repository
 }
 
 func NewRepos(db *pgxpool.Pool) *StorePG {
 	return &StorePG{
-		db,
+		instrumented{db},
 	}
 }
 
-func (s *StorePG) CreateRoomById(ctx context.Context, id string) error {
+// Ping проверяет соединение с базой через пул.
+func (s *StorePG) Ping(ctx context.Context) error {
+	if err := s.db.Ping(ctx); err != nil {
+		return wrapErr(err, "ping postgres")
+	}
+
+	return nil
+}
+
+func (s *StorePG) CreateRoomById(ctx context.Context, id string, meta model.RoomMeta, ownerHash []byte) error {
 	args := pgx.NamedArgs{
-		"id": id,
+		"id":               id,
+		"title":            meta.Title,
+		"description":      meta.Description,
+		"visibility":       meta.Visibility,
+		"tags":             meta.Tags,
+		"owner_token_hash": ownerHash,
+		"expires_at":       meta.ExpiresAt,
+		"created_by":       nullString(meta.CreatedBy),
+		"tenant_id":        nil,
 	}
+	t, ok := model.TenantFrom(ctx)
+	if ok {
+		args["tenant_id"] = nullString(t.ID)
+	}
+
+	if !ok || t.ID == "" || t.Quotas.MaxRooms == 0 {
+		return insertRoom(ctx, s.db, args)
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return wrapErr(err, "begin insert room in pg")
+	}
+	// После Commit откат ничего не делает
+	defer func() { _ = tx.Rollback(ctx) }()
 
-	exec, err := s.db.Exec(ctx, `insert into rooms (id) values (@id)`, args)
+	// Блокировка строки арендатора выстраивает его вставки в очередь,
+	// поэтому параллельные запросы не превысят квоту
+	_, err = tx.Exec(ctx, `select from tenants where id = @tenant_id for update`, args)
 	if err != nil {
-		return errors.Wrap(err, "insert room in pg")
+		return wrapErr(err, "lock tenant in pg")
+	}
+
+	var n int
+	err = tx.QueryRow(ctx, `select count(*) from rooms where `+roomAlive+` and tenant_id = @tenant_id`, args).Scan(&n)
+	if err != nil {
+		return wrapErr(err, "count tenant rooms in pg")
+	}
+	if n >= t.Quotas.MaxRooms {
+		return errors.Wrapf(model.ErrQuotaExceeded, "tenant room limit of %d reached", t.Quotas.MaxRooms)
+	}
+
+	if err = insertRoom(ctx, tx, args); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return wrapErr(err, "commit insert room in pg")
+	}
+
+	return nil
+}
+
+func insertRoom(ctx context.Context, db execer, args pgx.NamedArgs) error {
+	exec, err := db.Exec(ctx,
+		`insert into rooms (id, title, description, visibility, tags, owner_token_hash, expires_at, created_by, tenant_id)
+		 values (@id, @title, @description, @visibility, @tags, @owner_token_hash, @expires_at, @created_by, @tenant_id)`,
+		args,
+	)
+	if err != nil {
+		return wrapErr(err, "insert room in pg")
 	}
 
 	if exec.RowsAffected() != 1 {
@@ -41,28 +121,35 @@ func (s *StorePG) CreateRoomById(ctx context.Context, id string) error {
 	return nil
 }
 
+// DeleteRoomById мягко удаляет комнату: она пропадает из выдачи,
+// но до окончательной очистки её можно восстановить.
 func (s *StorePG) DeleteRoomById(ctx context.Context, id string) error {
 	args := pgx.NamedArgs{
 		"id": id,
 	}
 
-	exec, err := s.db.Exec(ctx, "delete from rooms where id = (@id)", args)
+	exec, err := s.db.Exec(ctx,
+		"update rooms set deleted_at = now() where id = (@id) and deleted_at is null"+tenantScope(ctx, "tenant_id", args),
+		args,
+	)
 	if err != nil {
-		return errors.Wrap(err, "delete room in pg")
+		return wrapErr(err, "delete room in pg")
 	}
 
 	if exec.RowsAffected() == 0 {
-		return errors.New("no delete room")
+		return errors.Wrap(model.ErrNotFound, "room not found")
 	}
 
 	return nil
 }
 
 func (s *StorePG) RoomExists(ctx context.Context, id string) (bool, error) {
+	args := pgx.NamedArgs{"id": id}
+
 	var exists bool
 	err := s.db.QueryRow(ctx,
-		`select exists (select * from rooms where id = @id)`,
-		pgx.NamedArgs{"id": id},
+		`select exists (select * from rooms where id = @id and `+roomAlive+tenantScope(ctx, "tenant_id", args)+`)`,
+		args,
 	).Scan(&exists)
 	if err != nil {
 		return false, errors.Wrap(err, "check room exists")
@@ -70,3 +157,165 @@ func (s *StorePG) RoomExists(ctx context.Context, id string) (bool, error) {
 
 	return exists, nil
 }
+
+func (s *StorePG) RoomOwnerHash(ctx context.Context, id string) ([]byte, error) {
+	args := pgx.NamedArgs{"id": id}
+
+	var hash []byte
+	err := s.db.QueryRow(ctx,
+		`select owner_token_hash from rooms where id = @id`+tenantScope(ctx, "tenant_id", args),
+		args,
+	).Scan(&hash)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.Wrap(model.ErrNotFound, "room not found")
+	}
+	if err != nil {
+		return nil, wrapErr(err, "select room owner hash in pg")
+	}
+
+	return hash, nil
+}
+
+func (s *StorePG) RoomCreatedBy(ctx context.Context, id string) (string, error) {
+	args := pgx.NamedArgs{"id": id}
+
+	var createdBy string
+	err := s.db.QueryRow(ctx,
+		`select coalesce(created_by, '') from rooms where id = @id`+tenantScope(ctx, "tenant_id", args),
+		args,
+	).Scan(&createdBy)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", errors.Wrap(model.ErrNotFound, "room not found")
+	}
+	if err != nil {
+		return "", wrapErr(err, "select room creator in pg")
+	}
+
+	return createdBy, nil
+}
+
+func (s *StorePG) ListRooms(ctx context.Context, f model.RoomFilter) ([]model.RoomInfo, error) {
+	where := []string{roomAlive}
+	args := pgx.NamedArgs{
+		"limit": f.Limit,
+	}
+
+	if f.Visibility != "" {
+		where = append(where, "visibility = @visibility")
+		args["visibility"] = f.Visibility
+	}
+
+	if f.CreatedAfter != nil {
+		where = append(where, "created_at > @created_after")
+		args["created_after"] = *f.CreatedAfter
+	}
+
+	if f.CreatedBy != "" {
+		where = append(where, "created_by = @created_by")
+		args["created_by"] = f.CreatedBy
+	}
+
+	if f.Tag != "" {
+		where = append(where, "tags @> array[@tag::text]")
+		args["tag"] = f.Tag
+	}
+
+	if f.HasActivePeers != nil {
+		if *f.HasActivePeers {
+			where = append(where, "id = any(@active_ids)")
+		} else {
+			where = append(where, "not (id = any(@active_ids))")
+		}
+		args["active_ids"] = f.ActiveIDs
+		if f.ActiveIDs == nil {
+			// nil кодируется как NULL, а сравнение с NULL отсекло бы все комнаты
+			args["active_ids"] = []uuid.UUID{}
+		}
+	}
+
+	column, desc := sortColumn(f.Sort)
+	direction := "asc"
+	if desc {
+		direction = "desc"
+	}
+
+	if f.After != nil {
+		op := ">"
+		if desc {
+			op = "<"
+		}
+		where = append(where, fmt.Sprintf("(%s, id) %s (@after_key, @after_id)", column, op))
+		args["after_id"] = f.After.ID
+
+		if column == "title" {
+			args["after_key"] = f.After.Title
+		} else {
+			args["after_key"] = f.After.CreatedAt
+		}
+	}
+
+	query := `select id, title, description, visibility, tags, created_at, expires_at, coalesce(created_by, '') from rooms where ` +
+		strings.Join(where, " and ") + tenantScope(ctx, "tenant_id", args)
+	query += fmt.Sprintf(" order by %[1]s %[2]s, id %[2]s limit @limit", column, direction)
+
+	rows, err := s.db.Query(ctx, query, args)
+	if err != nil {
+		return nil, wrapErr(err, "list rooms in pg")
+	}
+	defer rows.Close()
+
+	res := make([]model.RoomInfo, 0, f.Limit)
+	for rows.Next() {
+		var r model.RoomInfo
+		if err = rows.Scan(&r.ID, &r.Title, &r.Description, &r.Visibility, &r.Tags, &r.CreatedAt, &r.ExpiresAt, &r.CreatedBy); err != nil {
+			return nil, errors.Wrap(err, "scan room")
+		}
+		res = append(res, r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "list rooms rows")
+	}
+
+	return res, nil
+}
+
+// tenantScope дописывает к запросу условие видимости комнат арендатору
+// запроса и добавляет его аргумент в args. Без арендатора в ctx запрос
+// не ограничивается.
+func tenantScope(ctx context.Context, column string, args pgx.NamedArgs) string {
+	t, ok := model.TenantFrom(ctx)
+	switch {
+	case !ok:
+		return ""
+	case t.ID == "":
+		return " and " + column + " is null"
+	}
+
+	args["tenant_id"] = t.ID
+
+	return " and " + column + " = @tenant_id"
+}
+
+// nullString возвращает значение для nullable-колонки: пустая строка — NULL.
+func nullString(s string) any {
+	if s == "" {
+		return nil
+	}
+
+	return s
+}
+
+// sortColumn возвращает колонку сортировки и признак убывания.
+func sortColumn(sort string) (string, bool) {
+	switch sort {
+	case model.SortTitleAsc:
+		return "title", false
+	case model.SortTitleDesc:
+		return "title", true
+	case model.SortCreatedAsc:
+		return "created_at", false
+	default:
+		return "created_at", true
+	}
+}
@@ -2,12 +2,17 @@ package postgresql
 
 import (
 	"context"
+	"errors"
+	"regexp"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/pashagolub/pgxmock/v2"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/vpbuyanov/syncplay/internal/model"
 )
 
 func TestStorePG_CreateRoomById(t *testing.T) {
@@ -16,9 +21,17 @@ func TestStorePG_CreateRoomById(t *testing.T) {
 	id, err := uuid.NewUUID()
 	assert.NoError(t, err)
 
+	meta := model.RoomMeta{
+		Title:      "Friday movie night",
+		Visibility: model.VisibilityPublic,
+		Tags:       []string{"movies"},
+	}
+
 	type testRow struct {
 		name    string
 		id      string
+		meta    model.RoomMeta
+		hash    []byte
 		setup   func(m *mocker, s *StorePG, t *testRow)
 		wantErr assert.ErrorAssertionFunc
 	}
@@ -27,12 +40,22 @@ func TestStorePG_CreateRoomById(t *testing.T) {
 		{
 			name: "success",
 			id:   id.String(),
+			meta: meta,
+			hash: []byte("hash"),
 			setup: func(m *mocker, s *StorePG, t *testRow) {
 				args := pgx.NamedArgs{
-					"id": t.id,
+					"id":               t.id,
+					"title":            t.meta.Title,
+					"description":      t.meta.Description,
+					"visibility":       t.meta.Visibility,
+					"tags":             t.meta.Tags,
+					"owner_token_hash": t.hash,
+					"expires_at":       t.meta.ExpiresAt,
+					"created_by":       nil,
+					"tenant_id":        nil,
 				}
 
-				m.conn.ExpectExec(`insert into rooms \(id\) values \(@id\)`).
+				m.conn.ExpectExec(`insert into rooms \(id, title, description, visibility, tags, owner_token_hash, expires_at, created_by, tenant_id\)`).
 					WithArgs(args).
 					WillReturnResult(pgxmock.NewResult("INSERT", 1)).
 					WillReturnError(nil)
@@ -42,12 +65,22 @@ func TestStorePG_CreateRoomById(t *testing.T) {
 		{
 			name: "pg_error_1",
 			id:   id.String(),
+			meta: meta,
+			hash: []byte("hash"),
 			setup: func(m *mocker, s *StorePG, t *testRow) {
 				args := pgx.NamedArgs{
-					"id": t.id,
+					"id":               t.id,
+					"title":            t.meta.Title,
+					"description":      t.meta.Description,
+					"visibility":       t.meta.Visibility,
+					"tags":             t.meta.Tags,
+					"owner_token_hash": t.hash,
+					"expires_at":       t.meta.ExpiresAt,
+					"created_by":       nil,
+					"tenant_id":        nil,
 				}
 
-				m.conn.ExpectExec(`insert into rooms \(id\) values \(@id\)`).
+				m.conn.ExpectExec(`insert into rooms \(id, title, description, visibility, tags, owner_token_hash, expires_at, created_by, tenant_id\)`).
 					WithArgs(args).
 					WillReturnError(assert.AnError)
 			},
@@ -56,12 +89,22 @@ func TestStorePG_CreateRoomById(t *testing.T) {
 		{
 			name: "no_insert",
 			id:   id.String(),
+			meta: meta,
+			hash: []byte("hash"),
 			setup: func(m *mocker, s *StorePG, t *testRow) {
 				args := pgx.NamedArgs{
-					"id": t.id,
+					"id":               t.id,
+					"title":            t.meta.Title,
+					"description":      t.meta.Description,
+					"visibility":       t.meta.Visibility,
+					"tags":             t.meta.Tags,
+					"owner_token_hash": t.hash,
+					"expires_at":       t.meta.ExpiresAt,
+					"created_by":       nil,
+					"tenant_id":        nil,
 				}
 
-				m.conn.ExpectExec(`insert into rooms \(id\) values \(@id\)`).
+				m.conn.ExpectExec(`insert into rooms \(id, title, description, visibility, tags, owner_token_hash, expires_at, created_by, tenant_id\)`).
 					WithArgs(args).
 					WillReturnResult(pgxmock.NewResult("INSERT", 0))
 			},
@@ -78,7 +121,7 @@ func TestStorePG_CreateRoomById(t *testing.T) {
 				tt.setup(m, r, &tt)
 			}
 
-			tt.wantErr(t, r.CreateRoomById(ctx, tt.id), "CreateRoomById() error")
+			tt.wantErr(t, r.CreateRoomById(ctx, tt.id, tt.meta, tt.hash), "CreateRoomById() error")
 		})
 	}
 }
@@ -104,9 +147,9 @@ func TestStorePG_DeleteRoomById(t *testing.T) {
 					"id": t.id,
 				}
 
-				m.conn.ExpectExec(`delete from rooms where id = \(@id\)`).
+				m.conn.ExpectExec(`update rooms set deleted_at = now\(\) where id = \(@id\) and deleted_at is null`).
 					WithArgs(args).
-					WillReturnResult(pgxmock.NewResult("DELETE", 1)).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1)).
 					WillReturnError(nil)
 			},
 			wantErr: assert.NoError,
@@ -119,7 +162,7 @@ func TestStorePG_DeleteRoomById(t *testing.T) {
 					"id": t.id,
 				}
 
-				m.conn.ExpectExec(`delete from rooms where id = \(@id\)`).
+				m.conn.ExpectExec(`update rooms set deleted_at = now\(\) where id = \(@id\) and deleted_at is null`).
 					WithArgs(args).
 					WillReturnError(assert.AnError)
 			},
@@ -133,11 +176,13 @@ func TestStorePG_DeleteRoomById(t *testing.T) {
 					"id": t.id,
 				}
 
-				m.conn.ExpectExec(`delete from rooms where id = \(@id\)`).
+				m.conn.ExpectExec(`update rooms set deleted_at = now\(\) where id = \(@id\) and deleted_at is null`).
 					WithArgs(args).
-					WillReturnResult(pgxmock.NewResult("DELETE", 0))
+					WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+			},
+			wantErr: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorIs(t, err, model.ErrNotFound)
 			},
-			wantErr: assert.Error,
 		},
 	}
 
@@ -178,7 +223,7 @@ func TestStorePG_RoomExists(t *testing.T) {
 				args := pgx.NamedArgs{"id": tr.id}
 				rows := pgxmock.NewRows([]string{"?column?"}).AddRow(true)
 
-				m.conn.ExpectQuery(`select exists \(select \* from rooms where id = @id\)`).
+				m.conn.ExpectQuery(`select exists \(select \* from rooms where id = @id and ` + regexp.QuoteMeta(roomAlive) + `\)`).
 					WithArgs(args).
 					WillReturnRows(rows)
 			},
@@ -192,7 +237,7 @@ func TestStorePG_RoomExists(t *testing.T) {
 				args := pgx.NamedArgs{"id": tr.id}
 				rows := pgxmock.NewRows([]string{"?column?"}).AddRow(false)
 
-				m.conn.ExpectQuery(`select exists \(select \* from rooms where id = @id\)`).
+				m.conn.ExpectQuery(`select exists \(select \* from rooms where id = @id and ` + regexp.QuoteMeta(roomAlive) + `\)`).
 					WithArgs(args).
 					WillReturnRows(rows)
 			},
@@ -205,7 +250,7 @@ func TestStorePG_RoomExists(t *testing.T) {
 			setup: func(m *mocker, s *StorePG, tr *testRow) {
 				args := pgx.NamedArgs{"id": tr.id}
 
-				m.conn.ExpectQuery(`select exists \(select \* from rooms where id = @id\)`).
+				m.conn.ExpectQuery(`select exists \(select \* from rooms where id = @id and ` + regexp.QuoteMeta(roomAlive) + `\)`).
 					WithArgs(args).
 					WillReturnError(assert.AnError)
 			},
@@ -230,3 +275,205 @@ func TestStorePG_RoomExists(t *testing.T) {
 		})
 	}
 }
+
+func TestStorePG_ListRooms(t *testing.T) {
+	ctx := context.Background()
+
+	id := uuid.New()
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	expiresAt := createdAt.Add(time.Hour)
+	active := true
+	columns := []string{"id", "title", "description", "visibility", "tags", "created_at", "expires_at", "created_by"}
+
+	type testRow struct {
+		name    string
+		filter  model.RoomFilter
+		setup   func(m *mocker, tr *testRow)
+		want    []model.RoomInfo
+		wantErr assert.ErrorAssertionFunc
+	}
+
+	tests := []testRow{
+		{
+			name:   "no_filters",
+			filter: model.RoomFilter{Sort: model.SortCreatedDesc, Limit: 21},
+			setup: func(m *mocker, tr *testRow) {
+				rows := pgxmock.NewRows(columns).
+					AddRow(id.String(), "title", "descr", "public", []string{"movies"}, createdAt, &expiresAt, "alice")
+
+				m.conn.ExpectQuery(regexp.QuoteMeta(
+					`select id, title, description, visibility, tags, created_at, expires_at, coalesce(created_by, '') from rooms` +
+						` where ` + roomAlive + ` order by created_at desc, id desc limit @limit`,
+				)).
+					WithArgs(pgx.NamedArgs{"limit": 21}).
+					WillReturnRows(rows)
+			},
+			want: []model.RoomInfo{{
+				ID: id.String(),
+				RoomMeta: model.RoomMeta{
+					Title:       "title",
+					Description: "descr",
+					Visibility:  "public",
+					Tags:        []string{"movies"},
+					ExpiresAt:   &expiresAt,
+					CreatedBy:   "alice",
+				},
+				CreatedAt: createdAt,
+			}},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "all_filters_with_cursor",
+			filter: model.RoomFilter{
+				Visibility:     model.VisibilityPublic,
+				CreatedAfter:   &createdAt,
+				CreatedBy:      "alice",
+				Tag:            "movies",
+				HasActivePeers: &active,
+				ActiveIDs:      []uuid.UUID{id},
+				Sort:           model.SortTitleAsc,
+				After:          &model.RoomInfo{ID: id.String(), RoomMeta: model.RoomMeta{Title: "abc"}},
+				Limit:          6,
+			},
+			setup: func(m *mocker, tr *testRow) {
+				m.conn.ExpectQuery(regexp.QuoteMeta(
+					`select id, title, description, visibility, tags, created_at, expires_at, coalesce(created_by, '') from rooms` +
+						` where ` + roomAlive + ` and visibility = @visibility and created_at > @created_after` +
+						` and created_by = @created_by and tags @> array[@tag::text] and id = any(@active_ids)` +
+						` and (title, id) > (@after_key, @after_id)` +
+						` order by title asc, id asc limit @limit`,
+				)).
+					WithArgs(pgx.NamedArgs{
+						"limit":         6,
+						"visibility":    model.VisibilityPublic,
+						"created_after": createdAt,
+						"created_by":    "alice",
+						"tag":           "movies",
+						"active_ids":    []uuid.UUID{id},
+						"after_key":     "abc",
+						"after_id":      id.String(),
+					}).
+					WillReturnRows(pgxmock.NewRows(columns))
+			},
+			want:    []model.RoomInfo{},
+			wantErr: assert.NoError,
+		},
+		{
+			name:   "pg_error",
+			filter: model.RoomFilter{Sort: model.SortCreatedAsc, Limit: 1},
+			setup: func(m *mocker, tr *testRow) {
+				m.conn.ExpectQuery(`select id, title, description, visibility, tags, created_at, expires_at, coalesce\(created_by, ''\) from rooms`).
+					WithArgs(pgx.NamedArgs{"limit": 1}).
+					WillReturnError(assert.AnError)
+			},
+			wantErr: assert.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := newMocker()
+			assert.NoError(t, err)
+
+			r := m.storePG()
+			if tt.setup != nil {
+				tt.setup(m, &tt)
+			}
+
+			got, err := r.ListRooms(ctx, tt.filter)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, m.conn.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStorePG_RoomOwnerHash(t *testing.T) {
+	ctx := context.Background()
+	id := uuid.NewString()
+	args := pgx.NamedArgs{"id": id}
+
+	t.Run("success", func(t *testing.T) {
+		m, err := newMocker()
+		assert.NoError(t, err)
+
+		m.conn.ExpectQuery(`select owner_token_hash from rooms where id = @id`).
+			WithArgs(args).
+			WillReturnRows(pgxmock.NewRows([]string{"owner_token_hash"}).AddRow([]byte("hash")))
+
+		hash, err := m.storePG().RoomOwnerHash(ctx, id)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("hash"), hash)
+	})
+
+	t.Run("not_found", func(t *testing.T) {
+		m, err := newMocker()
+		assert.NoError(t, err)
+
+		m.conn.ExpectQuery(`select owner_token_hash from rooms where id = @id`).
+			WithArgs(args).
+			WillReturnError(pgx.ErrNoRows)
+
+		_, err = m.storePG().RoomOwnerHash(ctx, id)
+		assert.ErrorIs(t, err, model.ErrNotFound)
+	})
+
+	t.Run("pg_error", func(t *testing.T) {
+		m, err := newMocker()
+		assert.NoError(t, err)
+
+		m.conn.ExpectQuery(`select owner_token_hash from rooms where id = @id`).
+			WithArgs(args).
+			WillReturnError(assert.AnError)
+
+		_, err = m.storePG().RoomOwnerHash(ctx, id)
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, model.ErrNotFound)
+	})
+}
+
+func TestStorePG_RoomCreatedBy(t *testing.T) {
+	ctx := context.Background()
+	id := uuid.NewString()
+	args := pgx.NamedArgs{"id": id}
+
+	t.Run("success", func(t *testing.T) {
+		m, err := newMocker()
+		assert.NoError(t, err)
+
+		m.conn.ExpectQuery(`select coalesce\(created_by, ''\) from rooms where id = @id`).
+			WithArgs(args).
+			WillReturnRows(pgxmock.NewRows([]string{"created_by"}).AddRow("alice"))
+
+		createdBy, err := m.storePG().RoomCreatedBy(ctx, id)
+		assert.NoError(t, err)
+		assert.Equal(t, "alice", createdBy)
+	})
+
+	t.Run("not_found", func(t *testing.T) {
+		m, err := newMocker()
+		assert.NoError(t, err)
+
+		m.conn.ExpectQuery(`select coalesce\(created_by, ''\) from rooms where id = @id`).
+			WithArgs(args).
+			WillReturnError(pgx.ErrNoRows)
+
+		_, err = m.storePG().RoomCreatedBy(ctx, id)
+		assert.ErrorIs(t, err, model.ErrNotFound)
+	})
+}
+
+func TestStorePG_Ping(t *testing.T) {
+	conn, err := pgxmock.NewPool(pgxmock.MonitorPingsOption(true))
+	assert.NoError(t, err)
+
+	r := &StorePG{conn}
+
+	conn.ExpectPing()
+	assert.NoError(t, r.Ping(context.Background()))
+
+	conn.ExpectPing().WillReturnError(errors.New("connection refused"))
+	assert.ErrorContains(t, r.Ping(context.Background()), "ping postgres: connection refused")
+
+	assert.NoError(t, conn.ExpectationsWereMet())
+}
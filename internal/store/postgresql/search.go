@@ -0,0 +1,95 @@
+package postgresql
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+// headlineOptions — настройки ts_headline; совпадения обрамляются
+// маркерами model.HighlightStart/HighlightStop.
+const headlineOptions = "StartSel=" + model.HighlightStart + ", StopSel=" + model.HighlightStop +
+	", MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=\" … \""
+
+func (s *StorePG) SearchRooms(ctx context.Context, query string, limit int) ([]model.SearchHit, error) {
+	args := pgx.NamedArgs{
+		"q":        query,
+		"headline": headlineOptions,
+		"limit":    limit,
+	}
+
+	rows, err := s.db.Query(ctx,
+		`select r.id, r.title,
+		        ts_headline('simple', r.title || ' ' || r.description, q, @headline),
+		        ts_rank(r.search_tsv, q) as rank,
+		        r.created_at
+		 from rooms r, websearch_to_tsquery('simple', @q) q
+		 where r.visibility = 'public' and r.search_tsv @@ q and `+roomAlive+tenantScope(ctx, "r.tenant_id", args)+`
+		 order by rank desc, r.created_at desc
+		 limit @limit`,
+		args,
+	)
+	if err != nil {
+		return nil, wrapErr(err, "search rooms in pg")
+	}
+	defer rows.Close()
+
+	res := make([]model.SearchHit, 0, limit)
+	for rows.Next() {
+		hit := model.SearchHit{Kind: model.SearchKindRoom}
+		if err = rows.Scan(&hit.RoomID, &hit.Title, &hit.Snippet, &hit.Rank, &hit.CreatedAt); err != nil {
+			return nil, errors.Wrap(err, "scan room hit")
+		}
+		res = append(res, hit)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "search rooms rows")
+	}
+
+	return res, nil
+}
+
+func (s *StorePG) SearchChat(ctx context.Context, roomID, query string, limit int) ([]model.SearchHit, error) {
+	args := pgx.NamedArgs{
+		"room_id":  roomID,
+		"q":        query,
+		"headline": headlineOptions,
+		"limit":    limit,
+	}
+
+	rows, err := s.db.Query(ctx,
+		`select m.id, m.room_id, coalesce(m.sender, ''),
+		        ts_headline('simple', coalesce(m.text, ''), q, @headline),
+		        ts_rank(m.text_tsv, q) as rank,
+		        m.created_at
+		 from chat_messages m, websearch_to_tsquery('simple', @q) q
+		 where m.room_id = @room_id and m.text_tsv @@ q
+		   and exists (select * from rooms where id = @room_id`+tenantScope(ctx, "tenant_id", args)+`)
+		 order by rank desc, m.created_at desc
+		 limit @limit`,
+		args,
+	)
+	if err != nil {
+		return nil, wrapErr(err, "search chat in pg")
+	}
+	defer rows.Close()
+
+	res := make([]model.SearchHit, 0, limit)
+	for rows.Next() {
+		hit := model.SearchHit{Kind: model.SearchKindMessage}
+		if err = rows.Scan(&hit.MessageID, &hit.RoomID, &hit.Sender, &hit.Snippet, &hit.Rank, &hit.CreatedAt); err != nil {
+			return nil, errors.Wrap(err, "scan message hit")
+		}
+		res = append(res, hit)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "search chat rows")
+	}
+
+	return res, nil
+}
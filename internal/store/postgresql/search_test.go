@@ -0,0 +1,97 @@
+package postgresql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+func TestStorePG_SearchRooms(t *testing.T) {
+	ctx := context.Background()
+
+	id := uuid.NewString()
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	args := pgx.NamedArgs{"q": "movie", "headline": headlineOptions, "limit": 10}
+
+	t.Run("success", func(t *testing.T) {
+		m, err := newMocker()
+		assert.NoError(t, err)
+
+		m.conn.ExpectQuery(`from rooms r, websearch_to_tsquery\('simple', @q\) q\s+where r.visibility = 'public'`).
+			WithArgs(args).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "title", "snippet", "rank", "created_at"}).
+				AddRow(id, "Movie night", "⟦Movie⟧ night", float32(0.5), createdAt))
+
+		got, err := m.storePG().SearchRooms(ctx, "movie", 10)
+		assert.NoError(t, err)
+		assert.Equal(t, []model.SearchHit{{
+			Kind:      model.SearchKindRoom,
+			RoomID:    id,
+			Title:     "Movie night",
+			Snippet:   "⟦Movie⟧ night",
+			Rank:      0.5,
+			CreatedAt: createdAt,
+		}}, got)
+	})
+
+	t.Run("pg_error", func(t *testing.T) {
+		m, err := newMocker()
+		assert.NoError(t, err)
+
+		m.conn.ExpectQuery(`from rooms r`).
+			WithArgs(args).
+			WillReturnError(assert.AnError)
+
+		_, err = m.storePG().SearchRooms(ctx, "movie", 10)
+		assert.Error(t, err)
+	})
+}
+
+func TestStorePG_SearchChat(t *testing.T) {
+	ctx := context.Background()
+
+	roomID := uuid.NewString()
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	args := pgx.NamedArgs{"room_id": roomID, "q": "hello", "headline": headlineOptions, "limit": 10}
+
+	t.Run("success", func(t *testing.T) {
+		m, err := newMocker()
+		assert.NoError(t, err)
+
+		m.conn.ExpectQuery(`from chat_messages m, websearch_to_tsquery\('simple', @q\) q\s+where m.room_id = @room_id`).
+			WithArgs(args).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "room_id", "sender", "snippet", "rank", "created_at"}).
+				AddRow(int64(3), roomID, "peer", "⟦hello⟧ all", float32(0.1), createdAt))
+
+		got, err := m.storePG().SearchChat(ctx, roomID, "hello", 10)
+		assert.NoError(t, err)
+		assert.Equal(t, []model.SearchHit{{
+			Kind:      model.SearchKindMessage,
+			RoomID:    roomID,
+			MessageID: 3,
+			Sender:    "peer",
+			Snippet:   "⟦hello⟧ all",
+			Rank:      0.1,
+			CreatedAt: createdAt,
+		}}, got)
+	})
+
+	t.Run("pg_error", func(t *testing.T) {
+		m, err := newMocker()
+		assert.NoError(t, err)
+
+		m.conn.ExpectQuery(`from chat_messages m`).
+			WithArgs(args).
+			WillReturnError(assert.AnError)
+
+		_, err = m.storePG().SearchChat(ctx, roomID, "hello", 10)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,149 @@
+package postgresql
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+const tenantColumns = `id, name, max_rooms, max_peers, messages_per_min, created_at`
+
+func (s *StorePG) CreateTenant(ctx context.Context, t model.Tenant, keyHash []byte) (model.Tenant, error) {
+	args := pgx.NamedArgs{
+		"id":               t.ID,
+		"name":             t.Name,
+		"api_key_hash":     keyHash,
+		"max_rooms":        t.Quotas.MaxRooms,
+		"max_peers":        t.Quotas.MaxPeers,
+		"messages_per_min": t.Quotas.MessagesPerMin,
+	}
+
+	err := s.db.QueryRow(ctx,
+		`insert into tenants (id, name, api_key_hash, max_rooms, max_peers, messages_per_min)
+		 values (@id, @name, @api_key_hash, @max_rooms, @max_peers, @messages_per_min)
+		 returning created_at`,
+		args,
+	).Scan(&t.CreatedAt)
+	if err != nil {
+		return model.Tenant{}, wrapErr(err, "insert tenant in pg")
+	}
+
+	return t, nil
+}
+
+func (s *StorePG) UpdateTenantQuotas(ctx context.Context, name string, q model.TenantQuotas) (model.Tenant, error) {
+	args := pgx.NamedArgs{
+		"name":             name,
+		"max_rooms":        q.MaxRooms,
+		"max_peers":        q.MaxPeers,
+		"messages_per_min": q.MessagesPerMin,
+	}
+
+	t, err := scanTenant(s.db.QueryRow(ctx,
+		`update tenants set max_rooms = @max_rooms, max_peers = @max_peers, messages_per_min = @messages_per_min
+		 where name = @name
+		 returning `+tenantColumns,
+		args,
+	))
+	if err != nil {
+		return model.Tenant{}, wrapErr(err, "update tenant in pg")
+	}
+
+	return t, nil
+}
+
+func (s *StorePG) ListTenants(ctx context.Context) ([]model.Tenant, error) {
+	rows, err := s.db.Query(ctx, `select `+tenantColumns+` from tenants order by name`)
+	if err != nil {
+		return nil, wrapErr(err, "list tenants in pg")
+	}
+	defer rows.Close()
+
+	res := make([]model.Tenant, 0)
+	for rows.Next() {
+		t, err := scanTenant(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "scan tenant")
+		}
+		res = append(res, t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "list tenants rows")
+	}
+
+	return res, nil
+}
+
+func (s *StorePG) TenantByKeyHash(ctx context.Context, keyHash []byte) (model.Tenant, error) {
+	t, err := scanTenant(s.db.QueryRow(ctx,
+		`select `+tenantColumns+` from tenants where api_key_hash = @api_key_hash`,
+		pgx.NamedArgs{"api_key_hash": keyHash},
+	))
+	if err != nil {
+		return model.Tenant{}, wrapErr(err, "select tenant in pg")
+	}
+
+	return t, nil
+}
+
+// CountRooms считает живые комнаты арендатора запроса.
+func (s *StorePG) CountRooms(ctx context.Context) (int, error) {
+	args := pgx.NamedArgs{}
+
+	var n int
+	err := s.db.QueryRow(ctx,
+		`select count(*) from rooms where `+roomAlive+tenantScope(ctx, "tenant_id", args),
+		args,
+	).Scan(&n)
+	if err != nil {
+		return 0, wrapErr(err, "count rooms in pg")
+	}
+
+	return n, nil
+}
+
+// CountRoomsByTenant считает живые комнаты по арендаторам; комнаты
+// арендатора по умолчанию лежат под пустым ключом.
+func (s *StorePG) CountRoomsByTenant(ctx context.Context) (map[string]int, error) {
+	args := pgx.NamedArgs{}
+
+	rows, err := s.db.Query(ctx,
+		`select coalesce(tenant_id::text, ''), count(*) from rooms
+		 where `+roomAlive+tenantScope(ctx, "tenant_id", args)+`
+		 group by tenant_id`,
+		args,
+	)
+	if err != nil {
+		return nil, wrapErr(err, "count rooms by tenant in pg")
+	}
+	defer rows.Close()
+
+	res := make(map[string]int)
+	for rows.Next() {
+		var (
+			id string
+			n  int
+		)
+		if err = rows.Scan(&id, &n); err != nil {
+			return nil, errors.Wrap(err, "scan room count")
+		}
+		res[id] = n
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "count rooms by tenant rows")
+	}
+
+	return res, nil
+}
+
+func scanTenant(row pgx.Row) (model.Tenant, error) {
+	var t model.Tenant
+	err := row.Scan(&t.ID, &t.Name, &t.Quotas.MaxRooms, &t.Quotas.MaxPeers, &t.Quotas.MessagesPerMin, &t.CreatedAt)
+
+	return t, err
+}
@@ -0,0 +1,130 @@
+package postgresql
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+func TestStorePG_CreateTenant(t *testing.T) {
+	m, err := newMocker()
+	require.NoError(t, err)
+
+	tenant := model.Tenant{ID: uuid.NewString(), Name: "acme", Quotas: model.TenantQuotas{MaxRooms: 10}}
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	m.conn.ExpectQuery(`insert into tenants \(id, name, api_key_hash, max_rooms, max_peers, messages_per_min\)`).
+		WithArgs(pgx.NamedArgs{
+			"id":               tenant.ID,
+			"name":             "acme",
+			"api_key_hash":     []byte("hash"),
+			"max_rooms":        10,
+			"max_peers":        0,
+			"messages_per_min": 0,
+		}).
+		WillReturnRows(pgxmock.NewRows([]string{"created_at"}).AddRow(createdAt))
+
+	got, err := m.storePG().CreateTenant(context.Background(), tenant, []byte("hash"))
+	require.NoError(t, err)
+
+	tenant.CreatedAt = createdAt
+	assert.Equal(t, tenant, got)
+	assert.NoError(t, m.conn.ExpectationsWereMet())
+}
+
+func TestStorePG_TenantScope(t *testing.T) {
+	id := uuid.NewString()
+	tenantID := uuid.NewString()
+
+	t.Run("tenant", func(t *testing.T) {
+		m, err := newMocker()
+		require.NoError(t, err)
+
+		m.conn.ExpectExec(`update rooms set deleted_at = now\(\) where id = \(@id\) and deleted_at is null and tenant_id = @tenant_id`).
+			WithArgs(pgx.NamedArgs{"id": id, "tenant_id": tenantID}).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		ctx := model.WithTenant(context.Background(), model.Tenant{ID: tenantID})
+		require.NoError(t, m.storePG().DeleteRoomById(ctx, id))
+		assert.NoError(t, m.conn.ExpectationsWereMet())
+	})
+
+	t.Run("default tenant", func(t *testing.T) {
+		m, err := newMocker()
+		require.NoError(t, err)
+
+		m.conn.ExpectExec(`update rooms set deleted_at = now\(\) where id = \(@id\) and deleted_at is null and tenant_id is null`).
+			WithArgs(pgx.NamedArgs{"id": id}).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		ctx := model.WithTenant(context.Background(), model.Tenant{})
+		require.NoError(t, m.storePG().DeleteRoomById(ctx, id))
+		assert.NoError(t, m.conn.ExpectationsWereMet())
+	})
+}
+
+func TestStorePG_CreateRoomByIdQuota(t *testing.T) {
+	id := uuid.NewString()
+	tenant := model.Tenant{ID: uuid.NewString(), Name: "acme", Quotas: model.TenantQuotas{MaxRooms: 2}}
+	ctx := model.WithTenant(context.Background(), tenant)
+	meta := model.RoomMeta{Title: "acme room", Visibility: model.VisibilityPublic}
+
+	args := pgx.NamedArgs{
+		"id":               id,
+		"title":            meta.Title,
+		"description":      meta.Description,
+		"visibility":       meta.Visibility,
+		"tags":             meta.Tags,
+		"owner_token_hash": []byte("hash"),
+		"expires_at":       meta.ExpiresAt,
+		"created_by":       nil,
+		"tenant_id":        tenant.ID,
+	}
+
+	t.Run("under quota", func(t *testing.T) {
+		m, err := newMocker()
+		require.NoError(t, err)
+
+		m.conn.ExpectBegin()
+		m.conn.ExpectExec(`select from tenants where id = @tenant_id for update`).
+			WithArgs(args).
+			WillReturnResult(pgxmock.NewResult("SELECT", 1))
+		m.conn.ExpectQuery(`select count\(\*\) from rooms where ` + regexp.QuoteMeta(roomAlive) + ` and tenant_id = @tenant_id`).
+			WithArgs(args).
+			WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(1))
+		m.conn.ExpectExec(`insert into rooms \(id, title, description, visibility, tags, owner_token_hash, expires_at, created_by, tenant_id\)`).
+			WithArgs(args).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		m.conn.ExpectCommit()
+
+		require.NoError(t, m.storePG().CreateRoomById(ctx, id, meta, []byte("hash")))
+		assert.NoError(t, m.conn.ExpectationsWereMet())
+	})
+
+	t.Run("quota exceeded", func(t *testing.T) {
+		m, err := newMocker()
+		require.NoError(t, err)
+
+		m.conn.ExpectBegin()
+		m.conn.ExpectExec(`select from tenants where id = @tenant_id for update`).
+			WithArgs(args).
+			WillReturnResult(pgxmock.NewResult("SELECT", 1))
+		m.conn.ExpectQuery(`select count\(\*\) from rooms where ` + regexp.QuoteMeta(roomAlive) + ` and tenant_id = @tenant_id`).
+			WithArgs(args).
+			WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(2))
+		m.conn.ExpectRollback()
+
+		err = m.storePG().CreateRoomById(ctx, id, meta, []byte("hash"))
+		require.ErrorIs(t, err, model.ErrQuotaExceeded)
+		assert.NoError(t, m.conn.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,37 @@
+package postgresql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracer(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	tr := &Tracer{tracer: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)).Tracer("test")}
+
+	ctx := tr.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "update rooms set x = 1"})
+	tr.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("UPDATE 3")})
+
+	ctx = tr.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "select 1"})
+	tr.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: errors.New("conn closed")})
+
+	ended := spans.Ended()
+	require.Len(t, ended, 2)
+
+	assert.Equal(t, "pg.update", ended[0].Name())
+	assert.Contains(t, ended[0].Attributes(), attribute.String("db.operation", "update"))
+	assert.Contains(t, ended[0].Attributes(), attribute.Int64("db.rows_affected", 3))
+
+	assert.Equal(t, "pg.select", ended[1].Name())
+	assert.Equal(t, codes.Error, ended[1].Status().Code)
+}
@@ -0,0 +1,112 @@
+package postgresql
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+func (s *StorePG) CreateUser(ctx context.Context, u model.User, passwordHash string) (model.User, error) {
+	args := pgx.NamedArgs{
+		"id":            u.ID,
+		"username":      u.Username,
+		"password_hash": passwordHash,
+	}
+
+	err := s.db.QueryRow(ctx,
+		`insert into users (id, username, password_hash) values (@id, @username, @password_hash)
+		 returning created_at`,
+		args,
+	).Scan(&u.CreatedAt)
+	if err != nil {
+		return model.User{}, wrapErr(err, "insert user in pg")
+	}
+
+	return u, nil
+}
+
+func (s *StorePG) UserByName(ctx context.Context, username string) (model.User, string, error) {
+	var (
+		u    model.User
+		hash string
+	)
+	err := s.db.QueryRow(ctx,
+		`select id, username, password_hash, created_at from users where username = @username`,
+		pgx.NamedArgs{"username": username},
+	).Scan(&u.ID, &u.Username, &hash, &u.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return model.User{}, "", errors.Wrap(model.ErrNotFound, "user not found")
+	}
+	if err != nil {
+		return model.User{}, "", wrapErr(err, "select user in pg")
+	}
+
+	return u, hash, nil
+}
+
+func (s *StorePG) CreateSession(ctx context.Context, tokenHash []byte, userID string, expiresAt time.Time) error {
+	args := pgx.NamedArgs{
+		"token_hash": tokenHash,
+		"user_id":    userID,
+		"expires_at": expiresAt,
+	}
+
+	_, err := s.db.Exec(ctx,
+		`insert into sessions (token_hash, user_id, expires_at) values (@token_hash, @user_id, @expires_at)`,
+		args,
+	)
+	if err != nil {
+		return wrapErr(err, "insert session in pg")
+	}
+
+	return nil
+}
+
+func (s *StorePG) SessionByHash(ctx context.Context, tokenHash []byte) (model.User, time.Time, error) {
+	var (
+		u         model.User
+		expiresAt time.Time
+	)
+	err := s.db.QueryRow(ctx,
+		`select u.id, u.username, u.created_at, s.expires_at
+		 from sessions s join users u on u.id = s.user_id
+		 where s.token_hash = @token_hash`,
+		pgx.NamedArgs{"token_hash": tokenHash},
+	).Scan(&u.ID, &u.Username, &u.CreatedAt, &expiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return model.User{}, time.Time{}, errors.Wrap(model.ErrNotFound, "session not found")
+	}
+	if err != nil {
+		return model.User{}, time.Time{}, wrapErr(err, "select session in pg")
+	}
+
+	return u, expiresAt, nil
+}
+
+func (s *StorePG) DeleteSession(ctx context.Context, tokenHash []byte) error {
+	_, err := s.db.Exec(ctx,
+		`delete from sessions where token_hash = @token_hash`,
+		pgx.NamedArgs{"token_hash": tokenHash},
+	)
+	if err != nil {
+		return wrapErr(err, "delete session in pg")
+	}
+
+	return nil
+}
+
+func (s *StorePG) DeleteExpiredSessions(ctx context.Context, userID string, before time.Time) error {
+	_, err := s.db.Exec(ctx,
+		`delete from sessions where user_id = @user_id and expires_at <= @before`,
+		pgx.NamedArgs{"user_id": userID, "before": before},
+	)
+	if err != nil {
+		return wrapErr(err, "delete expired sessions in pg")
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+func (s *StoreSQLite) SaveChatMessage(ctx context.Context, msg model.ChatMessage) (model.ChatMessage, error) {
+	scope, args := tenantScope(ctx, "tenant_id", []any{
+		sql.Named("room_id", msg.RoomID),
+		sql.Named("sender", msg.Sender),
+		sql.Named("text", msg.Text),
+		sql.Named("now", s.timestamp()),
+	})
+
+	// Комната чужого арендатора не найдётся, и вставка не вернёт строк
+	var createdAt string
+	err := s.db.QueryRowContext(ctx,
+		`insert into chat_messages (room_id, sender, text, created_at)
+		 select @room_id, @sender, @text, @now
+		 where exists (select * from rooms where id = @room_id`+scope+`)
+		 returning id, created_at`,
+		args...,
+	).Scan(&msg.ID, &createdAt)
+	if err != nil {
+		return model.ChatMessage{}, wrapErr(err, "insert chat message in sqlite")
+	}
+
+	if msg.CreatedAt, err = parseTime(createdAt); err != nil {
+		return model.ChatMessage{}, err
+	}
+
+	return msg, nil
+}
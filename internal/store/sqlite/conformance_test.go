@@ -0,0 +1,44 @@
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/vpbuyanov/syncplay/internal/config"
+	"github.com/vpbuyanov/syncplay/internal/migrator"
+	"github.com/vpbuyanov/syncplay/internal/model"
+	"github.com/vpbuyanov/syncplay/internal/store/storetest"
+)
+
+func TestStoreSQLite_Conformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) model.Store {
+		path := filepath.Join(t.TempDir(), "syncplay.db")
+
+		m, err := migrator.New(config.Storage{Driver: config.StorageSQLite, SQLite: config.SQLite{Path: path}}, config.Postgres{})
+		require.NoError(t, err)
+		require.NoError(t, m.Up())
+		require.NoError(t, m.Close())
+
+		db, err := Open(path)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+
+		return NewRepos(db)
+	})
+}
+
+func TestOpen_WAL(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "syncplay.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	var mode string
+	require.NoError(t, db.QueryRow(`pragma journal_mode`).Scan(&mode))
+	require.Equal(t, "wal", mode)
+
+	var fk bool
+	require.NoError(t, db.QueryRow(`pragma foreign_keys`).Scan(&fk))
+	require.True(t, fk)
+}
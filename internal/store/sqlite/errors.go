@@ -0,0 +1,36 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/pkg/errors"
+	sqlitedrv "modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+// wrapErr оборачивает ошибку SQLite, переводя известные случаи в доменные ошибки model.
+func wrapErr(err error, msg string) error {
+	var sqlErr *sqlitedrv.Error
+	if !errors.As(err, &sqlErr) {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.Wrap(model.ErrNotFound, msg)
+		}
+
+		return errors.Wrap(err, msg)
+	}
+
+	switch sqlErr.Code() {
+	case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+		return errors.Wrap(model.ErrNotFound, msg)
+	case sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY, sqlite3.SQLITE_CONSTRAINT_UNIQUE:
+		return errors.Wrap(model.ErrConflict, msg)
+	case sqlite3.SQLITE_BUSY, sqlite3.SQLITE_LOCKED, sqlite3.SQLITE_CANTOPEN:
+		// Исходную ошибку сохраняем для логов
+		return fmt.Errorf("%s: %w: %w", msg, model.ErrUnavailable, err)
+	}
+
+	return errors.Wrap(err, msg)
+}
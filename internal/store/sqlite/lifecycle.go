@@ -0,0 +1,219 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+func (s *StoreSQLite) TouchRoom(ctx context.Context, id string) error {
+	scope, args := tenantScope(ctx, "tenant_id", []any{
+		sql.Named("id", id),
+		sql.Named("now", s.timestamp()),
+	})
+
+	_, err := s.db.ExecContext(ctx,
+		`update rooms set last_active_at = @now where id = @id`+scope,
+		args...,
+	)
+	if err != nil {
+		return wrapErr(err, "touch room in sqlite")
+	}
+
+	return nil
+}
+
+func (s *StoreSQLite) ExpiringRooms(ctx context.Context, before time.Time, limit int) ([]model.RoomInfo, error) {
+	scope, args := tenantScope(ctx, "tenant_id", []any{
+		sql.Named("before", formatTime(before)),
+		sql.Named("limit", limit),
+	})
+
+	rows, err := s.db.QueryContext(ctx,
+		`select id, expires_at from rooms
+		 where deleted_at is null and archived_at is null and expires_at <= @before`+scope+`
+		 order by expires_at
+		 limit @limit`,
+		args...,
+	)
+	if err != nil {
+		return nil, wrapErr(err, "select expiring rooms in sqlite")
+	}
+	defer rows.Close()
+
+	res := make([]model.RoomInfo, 0, limit)
+	for rows.Next() {
+		var (
+			r         model.RoomInfo
+			expiresAt sql.NullString
+		)
+		if err = rows.Scan(&r.ID, &expiresAt); err != nil {
+			return nil, errors.Wrap(err, "scan expiring room")
+		}
+		if r.ExpiresAt, err = parseNullTime(expiresAt); err != nil {
+			return nil, err
+		}
+		res = append(res, r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "expiring rooms rows")
+	}
+
+	return res, nil
+}
+
+func (s *StoreSQLite) IdleRooms(ctx context.Context, before time.Time, exclude []string, limit int) ([]string, error) {
+	scope, args := tenantScope(ctx, "tenant_id", []any{
+		sql.Named("before", formatTime(before)),
+		sql.Named("exclude", jsonStrings(exclude)),
+		sql.Named("limit", limit),
+	})
+
+	rows, err := s.db.QueryContext(ctx,
+		`select id from rooms
+		 where deleted_at is null and archived_at is null and last_active_at < @before
+		   and id not in (select value from json_each(@exclude))`+scope+`
+		 order by last_active_at
+		 limit @limit`,
+		args...,
+	)
+	if err != nil {
+		return nil, wrapErr(err, "select idle rooms in sqlite")
+	}
+
+	return scanIDs(rows, limit, "idle rooms")
+}
+
+func (s *StoreSQLite) ArchiveRooms(ctx context.Context, ids []string) (int64, error) {
+	scope, args := tenantScope(ctx, "tenant_id", []any{
+		sql.Named("ids", jsonStrings(ids)),
+		sql.Named("now", s.timestamp()),
+	})
+
+	exec, err := s.db.ExecContext(ctx,
+		`update rooms set archived_at = @now
+		 where id in (select value from json_each(@ids)) and archived_at is null and deleted_at is null`+scope,
+		args...,
+	)
+	if err != nil {
+		return 0, wrapErr(err, "archive rooms in sqlite")
+	}
+
+	n, err := exec.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "archive rooms affected")
+	}
+
+	return n, nil
+}
+
+// PurgeRooms окончательно удаляет комнаты вместе с историей чата
+// в одной транзакции.
+func (s *StoreSQLite) PurgeRooms(ctx context.Context, ids []string) (int64, error) {
+	// Чат удаляется только у комнат, видимых арендатору запроса
+	scope, args := tenantScope(ctx, "tenant_id", []any{sql.Named("ids", jsonStrings(ids))})
+	inScope := `(select id from rooms where id in (select value from json_each(@ids))` + scope + `)`
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, wrapErr(err, "begin purge rooms in sqlite")
+	}
+	// После Commit откат ничего не делает
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.ExecContext(ctx, `delete from chat_messages where room_id in `+inScope, args...)
+	if err != nil {
+		return 0, wrapErr(err, "purge chat messages in sqlite")
+	}
+
+	exec, err := tx.ExecContext(ctx, `delete from rooms where id in `+inScope, args...)
+	if err != nil {
+		return 0, wrapErr(err, "purge rooms in sqlite")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, wrapErr(err, "commit purge rooms in sqlite")
+	}
+
+	n, err := exec.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "purge rooms affected")
+	}
+
+	return n, nil
+}
+
+// RestoreRoomById возвращает в строй архивированную, истёкшую или мягко удалённую
+// комнату. Удалённую комнату можно восстановить, только если она удалена
+// не раньше deletedAfter. Прошедший expires_at при восстановлении сбрасывается.
+func (s *StoreSQLite) RestoreRoomById(ctx context.Context, id string, deletedAfter time.Time) error {
+	scope, args := tenantScope(ctx, "tenant_id", []any{
+		sql.Named("id", id),
+		sql.Named("now", s.timestamp()),
+		sql.Named("deleted_after", formatTime(deletedAfter)),
+	})
+
+	exec, err := s.db.ExecContext(ctx,
+		`update rooms
+		 set deleted_at = null, archived_at = null, last_active_at = @now,
+		     expires_at = case when expires_at <= @now then null else expires_at end
+		 where id = @id and not (`+roomAlive+`)
+		   and (deleted_at is null or deleted_at >= @deleted_after)`+scope,
+		args...,
+	)
+	if err != nil {
+		return wrapErr(err, "restore room in sqlite")
+	}
+
+	if n, _ := exec.RowsAffected(); n == 0 {
+		return errors.Wrap(model.ErrNotFound, "room not found or restore window has passed")
+	}
+
+	return nil
+}
+
+// DeletedRooms возвращает мягко удалённые раньше before комнаты.
+func (s *StoreSQLite) DeletedRooms(ctx context.Context, before time.Time, limit int) ([]string, error) {
+	scope, args := tenantScope(ctx, "tenant_id", []any{
+		sql.Named("before", formatTime(before)),
+		sql.Named("limit", limit),
+	})
+
+	rows, err := s.db.QueryContext(ctx,
+		`select id from rooms
+		 where deleted_at < @before`+scope+`
+		 order by deleted_at
+		 limit @limit`,
+		args...,
+	)
+	if err != nil {
+		return nil, wrapErr(err, "select deleted rooms in sqlite")
+	}
+
+	return scanIDs(rows, limit, "deleted rooms")
+}
+
+// scanIDs читает и закрывает выборку идентификаторов комнат.
+func scanIDs(rows *sql.Rows, limit int, what string) ([]string, error) {
+	defer rows.Close()
+
+	res := make([]string, 0, limit)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.Wrap(err, "scan "+what)
+		}
+		res = append(res, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, what+" rows")
+	}
+
+	return res, nil
+}
@@ -0,0 +1,208 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+// snippetWords — длина фрагмента в словах, как MaxWords в ts_headline.
+const snippetWords = 30
+
+// Веса bm25 для колонок rooms_fts, как у ts_rank для частей A и B.
+const (
+	weightTitle       = 1.0
+	weightDescription = 0.4
+)
+
+func (s *StoreSQLite) SearchRooms(ctx context.Context, query string, limit int) ([]model.SearchHit, error) {
+	match := ftsQuery(query)
+	if match == "" {
+		return []model.SearchHit{}, nil
+	}
+
+	scope, args := tenantScope(ctx, "r.tenant_id", []any{
+		sql.Named("q", match),
+		sql.Named("start", model.HighlightStart),
+		sql.Named("stop", model.HighlightStop),
+		sql.Named("words", snippetWords),
+		sql.Named("weight_title", weightTitle),
+		sql.Named("weight_description", weightDescription),
+		sql.Named("now", s.timestamp()),
+		sql.Named("limit", limit),
+	})
+
+	rows, err := s.db.QueryContext(ctx,
+		`select r.id, r.title,
+		        snippet(rooms_fts, -1, @start, @stop, ' … ', @words),
+		        -bm25(rooms_fts, @weight_title, @weight_description) as rank,
+		        r.created_at
+		 from rooms_fts f join rooms r on r.seq = f.rowid
+		 where rooms_fts match @q and r.visibility = 'public' and `+roomAlive+scope+`
+		 order by rank desc, r.created_at desc
+		 limit @limit`,
+		args...,
+	)
+	if err != nil {
+		return nil, wrapErr(err, "search rooms in sqlite")
+	}
+	defer rows.Close()
+
+	res := make([]model.SearchHit, 0, limit)
+	for rows.Next() {
+		var (
+			hit       = model.SearchHit{Kind: model.SearchKindRoom}
+			createdAt string
+		)
+		if err = rows.Scan(&hit.RoomID, &hit.Title, &hit.Snippet, &hit.Rank, &createdAt); err != nil {
+			return nil, errors.Wrap(err, "scan room hit")
+		}
+		if hit.CreatedAt, err = parseTime(createdAt); err != nil {
+			return nil, err
+		}
+		res = append(res, hit)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "search rooms rows")
+	}
+
+	return res, nil
+}
+
+func (s *StoreSQLite) SearchChat(ctx context.Context, roomID, query string, limit int) ([]model.SearchHit, error) {
+	match := ftsQuery(query)
+	if match == "" {
+		return []model.SearchHit{}, nil
+	}
+
+	scope, args := tenantScope(ctx, "tenant_id", []any{
+		sql.Named("q", match),
+		sql.Named("room_id", roomID),
+		sql.Named("start", model.HighlightStart),
+		sql.Named("stop", model.HighlightStop),
+		sql.Named("words", snippetWords),
+		sql.Named("limit", limit),
+	})
+
+	rows, err := s.db.QueryContext(ctx,
+		`select m.id, m.room_id, coalesce(m.sender, ''),
+		        snippet(chat_messages_fts, 0, @start, @stop, ' … ', @words),
+		        -bm25(chat_messages_fts) as rank,
+		        m.created_at
+		 from chat_messages_fts f join chat_messages m on m.id = f.rowid
+		 where chat_messages_fts match @q and m.room_id = @room_id
+		   and exists (select * from rooms where id = @room_id`+scope+`)
+		 order by rank desc, m.created_at desc
+		 limit @limit`,
+		args...,
+	)
+	if err != nil {
+		return nil, wrapErr(err, "search chat in sqlite")
+	}
+	defer rows.Close()
+
+	res := make([]model.SearchHit, 0, limit)
+	for rows.Next() {
+		var (
+			hit       = model.SearchHit{Kind: model.SearchKindMessage}
+			createdAt string
+		)
+		if err = rows.Scan(&hit.MessageID, &hit.RoomID, &hit.Sender, &hit.Snippet, &hit.Rank, &createdAt); err != nil {
+			return nil, errors.Wrap(err, "scan message hit")
+		}
+		if hit.CreatedAt, err = parseTime(createdAt); err != nil {
+			return nil, err
+		}
+		res = append(res, hit)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "search chat rows")
+	}
+
+	return res, nil
+}
+
+// ftsQuery переводит запрос в синтаксисе websearch_to_tsquery в выражение FTS5:
+// слова и "фразы" через пробел — AND, "or" — OR, "-слово" — NOT.
+// Группа без положительных термов отбрасывается, потому что в FTS5 NOT бинарный.
+// Пустой результат означает, что запрос ничего не найдёт.
+func ftsQuery(q string) string {
+	var (
+		groups   []string
+		pos, neg []string
+	)
+
+	flush := func() {
+		if len(pos) > 0 {
+			g := strings.Join(pos, " AND ")
+			for _, n := range neg {
+				g += " NOT " + n
+			}
+			groups = append(groups, "("+g+")")
+		}
+		pos, neg = nil, nil
+	}
+
+	for _, tok := range splitQuery(q) {
+		if strings.EqualFold(tok, "or") {
+			flush()
+			continue
+		}
+
+		words := strings.FieldsFunc(strings.TrimPrefix(tok, "-"), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if len(words) == 0 {
+			continue
+		}
+
+		// Слова состоят только из букв и цифр, поэтому кавычки экранировать не нужно
+		phrase := `"` + strings.Join(words, " ") + `"`
+		if strings.HasPrefix(tok, "-") {
+			neg = append(neg, phrase)
+		} else {
+			pos = append(pos, phrase)
+		}
+	}
+	flush()
+
+	return strings.Join(groups, " OR ")
+}
+
+// splitQuery делит запрос по пробелам, сохраняя фразы в кавычках целиком.
+func splitQuery(q string) []string {
+	var (
+		res    []string
+		cur    strings.Builder
+		quoted bool
+	)
+
+	flush := func() {
+		if cur.Len() > 0 {
+			res = append(res, cur.String())
+			cur.Reset()
+		}
+	}
+
+	for _, r := range q {
+		switch {
+		case r == '"':
+			flush()
+			quoted = !quoted
+		case unicode.IsSpace(r) && !quoted:
+			flush()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	flush()
+
+	return res
+}
@@ -0,0 +1,31 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFtsQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"слово", "movie", `("movie")`},
+		{"несколько слов", "movie night", `("movie" AND "night")`},
+		{"фраза", `"movie night"`, `("movie night")`},
+		{"исключение", "movie -classic", `("movie" NOT "classic")`},
+		{"или", "movie or film", `("movie") OR ("film")`},
+		{"знаки препинания", "don't; stop", `("don t" AND "stop")`},
+		{"кавычка внутри слова", `mo"vie`, `("mo" AND "vie")`},
+		{"только исключение", "-movie", ""},
+		{"пустой", "  ", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ftsQuery(tt.query))
+		})
+	}
+}
@@ -0,0 +1,417 @@
+// Package sqlite — хранилище комнат во встроенной базе SQLite для установок
+// без отдельного сервера Postgres. Схема накатывается миграциями migrations/sqlite.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	_ "modernc.org/sqlite" // драйвер database/sql "sqlite"
+
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+var _ model.Store = (*StoreSQLite)(nil)
+
+// timeLayout — формат хранения времени. Ширина фиксирована,
+// поэтому значения в UTC сравниваются как строки.
+const timeLayout = "2006-01-02 15:04:05.000000"
+
+// roomAlive — условие "комната не удалена, не архивирована и не истекла".
+const roomAlive = `deleted_at is null and archived_at is null and (expires_at is null or expires_at > @now)`
+
+// Open открывает базу в режиме WAL: читатели не блокируют запись.
+// Транзакции берут блокировку на запись сразу, чтобы не упираться в SQLITE_BUSY
+// при повышении блокировки посреди транзакции.
+func Open(path string) (*sql.DB, error) {
+	q := url.Values{}
+	q.Add("_pragma", "journal_mode(WAL)")
+	q.Add("_pragma", "synchronous(NORMAL)")
+	q.Add("_pragma", "busy_timeout(5000)")
+	q.Add("_pragma", "foreign_keys(ON)")
+	q.Set("_txlock", "immediate")
+
+	db, err := sql.Open("sqlite", "file:"+path+"?"+q.Encode())
+	if err != nil {
+		return nil, errors.Wrap(err, "open sqlite")
+	}
+
+	if err = db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, wrapErr(err, "ping sqlite")
+	}
+
+	return db, nil
+}
+
+type StoreSQLite struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+func NewRepos(db *sql.DB) *StoreSQLite {
+	return &StoreSQLite{
+		db:  db,
+		now: time.Now,
+	}
+}
+
+// Ping проверяет, что файл базы доступен.
+func (s *StoreSQLite) Ping(ctx context.Context) error {
+	if err := s.db.PingContext(ctx); err != nil {
+		return wrapErr(err, "ping sqlite")
+	}
+
+	return nil
+}
+
+func (s *StoreSQLite) timestamp() string {
+	return formatTime(s.now())
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+// nullTime возвращает значение для nullable-колонки времени.
+func nullTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+
+	return formatTime(*t)
+}
+
+func parseTime(s string) (time.Time, error) {
+	t, err := time.Parse(timeLayout, s)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "parse time")
+	}
+
+	return t, nil
+}
+
+func parseNullTime(s sql.NullString) (*time.Time, error) {
+	if !s.Valid {
+		return nil, nil //nolint:nilnil // NULL — отсутствие времени
+	}
+
+	t, err := parseTime(s.String)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// tenantScope дописывает к запросу условие видимости комнат арендатору
+// запроса. Без арендатора в ctx запрос не ограничивается.
+func tenantScope(ctx context.Context, column string, args []any) (string, []any) {
+	t, ok := model.TenantFrom(ctx)
+	switch {
+	case !ok:
+		return "", args
+	case t.ID == "":
+		return " and " + column + " is null", args
+	}
+
+	return " and " + column + " = @tenant_id", append(args, sql.Named("tenant_id", t.ID))
+}
+
+// nullString возвращает значение для nullable-колонки: пустая строка — NULL.
+func nullString(s string) any {
+	if s == "" {
+		return nil
+	}
+
+	return s
+}
+
+// jsonStrings кодирует список строк для json_each: nil кодируется как пустой массив.
+func jsonStrings(v []string) string {
+	if v == nil {
+		return "[]"
+	}
+
+	b, _ := json.Marshal(v) //nolint:errchkjson // срез строк всегда кодируется
+
+	return string(b)
+}
+
+func (s *StoreSQLite) CreateRoomById(ctx context.Context, id string, meta model.RoomMeta, ownerHash []byte) error {
+	now := s.timestamp()
+	tenant, ok := model.TenantFrom(ctx)
+
+	if !ok || tenant.ID == "" || tenant.Quotas.MaxRooms == 0 {
+		return insertRoom(ctx, s.db, id, meta, ownerHash, now, tenant.ID)
+	}
+
+	// Транзакция сразу берёт блокировку на запись, поэтому подсчёт
+	// и вставка не пересекаются с параллельными запросами
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return wrapErr(err, "begin insert room in sqlite")
+	}
+	// После Commit откат ничего не делает
+	defer func() { _ = tx.Rollback() }()
+
+	var n int
+	err = tx.QueryRowContext(ctx,
+		`select count(*) from rooms where `+roomAlive+` and tenant_id = @tenant_id`,
+		sql.Named("now", now),
+		sql.Named("tenant_id", tenant.ID),
+	).Scan(&n)
+	if err != nil {
+		return wrapErr(err, "count tenant rooms in sqlite")
+	}
+	if n >= tenant.Quotas.MaxRooms {
+		return errors.Wrapf(model.ErrQuotaExceeded, "tenant room limit of %d reached", tenant.Quotas.MaxRooms)
+	}
+
+	if err = insertRoom(ctx, tx, id, meta, ownerHash, now, tenant.ID); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return wrapErr(err, "commit insert room in sqlite")
+	}
+
+	return nil
+}
+
+// execer выполняет запрос и в базе, и в транзакции.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func insertRoom(ctx context.Context, db execer, id string, meta model.RoomMeta, ownerHash []byte, now, tenantID string) error {
+	exec, err := db.ExecContext(ctx,
+		`insert into rooms (id, title, description, visibility, tags, owner_token_hash,
+		                    created_at, expires_at, last_active_at, created_by, tenant_id)
+		 values (@id, @title, @description, @visibility, @tags, @owner_token_hash,
+		         @now, @expires_at, @now, @created_by, @tenant_id)`,
+		sql.Named("id", id),
+		sql.Named("title", meta.Title),
+		sql.Named("description", meta.Description),
+		sql.Named("visibility", meta.Visibility),
+		sql.Named("tags", jsonStrings(meta.Tags)),
+		sql.Named("owner_token_hash", ownerHash),
+		sql.Named("now", now),
+		sql.Named("expires_at", nullTime(meta.ExpiresAt)),
+		sql.Named("created_by", nullString(meta.CreatedBy)),
+		sql.Named("tenant_id", nullString(tenantID)),
+	)
+	if err != nil {
+		return wrapErr(err, "insert room in sqlite")
+	}
+
+	if n, _ := exec.RowsAffected(); n != 1 {
+		return errors.New("no insert room")
+	}
+
+	return nil
+}
+
+// DeleteRoomById мягко удаляет комнату: она пропадает из выдачи,
+// но до окончательной очистки её можно восстановить.
+func (s *StoreSQLite) DeleteRoomById(ctx context.Context, id string) error {
+	scope, args := tenantScope(ctx, "tenant_id", []any{
+		sql.Named("id", id),
+		sql.Named("now", s.timestamp()),
+	})
+
+	exec, err := s.db.ExecContext(ctx,
+		`update rooms set deleted_at = @now where id = @id and deleted_at is null`+scope,
+		args...,
+	)
+	if err != nil {
+		return wrapErr(err, "delete room in sqlite")
+	}
+
+	if n, _ := exec.RowsAffected(); n == 0 {
+		return errors.Wrap(model.ErrNotFound, "room not found")
+	}
+
+	return nil
+}
+
+func (s *StoreSQLite) RoomExists(ctx context.Context, id string) (bool, error) {
+	scope, args := tenantScope(ctx, "tenant_id", []any{
+		sql.Named("id", id),
+		sql.Named("now", s.timestamp()),
+	})
+
+	var exists bool
+	err := s.db.QueryRowContext(ctx,
+		`select exists (select * from rooms where id = @id and `+roomAlive+scope+`)`,
+		args...,
+	).Scan(&exists)
+	if err != nil {
+		return false, wrapErr(err, "check room exists")
+	}
+
+	return exists, nil
+}
+
+func (s *StoreSQLite) RoomOwnerHash(ctx context.Context, id string) ([]byte, error) {
+	scope, args := tenantScope(ctx, "tenant_id", []any{sql.Named("id", id)})
+
+	var hash []byte
+	err := s.db.QueryRowContext(ctx,
+		`select owner_token_hash from rooms where id = @id`+scope,
+		args...,
+	).Scan(&hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.Wrap(model.ErrNotFound, "room not found")
+	}
+	if err != nil {
+		return nil, wrapErr(err, "select room owner hash in sqlite")
+	}
+
+	return hash, nil
+}
+
+func (s *StoreSQLite) RoomCreatedBy(ctx context.Context, id string) (string, error) {
+	scope, args := tenantScope(ctx, "tenant_id", []any{sql.Named("id", id)})
+
+	var createdBy string
+	err := s.db.QueryRowContext(ctx,
+		`select coalesce(created_by, '') from rooms where id = @id`+scope,
+		args...,
+	).Scan(&createdBy)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", errors.Wrap(model.ErrNotFound, "room not found")
+	}
+	if err != nil {
+		return "", wrapErr(err, "select room creator in sqlite")
+	}
+
+	return createdBy, nil
+}
+
+func (s *StoreSQLite) ListRooms(ctx context.Context, f model.RoomFilter) ([]model.RoomInfo, error) {
+	where := []string{roomAlive}
+	args := []any{
+		sql.Named("now", s.timestamp()),
+		sql.Named("limit", f.Limit),
+	}
+
+	if f.Visibility != "" {
+		where = append(where, "visibility = @visibility")
+		args = append(args, sql.Named("visibility", f.Visibility))
+	}
+
+	if f.CreatedAfter != nil {
+		where = append(where, "created_at > @created_after")
+		args = append(args, sql.Named("created_after", formatTime(*f.CreatedAfter)))
+	}
+
+	if f.CreatedBy != "" {
+		where = append(where, "created_by = @created_by")
+		args = append(args, sql.Named("created_by", f.CreatedBy))
+	}
+
+	if f.Tag != "" {
+		where = append(where, "exists (select * from json_each(tags) where value = @tag)")
+		args = append(args, sql.Named("tag", f.Tag))
+	}
+
+	if f.HasActivePeers != nil {
+		if *f.HasActivePeers {
+			where = append(where, "id in (select value from json_each(@active_ids))")
+		} else {
+			where = append(where, "id not in (select value from json_each(@active_ids))")
+		}
+
+		ids := make([]string, 0, len(f.ActiveIDs))
+		for _, id := range f.ActiveIDs {
+			ids = append(ids, id.String())
+		}
+		args = append(args, sql.Named("active_ids", jsonStrings(ids)))
+	}
+
+	column, desc := sortColumn(f.Sort)
+	direction := "asc"
+	if desc {
+		direction = "desc"
+	}
+
+	if f.After != nil {
+		op := ">"
+		if desc {
+			op = "<"
+		}
+		where = append(where, fmt.Sprintf("(%s, id) %s (@after_key, @after_id)", column, op))
+		args = append(args, sql.Named("after_id", f.After.ID))
+
+		if column == "title" {
+			args = append(args, sql.Named("after_key", f.After.Title))
+		} else {
+			args = append(args, sql.Named("after_key", formatTime(f.After.CreatedAt)))
+		}
+	}
+
+	scope, args := tenantScope(ctx, "tenant_id", args)
+
+	query := `select id, title, description, visibility, tags, created_at, expires_at, created_by from rooms where ` +
+		strings.Join(where, " and ") + scope
+	query += fmt.Sprintf(" order by %[1]s %[2]s, id %[2]s limit @limit", column, direction)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, wrapErr(err, "list rooms in sqlite")
+	}
+	defer rows.Close()
+
+	res := make([]model.RoomInfo, 0, f.Limit)
+	for rows.Next() {
+		var (
+			r               model.RoomInfo
+			tags, createdAt string
+			expiresAt       sql.NullString
+			createdBy       sql.NullString
+		)
+		if err = rows.Scan(&r.ID, &r.Title, &r.Description, &r.Visibility, &tags, &createdAt, &expiresAt, &createdBy); err != nil {
+			return nil, errors.Wrap(err, "scan room")
+		}
+
+		if err = json.Unmarshal([]byte(tags), &r.Tags); err != nil {
+			return nil, errors.Wrap(err, "decode room tags")
+		}
+		if r.CreatedAt, err = parseTime(createdAt); err != nil {
+			return nil, err
+		}
+		if r.ExpiresAt, err = parseNullTime(expiresAt); err != nil {
+			return nil, err
+		}
+		r.CreatedBy = createdBy.String
+
+		res = append(res, r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "list rooms rows")
+	}
+
+	return res, nil
+}
+
+// sortColumn возвращает колонку сортировки и признак убывания.
+func sortColumn(sort string) (string, bool) {
+	switch sort {
+	case model.SortTitleAsc:
+		return "title", false
+	case model.SortTitleDesc:
+		return "title", true
+	case model.SortCreatedAsc:
+		return "created_at", false
+	default:
+		return "created_at", true
+	}
+}
@@ -0,0 +1,162 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+const tenantColumns = `id, name, max_rooms, max_peers, messages_per_min, created_at`
+
+func (s *StoreSQLite) CreateTenant(ctx context.Context, t model.Tenant, keyHash []byte) (model.Tenant, error) {
+	now := s.now().UTC().Truncate(time.Microsecond)
+
+	_, err := s.db.ExecContext(ctx,
+		`insert into tenants (id, name, api_key_hash, max_rooms, max_peers, messages_per_min, created_at)
+		 values (@id, @name, @api_key_hash, @max_rooms, @max_peers, @messages_per_min, @now)`,
+		sql.Named("id", t.ID),
+		sql.Named("name", t.Name),
+		sql.Named("api_key_hash", keyHash),
+		sql.Named("max_rooms", t.Quotas.MaxRooms),
+		sql.Named("max_peers", t.Quotas.MaxPeers),
+		sql.Named("messages_per_min", t.Quotas.MessagesPerMin),
+		sql.Named("now", formatTime(now)),
+	)
+	if err != nil {
+		return model.Tenant{}, wrapErr(err, "insert tenant in sqlite")
+	}
+
+	t.CreatedAt = now
+
+	return t, nil
+}
+
+func (s *StoreSQLite) UpdateTenantQuotas(ctx context.Context, name string, q model.TenantQuotas) (model.Tenant, error) {
+	row := s.db.QueryRowContext(ctx,
+		`update tenants set max_rooms = @max_rooms, max_peers = @max_peers, messages_per_min = @messages_per_min
+		 where name = @name
+		 returning `+tenantColumns,
+		sql.Named("name", name),
+		sql.Named("max_rooms", q.MaxRooms),
+		sql.Named("max_peers", q.MaxPeers),
+		sql.Named("messages_per_min", q.MessagesPerMin),
+	)
+
+	t, err := scanTenant(row)
+	if err != nil {
+		return model.Tenant{}, wrapErr(err, "update tenant in sqlite")
+	}
+
+	return t, nil
+}
+
+func (s *StoreSQLite) ListTenants(ctx context.Context) ([]model.Tenant, error) {
+	rows, err := s.db.QueryContext(ctx, `select `+tenantColumns+` from tenants order by name`)
+	if err != nil {
+		return nil, wrapErr(err, "list tenants in sqlite")
+	}
+	defer rows.Close()
+
+	res := make([]model.Tenant, 0)
+	for rows.Next() {
+		t, err := scanTenant(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "list tenants rows")
+	}
+
+	return res, nil
+}
+
+func (s *StoreSQLite) TenantByKeyHash(ctx context.Context, keyHash []byte) (model.Tenant, error) {
+	row := s.db.QueryRowContext(ctx,
+		`select `+tenantColumns+` from tenants where api_key_hash = @api_key_hash`,
+		sql.Named("api_key_hash", keyHash),
+	)
+
+	t, err := scanTenant(row)
+	if err != nil {
+		return model.Tenant{}, wrapErr(err, "select tenant in sqlite")
+	}
+
+	return t, nil
+}
+
+// CountRooms считает живые комнаты арендатора запроса.
+func (s *StoreSQLite) CountRooms(ctx context.Context) (int, error) {
+	scope, args := tenantScope(ctx, "tenant_id", []any{sql.Named("now", s.timestamp())})
+
+	var n int
+	err := s.db.QueryRowContext(ctx,
+		`select count(*) from rooms where `+roomAlive+scope,
+		args...,
+	).Scan(&n)
+	if err != nil {
+		return 0, wrapErr(err, "count rooms in sqlite")
+	}
+
+	return n, nil
+}
+
+// CountRoomsByTenant считает живые комнаты по арендаторам; комнаты
+// арендатора по умолчанию лежат под пустым ключом.
+func (s *StoreSQLite) CountRoomsByTenant(ctx context.Context) (map[string]int, error) {
+	scope, args := tenantScope(ctx, "tenant_id", []any{sql.Named("now", s.timestamp())})
+
+	rows, err := s.db.QueryContext(ctx,
+		`select coalesce(tenant_id, ''), count(*) from rooms where `+roomAlive+scope+` group by tenant_id`,
+		args...,
+	)
+	if err != nil {
+		return nil, wrapErr(err, "count rooms by tenant in sqlite")
+	}
+	defer rows.Close()
+
+	res := make(map[string]int)
+	for rows.Next() {
+		var (
+			id string
+			n  int
+		)
+		if err = rows.Scan(&id, &n); err != nil {
+			return nil, errors.Wrap(err, "scan room count")
+		}
+		res[id] = n
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "count rooms by tenant rows")
+	}
+
+	return res, nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTenant(row scanner) (model.Tenant, error) {
+	var (
+		t         model.Tenant
+		createdAt string
+	)
+	err := row.Scan(&t.ID, &t.Name, &t.Quotas.MaxRooms, &t.Quotas.MaxPeers, &t.Quotas.MessagesPerMin, &createdAt)
+	if err != nil {
+		return model.Tenant{}, err
+	}
+
+	if t.CreatedAt, err = parseTime(createdAt); err != nil {
+		return model.Tenant{}, err
+	}
+
+	return t, nil
+}
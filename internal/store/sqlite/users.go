@@ -0,0 +1,124 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+func (s *StoreSQLite) CreateUser(ctx context.Context, u model.User, passwordHash string) (model.User, error) {
+	now := s.now().UTC().Truncate(time.Microsecond)
+
+	_, err := s.db.ExecContext(ctx,
+		`insert into users (id, username, password_hash, created_at) values (@id, @username, @password_hash, @now)`,
+		sql.Named("id", u.ID),
+		sql.Named("username", u.Username),
+		sql.Named("password_hash", passwordHash),
+		sql.Named("now", formatTime(now)),
+	)
+	if err != nil {
+		return model.User{}, wrapErr(err, "insert user in sqlite")
+	}
+
+	u.CreatedAt = now
+
+	return u, nil
+}
+
+func (s *StoreSQLite) UserByName(ctx context.Context, username string) (model.User, string, error) {
+	var (
+		u         model.User
+		hash      string
+		createdAt string
+	)
+	err := s.db.QueryRowContext(ctx,
+		`select id, username, password_hash, created_at from users where username = @username`,
+		sql.Named("username", username),
+	).Scan(&u.ID, &u.Username, &hash, &createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.User{}, "", errors.Wrap(model.ErrNotFound, "user not found")
+	}
+	if err != nil {
+		return model.User{}, "", wrapErr(err, "select user in sqlite")
+	}
+
+	if u.CreatedAt, err = parseTime(createdAt); err != nil {
+		return model.User{}, "", err
+	}
+
+	return u, hash, nil
+}
+
+func (s *StoreSQLite) CreateSession(ctx context.Context, tokenHash []byte, userID string, expiresAt time.Time) error {
+	_, err := s.db.ExecContext(ctx,
+		`insert into sessions (token_hash, user_id, created_at, expires_at)
+		 values (@token_hash, @user_id, @now, @expires_at)`,
+		sql.Named("token_hash", tokenHash),
+		sql.Named("user_id", userID),
+		sql.Named("now", s.timestamp()),
+		sql.Named("expires_at", formatTime(expiresAt)),
+	)
+	if err != nil {
+		return wrapErr(err, "insert session in sqlite")
+	}
+
+	return nil
+}
+
+func (s *StoreSQLite) SessionByHash(ctx context.Context, tokenHash []byte) (model.User, time.Time, error) {
+	var (
+		u                    model.User
+		createdAt, expiresAt string
+	)
+	err := s.db.QueryRowContext(ctx,
+		`select u.id, u.username, u.created_at, s.expires_at
+		 from sessions s join users u on u.id = s.user_id
+		 where s.token_hash = @token_hash`,
+		sql.Named("token_hash", tokenHash),
+	).Scan(&u.ID, &u.Username, &createdAt, &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.User{}, time.Time{}, errors.Wrap(model.ErrNotFound, "session not found")
+	}
+	if err != nil {
+		return model.User{}, time.Time{}, wrapErr(err, "select session in sqlite")
+	}
+
+	if u.CreatedAt, err = parseTime(createdAt); err != nil {
+		return model.User{}, time.Time{}, err
+	}
+	exp, err := parseTime(expiresAt)
+	if err != nil {
+		return model.User{}, time.Time{}, err
+	}
+
+	return u, exp, nil
+}
+
+func (s *StoreSQLite) DeleteSession(ctx context.Context, tokenHash []byte) error {
+	_, err := s.db.ExecContext(ctx,
+		`delete from sessions where token_hash = @token_hash`,
+		sql.Named("token_hash", tokenHash),
+	)
+	if err != nil {
+		return wrapErr(err, "delete session in sqlite")
+	}
+
+	return nil
+}
+
+func (s *StoreSQLite) DeleteExpiredSessions(ctx context.Context, userID string, before time.Time) error {
+	_, err := s.db.ExecContext(ctx,
+		`delete from sessions where user_id = @user_id and expires_at <= @before`,
+		sql.Named("user_id", userID),
+		sql.Named("before", formatTime(before)),
+	)
+	if err != nil {
+		return wrapErr(err, "delete expired sessions in sqlite")
+	}
+
+	return nil
+}
@@ -0,0 +1,610 @@
+// Package storetest — общий набор тестов, которому должны соответствовать
+// все реализации model.Store.
+package storetest
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/vpbuyanov/syncplay/internal/model"
+)
+
+// Run прогоняет набор тестов. newStore должен возвращать пустое хранилище.
+func Run(t *testing.T, newStore func(t *testing.T) model.Store) {
+	t.Helper()
+
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, s model.Store)
+	}{
+		{"Ping", testPing},
+		{"CreateRoom", testCreateRoom},
+		{"OwnerHash", testOwnerHash},
+		{"DeleteAndRestore", testDeleteAndRestore},
+		{"ListRooms", testListRooms},
+		{"ListRoomsPagination", testListRoomsPagination},
+		{"Chat", testChat},
+		{"SearchRooms", testSearchRooms},
+		{"SearchChat", testSearchChat},
+		{"Expiry", testExpiry},
+		{"IdleRooms", testIdleRooms},
+		{"ArchiveAndPurge", testArchiveAndPurge},
+		{"DeletedRooms", testDeletedRooms},
+		{"Users", testUsers},
+		{"Sessions", testSessions},
+		{"Tenants", testTenants},
+		{"TenantScope", testTenantScope},
+		{"RoomQuota", testRoomQuota},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newStore(t))
+		})
+	}
+}
+
+func testPing(t *testing.T, s model.Store) {
+	require.NoError(t, s.Ping(context.Background()))
+}
+
+func createRoom(t *testing.T, s model.Store, meta model.RoomMeta) string {
+	t.Helper()
+
+	if meta.Visibility == "" {
+		meta.Visibility = model.VisibilityPublic
+	}
+	if meta.Tags == nil {
+		meta.Tags = []string{}
+	}
+
+	id := uuid.NewString()
+	require.NoError(t, s.CreateRoomById(context.Background(), id, meta, []byte("hash-"+id)))
+
+	return id
+}
+
+func testCreateRoom(t *testing.T, s model.Store) {
+	ctx := context.Background()
+	id := createRoom(t, s, model.RoomMeta{Title: "room"})
+
+	exists, err := s.RoomExists(ctx, id)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = s.RoomExists(ctx, uuid.NewString())
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	err = s.CreateRoomById(ctx, id, model.RoomMeta{Visibility: model.VisibilityPublic, Tags: []string{}}, nil)
+	require.ErrorIs(t, err, model.ErrConflict)
+}
+
+func testOwnerHash(t *testing.T, s model.Store) {
+	ctx := context.Background()
+	id := createRoom(t, s, model.RoomMeta{})
+
+	hash, err := s.RoomOwnerHash(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hash-"+id), hash)
+
+	_, err = s.RoomOwnerHash(ctx, uuid.NewString())
+	require.ErrorIs(t, err, model.ErrNotFound)
+
+	createdBy, err := s.RoomCreatedBy(ctx, id)
+	require.NoError(t, err)
+	assert.Empty(t, createdBy)
+
+	owned := createRoom(t, s, model.RoomMeta{CreatedBy: "alice"})
+	createdBy, err = s.RoomCreatedBy(ctx, owned)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", createdBy)
+
+	_, err = s.RoomCreatedBy(ctx, uuid.NewString())
+	require.ErrorIs(t, err, model.ErrNotFound)
+}
+
+func testDeleteAndRestore(t *testing.T, s model.Store) {
+	ctx := context.Background()
+	id := createRoom(t, s, model.RoomMeta{})
+
+	require.NoError(t, s.DeleteRoomById(ctx, id))
+	require.ErrorIs(t, s.DeleteRoomById(ctx, id), model.ErrNotFound)
+	require.ErrorIs(t, s.DeleteRoomById(ctx, uuid.NewString()), model.ErrNotFound)
+
+	exists, err := s.RoomExists(ctx, id)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// Окно восстановления уже прошло
+	require.ErrorIs(t, s.RestoreRoomById(ctx, id, time.Now().Add(time.Hour).UTC()), model.ErrNotFound)
+
+	require.NoError(t, s.RestoreRoomById(ctx, id, time.Now().Add(-time.Hour).UTC()))
+
+	exists, err = s.RoomExists(ctx, id)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	// Живую комнату восстанавливать нечего
+	require.ErrorIs(t, s.RestoreRoomById(ctx, id, time.Now().Add(-time.Hour).UTC()), model.ErrNotFound)
+}
+
+func testListRooms(t *testing.T, s model.Store) {
+	ctx := context.Background()
+
+	alpha := createRoom(t, s, model.RoomMeta{Title: "alpha", Description: "first", Tags: []string{"movies", "fun"}})
+	bravo := createRoom(t, s, model.RoomMeta{Title: "bravo", Visibility: model.VisibilityPrivate, Tags: []string{"music"}})
+	charlie := createRoom(t, s, model.RoomMeta{Title: "charlie", Tags: []string{"movies"}, CreatedBy: "alice"})
+	deleted := createRoom(t, s, model.RoomMeta{Title: "deleted"})
+	require.NoError(t, s.DeleteRoomById(ctx, deleted))
+
+	list := func(f model.RoomFilter) []string {
+		t.Helper()
+
+		if f.Limit == 0 {
+			f.Limit = 10
+		}
+
+		rooms, err := s.ListRooms(ctx, f)
+		require.NoError(t, err)
+
+		ids := make([]string, 0, len(rooms))
+		for _, r := range rooms {
+			ids = append(ids, r.ID)
+		}
+
+		return ids
+	}
+
+	assert.Equal(t, []string{alpha, bravo, charlie}, list(model.RoomFilter{Sort: model.SortTitleAsc}))
+	assert.Equal(t, []string{charlie, bravo, alpha}, list(model.RoomFilter{Sort: model.SortTitleDesc}))
+	assert.Equal(t, []string{alpha, charlie}, list(model.RoomFilter{Sort: model.SortTitleAsc, Visibility: model.VisibilityPublic}))
+	assert.Equal(t, []string{alpha, charlie}, list(model.RoomFilter{Sort: model.SortTitleAsc, Tag: "movies"}))
+	assert.Equal(t, []string{charlie}, list(model.RoomFilter{Sort: model.SortTitleAsc, CreatedBy: "alice"}))
+
+	active := true
+	assert.Equal(t, []string{bravo}, list(model.RoomFilter{
+		Sort:           model.SortTitleAsc,
+		HasActivePeers: &active,
+		ActiveIDs:      []uuid.UUID{uuid.MustParse(bravo)},
+	}))
+
+	inactive := false
+	assert.Equal(t, []string{alpha, charlie}, list(model.RoomFilter{
+		Sort:           model.SortTitleAsc,
+		HasActivePeers: &inactive,
+		ActiveIDs:      []uuid.UUID{uuid.MustParse(bravo)},
+	}))
+
+	// Без активных комнат фильтр "нет участников" пропускает все комнаты
+	assert.Equal(t, []string{alpha, bravo, charlie}, list(model.RoomFilter{
+		Sort:           model.SortTitleAsc,
+		HasActivePeers: &inactive,
+	}))
+
+	rooms, err := s.ListRooms(ctx, model.RoomFilter{Sort: model.SortTitleAsc, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, rooms, 1)
+	assert.Equal(t, "alpha", rooms[0].Title)
+	assert.Equal(t, "first", rooms[0].Description)
+	assert.Equal(t, model.VisibilityPublic, rooms[0].Visibility)
+	assert.ElementsMatch(t, []string{"movies", "fun"}, rooms[0].Tags)
+	assert.WithinDuration(t, time.Now(), rooms[0].CreatedAt, time.Minute)
+	assert.Nil(t, rooms[0].ExpiresAt)
+	assert.Empty(t, rooms[0].CreatedBy)
+
+	created := list(model.RoomFilter{Sort: model.SortCreatedAsc, CreatedAfter: &rooms[0].CreatedAt})
+	assert.NotContains(t, created, alpha)
+}
+
+func testListRoomsPagination(t *testing.T, s model.Store) {
+	ctx := context.Background()
+
+	want := make([]string, 0, 5)
+	for range 5 {
+		want = append(want, createRoom(t, s, model.RoomMeta{Title: "same"}))
+	}
+
+	for _, sort := range []string{model.SortTitleAsc, model.SortCreatedDesc} {
+		var (
+			got   []string
+			after *model.RoomInfo
+		)
+
+		for {
+			page, err := s.ListRooms(ctx, model.RoomFilter{Sort: sort, After: after, Limit: 2})
+			require.NoError(t, err)
+			if len(page) == 0 {
+				break
+			}
+
+			for _, r := range page {
+				got = append(got, r.ID)
+			}
+			last := page[len(page)-1]
+			after = &last
+		}
+
+		assert.ElementsMatch(t, want, got, "sort %s", sort)
+	}
+}
+
+func testChat(t *testing.T, s model.Store) {
+	ctx := context.Background()
+	id := createRoom(t, s, model.RoomMeta{})
+
+	first, err := s.SaveChatMessage(ctx, model.ChatMessage{RoomID: id, Sender: "peer", Text: "hello"})
+	require.NoError(t, err)
+	assert.Positive(t, first.ID)
+	assert.Equal(t, "hello", first.Text)
+	assert.WithinDuration(t, time.Now(), first.CreatedAt, time.Minute)
+
+	second, err := s.SaveChatMessage(ctx, model.ChatMessage{RoomID: id, Sender: "peer", Text: "again"})
+	require.NoError(t, err)
+	assert.Greater(t, second.ID, first.ID)
+
+	_, err = s.SaveChatMessage(ctx, model.ChatMessage{RoomID: uuid.NewString(), Sender: "peer", Text: "lost"})
+	require.ErrorIs(t, err, model.ErrNotFound)
+}
+
+func testSearchRooms(t *testing.T, s model.Store) {
+	ctx := context.Background()
+
+	title := createRoom(t, s, model.RoomMeta{Title: "Movie night", Description: "classic films"})
+	desc := createRoom(t, s, model.RoomMeta{Title: "Friday", Description: "movie marathon"})
+	createRoom(t, s, model.RoomMeta{Title: "Movie secret", Visibility: model.VisibilityPrivate})
+	deleted := createRoom(t, s, model.RoomMeta{Title: "Movie deleted"})
+	require.NoError(t, s.DeleteRoomById(ctx, deleted))
+
+	hits, err := s.SearchRooms(ctx, "movie", 10)
+	require.NoError(t, err)
+	require.Len(t, hits, 2)
+
+	// Совпадение в названии весит больше, чем в описании
+	assert.Equal(t, title, hits[0].RoomID)
+	assert.Equal(t, desc, hits[1].RoomID)
+	assert.Greater(t, hits[0].Rank, hits[1].Rank)
+	assert.Equal(t, model.SearchKindRoom, hits[0].Kind)
+	assert.Equal(t, "Movie night", hits[0].Title)
+	assert.Contains(t, hits[0].Snippet, model.HighlightStart+"Movie"+model.HighlightStop)
+
+	hits, err = s.SearchRooms(ctx, "movie -classic", 10)
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	assert.Equal(t, desc, hits[0].RoomID)
+
+	hits, err = s.SearchRooms(ctx, `"movie marathon"`, 10)
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	assert.Equal(t, desc, hits[0].RoomID)
+
+	hits, err = s.SearchRooms(ctx, "movie", 1)
+	require.NoError(t, err)
+	assert.Len(t, hits, 1)
+}
+
+func testSearchChat(t *testing.T, s model.Store) {
+	ctx := context.Background()
+	id := createRoom(t, s, model.RoomMeta{})
+	other := createRoom(t, s, model.RoomMeta{})
+
+	msg, err := s.SaveChatMessage(ctx, model.ChatMessage{RoomID: id, Sender: "alice", Text: "Let's watch Alien tonight"})
+	require.NoError(t, err)
+	_, err = s.SaveChatMessage(ctx, model.ChatMessage{RoomID: id, Sender: "bob", Text: "sounds good"})
+	require.NoError(t, err)
+	_, err = s.SaveChatMessage(ctx, model.ChatMessage{RoomID: other, Sender: "eve", Text: "alien in another room"})
+	require.NoError(t, err)
+
+	hits, err := s.SearchChat(ctx, id, "alien", 10)
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	assert.Equal(t, model.SearchKindMessage, hits[0].Kind)
+	assert.Equal(t, msg.ID, hits[0].MessageID)
+	assert.Equal(t, id, hits[0].RoomID)
+	assert.Equal(t, "alice", hits[0].Sender)
+	assert.True(t, strings.Contains(hits[0].Snippet, model.HighlightStart+"Alien"+model.HighlightStop), hits[0].Snippet)
+}
+
+func testExpiry(t *testing.T, s model.Store) {
+	ctx := context.Background()
+
+	soon := time.Now().Add(time.Hour).UTC()
+	later := time.Now().Add(2 * time.Hour).UTC()
+	first := createRoom(t, s, model.RoomMeta{ExpiresAt: &soon})
+	second := createRoom(t, s, model.RoomMeta{ExpiresAt: &later})
+	createRoom(t, s, model.RoomMeta{})
+
+	rooms, err := s.ExpiringRooms(ctx, time.Now().Add(3*time.Hour).UTC(), 10)
+	require.NoError(t, err)
+	require.Len(t, rooms, 2)
+	assert.Equal(t, first, rooms[0].ID)
+	assert.Equal(t, second, rooms[1].ID)
+	require.NotNil(t, rooms[0].ExpiresAt)
+	assert.WithinDuration(t, soon, *rooms[0].ExpiresAt, time.Millisecond)
+
+	rooms, err = s.ExpiringRooms(ctx, time.Now().UTC(), 10)
+	require.NoError(t, err)
+	assert.Empty(t, rooms)
+
+	rooms, err = s.ExpiringRooms(ctx, time.Now().Add(3*time.Hour).UTC(), 1)
+	require.NoError(t, err)
+	assert.Len(t, rooms, 1)
+}
+
+func testIdleRooms(t *testing.T, s model.Store) {
+	ctx := context.Background()
+
+	idle := createRoom(t, s, model.RoomMeta{})
+	busy := createRoom(t, s, model.RoomMeta{})
+	require.NoError(t, s.TouchRoom(ctx, busy))
+	require.NoError(t, s.TouchRoom(ctx, uuid.NewString()))
+
+	ids, err := s.IdleRooms(ctx, time.Now().Add(time.Minute).UTC(), []string{busy}, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{idle}, ids)
+
+	ids, err = s.IdleRooms(ctx, time.Now().Add(-time.Minute).UTC(), nil, 10)
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+}
+
+func testArchiveAndPurge(t *testing.T, s model.Store) {
+	ctx := context.Background()
+
+	archived := createRoom(t, s, model.RoomMeta{})
+	purged := createRoom(t, s, model.RoomMeta{})
+	_, err := s.SaveChatMessage(ctx, model.ChatMessage{RoomID: purged, Sender: "peer", Text: "bye"})
+	require.NoError(t, err)
+
+	n, err := s.ArchiveRooms(ctx, []string{archived})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	n, err = s.ArchiveRooms(ctx, []string{archived})
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	exists, err := s.RoomExists(ctx, archived)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// Архивная комната восстанавливается независимо от окна удаления
+	require.NoError(t, s.RestoreRoomById(ctx, archived, time.Now().Add(time.Hour).UTC()))
+
+	n, err = s.PurgeRooms(ctx, []string{purged, uuid.NewString()})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	_, err = s.RoomOwnerHash(ctx, purged)
+	require.ErrorIs(t, err, model.ErrNotFound)
+
+	hits, err := s.SearchChat(ctx, purged, "bye", 10)
+	require.NoError(t, err)
+	assert.Empty(t, hits)
+}
+
+func testDeletedRooms(t *testing.T, s model.Store) {
+	ctx := context.Background()
+
+	deleted := createRoom(t, s, model.RoomMeta{})
+	createRoom(t, s, model.RoomMeta{})
+	require.NoError(t, s.DeleteRoomById(ctx, deleted))
+
+	ids, err := s.DeletedRooms(ctx, time.Now().Add(time.Minute).UTC(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{deleted}, ids)
+
+	ids, err = s.DeletedRooms(ctx, time.Now().Add(-time.Minute).UTC(), 10)
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+}
+
+func testUsers(t *testing.T, s model.Store) {
+	ctx := context.Background()
+
+	user, err := s.CreateUser(ctx, model.User{ID: uuid.NewString(), Username: "alice"}, "hash")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), user.CreatedAt, time.Minute)
+
+	got, hash, err := s.UserByName(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, got.ID)
+	assert.Equal(t, "alice", got.Username)
+	assert.Equal(t, "hash", hash)
+
+	_, err = s.CreateUser(ctx, model.User{ID: uuid.NewString(), Username: "alice"}, "other")
+	require.ErrorIs(t, err, model.ErrConflict)
+
+	_, _, err = s.UserByName(ctx, "bob")
+	require.ErrorIs(t, err, model.ErrNotFound)
+}
+
+func testSessions(t *testing.T, s model.Store) {
+	ctx := context.Background()
+
+	user, err := s.CreateUser(ctx, model.User{ID: uuid.NewString(), Username: "alice"}, "hash")
+	require.NoError(t, err)
+
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	require.NoError(t, s.CreateSession(ctx, []byte("live"), user.ID, expiresAt))
+	require.NoError(t, s.CreateSession(ctx, []byte("old"), user.ID, time.Now().Add(-time.Hour).UTC()))
+
+	got, exp, err := s.SessionByHash(ctx, []byte("live"))
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, got.ID)
+	assert.Equal(t, "alice", got.Username)
+	assert.True(t, expiresAt.Equal(exp))
+
+	// Сессия неизвестного пользователя
+	require.ErrorIs(t, s.CreateSession(ctx, []byte("lost"), uuid.NewString(), expiresAt), model.ErrNotFound)
+
+	require.NoError(t, s.DeleteExpiredSessions(ctx, user.ID, time.Now().UTC()))
+	_, _, err = s.SessionByHash(ctx, []byte("old"))
+	require.ErrorIs(t, err, model.ErrNotFound)
+
+	require.NoError(t, s.DeleteSession(ctx, []byte("live")))
+	_, _, err = s.SessionByHash(ctx, []byte("live"))
+	require.ErrorIs(t, err, model.ErrNotFound)
+
+	// Повторное удаление — не ошибка
+	require.NoError(t, s.DeleteSession(ctx, []byte("live")))
+}
+
+func testTenants(t *testing.T, s model.Store) {
+	ctx := context.Background()
+
+	acme, err := s.CreateTenant(ctx, model.Tenant{
+		ID:     uuid.NewString(),
+		Name:   "acme",
+		Quotas: model.TenantQuotas{MaxRooms: 5},
+	}, []byte("key-acme"))
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), acme.CreatedAt, time.Minute)
+
+	_, err = s.CreateTenant(ctx, model.Tenant{ID: uuid.NewString(), Name: "acme"}, []byte("key-other"))
+	require.ErrorIs(t, err, model.ErrConflict)
+
+	got, err := s.TenantByKeyHash(ctx, []byte("key-acme"))
+	require.NoError(t, err)
+	assert.Equal(t, acme.ID, got.ID)
+	assert.Equal(t, 5, got.Quotas.MaxRooms)
+
+	_, err = s.TenantByKeyHash(ctx, []byte("key-unknown"))
+	require.ErrorIs(t, err, model.ErrNotFound)
+
+	q := model.TenantQuotas{MaxRooms: 1, MaxPeers: 2, MessagesPerMin: 3}
+	updated, err := s.UpdateTenantQuotas(ctx, "acme", q)
+	require.NoError(t, err)
+	assert.Equal(t, q, updated.Quotas)
+
+	_, err = s.UpdateTenantQuotas(ctx, "nobody", q)
+	require.ErrorIs(t, err, model.ErrNotFound)
+
+	_, err = s.CreateTenant(ctx, model.Tenant{ID: uuid.NewString(), Name: "beta"}, []byte("key-beta"))
+	require.NoError(t, err)
+
+	list, err := s.ListTenants(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "acme", list[0].Name)
+	assert.Equal(t, q, list[0].Quotas)
+	assert.Equal(t, "beta", list[1].Name)
+}
+
+func testTenantScope(t *testing.T, s model.Store) {
+	acme, err := s.CreateTenant(context.Background(), model.Tenant{ID: uuid.NewString(), Name: "acme"}, []byte("key-acme"))
+	require.NoError(t, err)
+	beta, err := s.CreateTenant(context.Background(), model.Tenant{ID: uuid.NewString(), Name: "beta"}, []byte("key-beta"))
+	require.NoError(t, err)
+
+	acmeCtx := model.WithTenant(context.Background(), acme)
+	betaCtx := model.WithTenant(context.Background(), beta)
+	defaultCtx := model.WithTenant(context.Background(), model.Tenant{})
+
+	meta := model.RoomMeta{Title: "acme room", Visibility: model.VisibilityPublic, Tags: []string{}}
+	id := uuid.NewString()
+	require.NoError(t, s.CreateRoomById(acmeCtx, id, meta, []byte("hash")))
+	plain := createRoom(t, s, model.RoomMeta{Title: "plain room"})
+
+	exists, err := s.RoomExists(acmeCtx, id)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	// Чужой арендатор и арендатор по умолчанию комнату не видят
+	for _, ctx := range []context.Context{betaCtx, defaultCtx} {
+		exists, err = s.RoomExists(ctx, id)
+		require.NoError(t, err)
+		assert.False(t, exists)
+
+		_, err = s.RoomOwnerHash(ctx, id)
+		require.ErrorIs(t, err, model.ErrNotFound)
+
+		_, err = s.SaveChatMessage(ctx, model.ChatMessage{RoomID: id, Sender: "peer", Text: "hello"})
+		require.ErrorIs(t, err, model.ErrNotFound)
+
+		require.ErrorIs(t, s.DeleteRoomById(ctx, id), model.ErrNotFound)
+	}
+
+	rooms, err := s.ListRooms(acmeCtx, model.RoomFilter{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, rooms, 1)
+	assert.Equal(t, id, rooms[0].ID)
+
+	rooms, err = s.ListRooms(defaultCtx, model.RoomFilter{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, rooms, 1)
+	assert.Equal(t, plain, rooms[0].ID)
+
+	rooms, err = s.ListRooms(betaCtx, model.RoomFilter{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, rooms)
+
+	// Без арендатора видны все комнаты
+	rooms, err = s.ListRooms(context.Background(), model.RoomFilter{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, rooms, 2)
+
+	n, err := s.CountRooms(acmeCtx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	n, err = s.CountRooms(betaCtx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	counts, err := s.CountRoomsByTenant(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{acme.ID: 1, "": 1}, counts)
+
+	require.NoError(t, s.DeleteRoomById(acmeCtx, id))
+}
+
+func testRoomQuota(t *testing.T, s model.Store) {
+	acme, err := s.CreateTenant(context.Background(), model.Tenant{ID: uuid.NewString(), Name: "acme"}, []byte("key-acme"))
+	require.NoError(t, err)
+	acme.Quotas = model.TenantQuotas{MaxRooms: 2}
+	ctx := model.WithTenant(context.Background(), acme)
+
+	meta := model.RoomMeta{Title: "acme room", Visibility: model.VisibilityPublic, Tags: []string{}}
+
+	// Параллельные вставки не должны превысить квоту
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		created  []string
+		rejected int
+	)
+	for range 6 {
+		wg.Go(func() {
+			id := uuid.NewString()
+			err := s.CreateRoomById(ctx, id, meta, []byte("hash"))
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				created = append(created, id)
+			case assert.ErrorIs(t, err, model.ErrQuotaExceeded):
+				rejected++
+			}
+		})
+	}
+	wg.Wait()
+
+	require.Len(t, created, 2)
+	assert.Equal(t, 4, rejected)
+
+	// Удалённая комната квоту не занимает
+	require.NoError(t, s.DeleteRoomById(ctx, created[0]))
+	require.NoError(t, s.CreateRoomById(ctx, uuid.NewString(), meta, []byte("hash")))
+
+	// Квота одного арендатора не мешает остальным
+	createRoom(t, s, model.RoomMeta{Title: "plain room"})
+}
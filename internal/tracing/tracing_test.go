@@ -0,0 +1,44 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+
+	"github.com/vpbuyanov/syncplay/internal/config"
+)
+
+func TestSetup_Stdout(t *testing.T) {
+	var out bytes.Buffer
+
+	shutdown, err := Setup(context.Background(), config.Tracing{
+		Exporter:    config.TracingStdout,
+		SampleRatio: 1,
+		ServiceName: "syncplay-test",
+	}, &out)
+	require.NoError(t, err)
+
+	_, span := otel.Tracer("test").Start(context.Background(), "hello")
+	span.End()
+
+	// Shutdown отправляет накопленные спаны
+	require.NoError(t, shutdown(context.Background()))
+	assert.Contains(t, out.String(), `"Name":"hello"`)
+	assert.Contains(t, out.String(), "syncplay-test")
+}
+
+func TestSetup_None(t *testing.T) {
+	var out bytes.Buffer
+
+	shutdown, err := Setup(context.Background(), config.Tracing{Exporter: config.TracingNone}, &out)
+	require.NoError(t, err)
+	require.NoError(t, shutdown(context.Background()))
+	assert.Empty(t, out.String())
+
+	_, err = Setup(context.Background(), config.Tracing{Exporter: "zipkin"}, &out)
+	require.ErrorContains(t, err, `unknown tracing exporter "zipkin"`)
+}
@@ -0,0 +1,5 @@
+// Package version хранит версию сборки. При сборке её можно задать флагом
+// -ldflags "-X github.com/vpbuyanov/syncplay/internal/version.Version=1.2.3".
+package version
+
+var Version = "0.0.1"
@@ -0,0 +1,9 @@
+drop index if exists rooms_tags_idx;
+drop index if exists rooms_title_id_idx;
+drop index if exists rooms_created_at_id_idx;
+
+alter table "rooms"
+    drop column if exists tags,
+    drop column if exists visibility,
+    drop column if exists description,
+    drop column if exists title;
@@ -0,0 +1,10 @@
+alter table "rooms"
+    add column if not exists title       text   default ''       not null,
+    add column if not exists description text   default ''       not null,
+    add column if not exists visibility  text   default 'public' not null
+        constraint rooms_visibility_check check (visibility in ('public', 'private')),
+    add column if not exists tags        text[] default '{}'     not null;
+
+create index if not exists rooms_created_at_id_idx on "rooms" (created_at, id);
+create index if not exists rooms_title_id_idx on "rooms" (title, id);
+create index if not exists rooms_tags_idx on "rooms" using gin (tags);
@@ -0,0 +1,11 @@
+drop index if exists chat_messages_room_id_created_at_idx;
+drop index if exists chat_messages_text_tsv_idx;
+
+alter table "chat_messages"
+    drop column if exists text_tsv;
+
+drop index if exists rooms_search_tsv_idx;
+
+alter table "rooms"
+    drop column if exists search_tsv,
+    drop column if exists owner_token_hash;
@@ -0,0 +1,16 @@
+alter table "rooms"
+    add column if not exists owner_token_hash bytea,
+    add column if not exists search_tsv       tsvector
+        generated always as (
+            setweight(to_tsvector('simple', title), 'A') ||
+            setweight(to_tsvector('simple', description), 'B')
+        ) stored;
+
+create index if not exists rooms_search_tsv_idx on "rooms" using gin (search_tsv);
+
+alter table "chat_messages"
+    add column if not exists text_tsv tsvector
+        generated always as (to_tsvector('simple', coalesce(text, ''))) stored;
+
+create index if not exists chat_messages_text_tsv_idx on "chat_messages" using gin (text_tsv);
+create index if not exists chat_messages_room_id_created_at_idx on "chat_messages" (room_id, created_at);
@@ -0,0 +1,11 @@
+drop index if exists rooms_last_active_at_idx;
+drop index if exists rooms_expires_at_idx;
+
+alter table "chat_messages"
+    alter column created_at type timestamp without time zone using created_at at time zone 'UTC';
+
+alter table "rooms"
+    drop column if exists archived_at,
+    drop column if exists last_active_at,
+    drop column if exists expires_at,
+    alter column created_at type timestamp without time zone using created_at at time zone 'UTC';
@@ -0,0 +1,15 @@
+-- Время в Go и now() сравниваются корректно только с timestamptz: у timestamp
+-- без зоны now() зависит от TimeZone сессии. Старые значения считаем UTC
+alter table "rooms"
+    alter column created_at type timestamptz using created_at at time zone 'UTC',
+    add column if not exists expires_at     timestamptz,
+    add column if not exists last_active_at timestamptz default now() not null,
+    add column if not exists archived_at    timestamptz;
+
+alter table "chat_messages"
+    alter column created_at type timestamptz using created_at at time zone 'UTC';
+
+create index if not exists rooms_expires_at_idx on "rooms" (expires_at)
+    where expires_at is not null and archived_at is null;
+create index if not exists rooms_last_active_at_idx on "rooms" (last_active_at)
+    where archived_at is null;
@@ -0,0 +1,4 @@
+drop index if exists rooms_deleted_at_idx;
+
+alter table "rooms"
+    drop column if exists deleted_at;
@@ -0,0 +1,5 @@
+alter table "rooms"
+    add column if not exists deleted_at timestamptz;
+
+create index if not exists rooms_deleted_at_idx on "rooms" (deleted_at)
+    where deleted_at is not null;
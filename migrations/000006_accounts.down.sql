@@ -0,0 +1,7 @@
+drop index if exists rooms_created_by_idx;
+
+alter table "rooms"
+    drop column if exists created_by;
+
+drop table if exists "sessions";
+drop table if exists "users";
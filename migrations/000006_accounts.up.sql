@@ -0,0 +1,27 @@
+create table if not exists "users"
+(
+    id            uuid                      not null primary key,
+    username      text                      not null unique,
+    password_hash text                      not null,
+    created_at    timestamptz default now() not null
+);
+
+-- Хранится только sha256 токена сессии, сам токен знает лишь клиент
+create table if not exists "sessions"
+(
+    token_hash bytea                     not null primary key,
+    user_id    uuid                      not null references users (id) on delete cascade,
+    created_at timestamptz default now() not null,
+    expires_at timestamptz               not null
+);
+
+create index if not exists sessions_user_id_idx on "sessions" (user_id);
+create index if not exists sessions_expires_at_idx on "sessions" (expires_at);
+
+-- created_by — local:<id учётной записи> или jwt:<iss>|<sub> внешнего провайдера,
+-- поэтому без внешнего ключа на users
+alter table "rooms"
+    add column if not exists created_by text;
+
+create index if not exists rooms_created_by_idx on "rooms" (created_by, created_at)
+    where created_by is not null;
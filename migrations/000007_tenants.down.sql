@@ -0,0 +1,6 @@
+drop index if exists rooms_tenant_id_idx;
+
+alter table "rooms"
+    drop column if exists tenant_id;
+
+drop table if exists "tenants";
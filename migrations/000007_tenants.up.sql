@@ -0,0 +1,18 @@
+-- Хранится только sha256 API-ключа, сам ключ показывается один раз при создании.
+-- Квота 0 означает отсутствие ограничения
+create table if not exists "tenants"
+(
+    id               uuid                      not null primary key,
+    name             text                      not null unique,
+    api_key_hash     bytea                     not null unique,
+    max_rooms        integer                   not null default 0,
+    max_peers        integer                   not null default 0,
+    messages_per_min integer                   not null default 0,
+    created_at       timestamptz default now() not null
+);
+
+-- Комнаты без арендатора принадлежат арендатору по умолчанию
+alter table "rooms"
+    add column if not exists tenant_id uuid references tenants (id);
+
+create index if not exists rooms_tenant_id_idx on "rooms" (tenant_id);
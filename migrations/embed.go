@@ -0,0 +1,15 @@
+// Package migrations встраивает SQL-миграции в бинарник, чтобы мигратор
+// не зависел от рабочего каталога.
+package migrations
+
+import "embed"
+
+// Postgres — миграции Postgres из корня каталога.
+//
+//go:embed *.sql
+var Postgres embed.FS
+
+// SQLite — миграции встроенной базы, лежат в подкаталоге sqlite.
+//
+//go:embed sqlite/*.sql
+var SQLite embed.FS
@@ -0,0 +1,9 @@
+drop trigger if exists chat_messages_fts_delete;
+drop trigger if exists chat_messages_fts_insert;
+drop table if exists chat_messages_fts;
+drop trigger if exists rooms_fts_update;
+drop trigger if exists rooms_fts_delete;
+drop trigger if exists rooms_fts_insert;
+drop table if exists rooms_fts;
+drop table if exists chat_messages;
+drop table if exists rooms;
@@ -0,0 +1,85 @@
+-- Время хранится строкой "2006-01-02 15:04:05.000000" в UTC:
+-- формат фиксированной ширины, поэтому строки сравниваются как даты.
+create table if not exists rooms
+(
+    -- Явный rowid: неявный может поменяться при VACUUM, а на него ссылается rooms_fts
+    seq              integer primary key,
+    id               text unique           not null,
+    title            text default ''       not null,
+    description      text default ''       not null,
+    visibility       text default 'public' not null
+        constraint rooms_visibility_check check (visibility in ('public', 'private')),
+    tags             text default '[]'     not null,
+    owner_token_hash blob,
+    created_at       text                  not null,
+    expires_at       text,
+    last_active_at   text                  not null,
+    archived_at      text,
+    deleted_at       text
+);
+
+create index if not exists rooms_created_at_id_idx on rooms (created_at, id);
+create index if not exists rooms_title_id_idx on rooms (title, id);
+create index if not exists rooms_expires_at_idx on rooms (expires_at)
+    where expires_at is not null and archived_at is null;
+create index if not exists rooms_last_active_at_idx on rooms (last_active_at)
+    where archived_at is null;
+create index if not exists rooms_deleted_at_idx on rooms (deleted_at)
+    where deleted_at is not null;
+
+create table if not exists chat_messages
+(
+    id         integer primary key autoincrement,
+    room_id    text references rooms (id),
+    sender     text,
+    text       text,
+    created_at text not null
+);
+
+create index if not exists chat_messages_room_id_created_at_idx on chat_messages (room_id, created_at);
+
+create virtual table if not exists rooms_fts using fts5
+(
+    title,
+    description,
+    content = 'rooms',
+    content_rowid = 'seq',
+    tokenize = 'unicode61'
+);
+
+create trigger if not exists rooms_fts_insert after insert on rooms
+begin
+    insert into rooms_fts (rowid, title, description) values (new.seq, new.title, new.description);
+end;
+
+create trigger if not exists rooms_fts_delete after delete on rooms
+begin
+    insert into rooms_fts (rooms_fts, rowid, title, description)
+    values ('delete', old.seq, old.title, old.description);
+end;
+
+create trigger if not exists rooms_fts_update after update of title, description on rooms
+begin
+    insert into rooms_fts (rooms_fts, rowid, title, description)
+    values ('delete', old.seq, old.title, old.description);
+    insert into rooms_fts (rowid, title, description) values (new.seq, new.title, new.description);
+end;
+
+create virtual table if not exists chat_messages_fts using fts5
+(
+    text,
+    content = 'chat_messages',
+    content_rowid = 'id',
+    tokenize = 'unicode61'
+);
+
+create trigger if not exists chat_messages_fts_insert after insert on chat_messages
+begin
+    insert into chat_messages_fts (rowid, text) values (new.id, coalesce(new.text, ''));
+end;
+
+create trigger if not exists chat_messages_fts_delete after delete on chat_messages
+begin
+    insert into chat_messages_fts (chat_messages_fts, rowid, text)
+    values ('delete', old.id, coalesce(old.text, ''));
+end;
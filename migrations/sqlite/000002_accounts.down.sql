@@ -0,0 +1,6 @@
+drop index if exists rooms_created_by_idx;
+
+alter table rooms drop column created_by;
+
+drop table if exists sessions;
+drop table if exists users;
@@ -0,0 +1,26 @@
+create table if not exists users
+(
+    id            text primary key,
+    username      text unique not null,
+    password_hash text        not null,
+    created_at    text        not null
+);
+
+-- Хранится только sha256 токена сессии, сам токен знает лишь клиент
+create table if not exists sessions
+(
+    token_hash blob primary key,
+    user_id    text not null references users (id) on delete cascade,
+    created_at text not null,
+    expires_at text not null
+);
+
+create index if not exists sessions_user_id_idx on sessions (user_id);
+create index if not exists sessions_expires_at_idx on sessions (expires_at);
+
+-- created_by — id учётной записи или sub из JWT внешнего провайдера,
+-- поэтому без внешнего ключа на users
+alter table rooms add column created_by text;
+
+create index if not exists rooms_created_by_idx on rooms (created_by, created_at)
+    where created_by is not null;
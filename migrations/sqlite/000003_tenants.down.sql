@@ -0,0 +1,5 @@
+drop index if exists rooms_tenant_id_idx;
+
+alter table rooms drop column tenant_id;
+
+drop table if exists tenants;
@@ -0,0 +1,18 @@
+-- Хранится только sha256 API-ключа, сам ключ показывается один раз при создании.
+-- Квота 0 означает отсутствие ограничения
+create table if not exists tenants
+(
+    id               text primary key,
+    name             text unique not null,
+    api_key_hash     blob unique not null,
+    max_rooms        integer     not null default 0,
+    max_peers        integer     not null default 0,
+    messages_per_min integer     not null default 0,
+    created_at       text        not null
+);
+
+-- Комнаты без арендатора принадлежат арендатору по умолчанию. Без внешнего
+-- ключа: SQLite не даёт удалить колонку со ссылкой в down-миграции
+alter table rooms add column tenant_id text;
+
+create index if not exists rooms_tenant_id_idx on rooms (tenant_id);